@@ -3,6 +3,7 @@ package zap
 import (
 	"fmt"
 	"io"
+	"strings"
 	"sync"
 	"time"
 
@@ -39,6 +40,14 @@ type ansiEncoder struct {
 	errorColor string
 	panicColor string
 	fatalColor string
+
+	// If non-empty, caller prefixes are rendered as OSC 8 hyperlinks.
+	callerLinkBase string
+	// If positive, entries are wrapped at this many columns.
+	wrapWidth int
+	// If non-empty, the escape codes used to color field keys and values.
+	keyColor   string
+	valueColor string
 }
 
 // A ANSIOption is used to set options for a ANSI encoder.
@@ -55,17 +64,21 @@ func (opt ansiOptionFunc) apply(enc *ansiEncoder) {
 // NewANSIEncoder creates a line-oriented text encoder whose output is optimized
 // for human, rather than machine, consumption. Log levels are color-coded using
 // ANSI escape codes. By default, the encoder uses RFC3339-formatted timestamps.
+// The returned Encoder is also a TextEncoder.
 func NewANSIEncoder(options ...ANSIOption) Encoder {
 
 	enc := ansiPool.Get().(*ansiEncoder)
-	enc.truncate()
-	enc.timeFmt = time.RFC3339
+	enc.reset()
 	enc.debugColor = defaultDebugColor
 	enc.infoColor = defaultInfoColor
 	enc.warnColor = defaultWarnColor
 	enc.errorColor = defaultErrorColor
 	enc.panicColor = defaultPanicColor
 	enc.fatalColor = defaultFatalColor
+	enc.callerLinkBase = ""
+	enc.wrapWidth = 0
+	enc.keyColor = ""
+	enc.valueColor = ""
 	for _, opt := range options {
 		opt.apply(enc)
 	}
@@ -74,15 +87,17 @@ func NewANSIEncoder(options ...ANSIOption) Encoder {
 
 func (enc *ansiEncoder) Clone() Encoder {
 	clone := ansiPool.Get().(*ansiEncoder)
-	clone.truncate()
-	clone.bytes = append(clone.bytes, enc.bytes...)
-	clone.timeFmt = enc.timeFmt
+	enc.textEncoder.cloneTo(&clone.textEncoder)
 	clone.debugColor = enc.debugColor
 	clone.infoColor = enc.infoColor
 	clone.warnColor = enc.warnColor
 	clone.errorColor = enc.errorColor
 	clone.panicColor = enc.panicColor
 	clone.fatalColor = enc.fatalColor
+	clone.callerLinkBase = enc.callerLinkBase
+	clone.wrapWidth = enc.wrapWidth
+	clone.keyColor = enc.keyColor
+	clone.valueColor = enc.valueColor
 	return clone
 }
 
@@ -94,50 +109,142 @@ func (enc *ansiEncoder) WriteEntry(sink io.Writer, name string, msg string, lvl
 	if sink == nil {
 		return errNilSink
 	}
+	if enc.skipEntry(name, msg, lvl, t) {
+		return nil
+	}
+	suppressed, ok := enc.dedup(lvl, msg)
+	if !ok {
+		return nil
+	}
+	dropped, ok := enc.admit()
+	if !ok {
+		return nil
+	}
 
-	final := textPool.Get().(*textEncoder)
-	final.truncate()
-
+	start := enc.startTiming()
+	final := enc.textEncoder.newFinal()
+	enc.textEncoder.scoreEntry(final, lvl, msg)
+	enc.textEncoder.addPreamble(final, sink, t)
+	if dropped > 0 {
+		enc.addLevelColor(final, WarnLevel)
+		enc.textEncoder.addDropSummary(final, dropped, t)
+		enc.clearLevelColor(final, WarnLevel)
+		final.bytes = append(final.bytes, '\n')
+	}
+	if suppressed > 0 {
+		enc.addLevelColor(final, WarnLevel)
+		enc.textEncoder.addSuppressedSummary(final, suppressed, t)
+		enc.clearLevelColor(final, WarnLevel)
+		final.bytes = append(final.bytes, '\n')
+	}
+	lineStart := len(final.bytes)
 	enc.addLevelColor(final, lvl)
-	enc.textEncoder.addLevel(final, lvl)
-	enc.textEncoder.addTime(final, t)
-	enc.textEncoder.addName(final, name)
-	enc.textEncoder.addMessage(final, msg)
+	if !enc.fieldsOnly {
+		enc.textEncoder.addLevel(final, lvl)
+		enc.textEncoder.addTime(final, t)
+		enc.textEncoder.addName(final, name)
+		enc.textEncoder.addLabels(final)
+		enc.addMessage(final, msg)
+	}
 
-	if len(enc.textEncoder.bytes) > 0 {
-		final.bytes = append(final.bytes, ' ')
-		final.bytes = append(final.bytes, enc.textEncoder.bytes...)
+	fieldsStart := len(final.bytes)
+	enc.textEncoder.addFields(final, enc.fieldColors(lvl))
+	if enc.textEncoder.omitEmptyFields(final, lineStart, fieldsStart) {
+		enc.stopTiming(start)
+		return enc.writeFinal(sink, final, lvl)
 	}
+	sizeAt := len(final.bytes)
+	enc.textEncoder.addExemplar(final, t)
 	enc.clearLevelColor(final, lvl)
 	final.bytes = append(final.bytes, '\n')
+	enc.textEncoder.addSize(final, lineStart, sizeAt)
+	enc.wrap(final, lineStart)
+	enc.stopTiming(start)
+	return enc.writeFinal(sink, final, lvl)
+}
+
+// WriteEntryf is like WriteEntry, but it formats the message with
+// fmt.Sprintf only if the entry isn't filtered out by the encoder's level
+// options.
+func (enc *ansiEncoder) WriteEntryf(sink io.Writer, name string, lvl Level, t time.Time, format string, args ...interface{}) error {
+	if sink == nil {
+		return errNilSink
+	}
+	if enc.filtered(lvl, t) {
+		return nil
+	}
+	return enc.WriteEntry(sink, name, fmt.Sprintf(format, args...), lvl, t)
+}
+
+func (enc *ansiEncoder) addMessage(final *textEncoder, msg string) {
+	if enc.callerLinkBase == "" {
+		enc.textEncoder.addMessage(final, msg)
+		return
+	}
+	msg, rest := enc.splitMessage(msg)
+	file, line, ok := splitCaller(msg)
+	if !ok {
+		final.bytes = append(final.bytes, ' ')
+		final.bytes = enc.appendRaw(final.bytes, msg)
+		enc.addContinuations(final, rest)
+		return
+	}
+	caller := msg[:len(file)+1+len(line)]
+	final.bytes = append(final.bytes, ' ')
+	// OSC 8 ; params ; URI ST, followed by the link text and an empty link.
+	final.bytes = append(final.bytes, "\x1b]8;;"...)
+	final.bytes = append(final.bytes, enc.callerLinkBase...)
+	final.bytes = append(final.bytes, file...)
+	final.bytes = append(final.bytes, "#L"...)
+	final.bytes = append(final.bytes, line...)
+	final.bytes = append(final.bytes, "\x1b\\"...)
+	final.bytes = append(final.bytes, caller...)
+	final.bytes = append(final.bytes, "\x1b]8;;\x1b\\"...)
+	final.bytes = enc.appendRaw(final.bytes, msg[len(caller):])
+	enc.addContinuations(final, rest)
+}
 
-	expectedBytes := len(final.bytes)
-	n, err := sink.Write(final.bytes)
-	final.Free()
-	if err != nil {
-		return err
+// splitCaller extracts the file and line from a message prefixed with caller
+// information by the AddCaller option (e.g., "foo.go:42: message").
+func splitCaller(msg string) (file, line string, ok bool) {
+	end := strings.Index(msg, ": ")
+	if end < 0 {
+		return "", "", false
+	}
+	colon := strings.LastIndexByte(msg[:end], ':')
+	if colon < 1 || colon == end-1 {
+		return "", "", false
 	}
-	if n != expectedBytes {
-		return fmt.Errorf("incomplete write: only wrote %v of %v bytes", n, expectedBytes)
+	for _, c := range msg[colon+1 : end] {
+		if c < '0' || c > '9' {
+			return "", "", false
+		}
 	}
-	return nil
+	return msg[:colon], msg[colon+1 : end], true
 }
 
 func (enc *ansiEncoder) addLevelColor(final *textEncoder, lvl Level) {
+	final.bytes = append(final.bytes, enc.levelColor(lvl)...)
+}
+
+// levelColor returns the escape code for the level's color, or an empty
+// string if the level isn't colored.
+func (enc *ansiEncoder) levelColor(lvl Level) string {
 	switch lvl {
 	case DebugLevel:
-		final.bytes = append(final.bytes, enc.debugColor...)
+		return enc.debugColor
 	case InfoLevel:
-		final.bytes = append(final.bytes, enc.infoColor...)
+		return enc.infoColor
 	case WarnLevel:
-		final.bytes = append(final.bytes, enc.warnColor...)
+		return enc.warnColor
 	case ErrorLevel:
-		final.bytes = append(final.bytes, enc.errorColor...)
+		return enc.errorColor
 	case PanicLevel:
-		final.bytes = append(final.bytes, enc.panicColor...)
+		return enc.panicColor
 	case FatalLevel:
-		final.bytes = append(final.bytes, enc.fatalColor...)
+		return enc.fatalColor
 	default:
+		return ""
 	}
 }
 
@@ -155,3 +262,31 @@ func AnsiTextOption(to TextOption) ANSIOption {
 		to.apply(&enc.textEncoder)
 	})
 }
+
+// ANSIHyperlinkCaller renders the caller information added by the AddCaller
+// option as an OSC 8 hyperlink to baseURL followed by the file name and line
+// anchor (e.g., "https://example.com/src/foo.go#L42"). Terminals that don't
+// support hyperlinks display the caller as plain text.
+func ANSIHyperlinkCaller(baseURL string) ANSIOption {
+	return ansiOptionFunc(func(enc *ansiEncoder) {
+		enc.callerLinkBase = baseURL
+	})
+}
+
+// ANSIFieldsOnly writes only the fields of each entry, like the TextFieldsOnly
+// option. Fields are still colored.
+func ANSIFieldsOnly(omitEmpty bool) ANSIOption {
+	return AnsiTextOption(TextFieldsOnly(omitEmpty))
+}
+
+// ANSIWrapWidth wraps entries longer than cols visible columns, which keeps
+// them readable on narrow terminals. Lines are broken between fields and words
+// where possible, continuation lines are indented, and colors are restored on
+// each continuation line. Escape sequences are never split. A non-positive
+// width disables wrapping, which is the default. With the TextEmitSize option,
+// sizes are measured before entries are wrapped.
+func ANSIWrapWidth(cols int) ANSIOption {
+	return ansiOptionFunc(func(enc *ansiEncoder) {
+		enc.wrapWidth = cols
+	})
+}
@@ -0,0 +1,90 @@
+// Copyright (c) 2016 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zap
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestANSIHyperlinkCaller(t *testing.T) {
+	tests := []struct {
+		msg      string
+		expected string
+	}{
+		{
+			"foo.go:42: hello",
+			"\x1b]8;;https://example.com/src/foo.go#L42\x1b\\foo.go:42\x1b]8;;\x1b\\: hello",
+		},
+		{"no caller here", "no caller here"},
+		{"foo.go:bar: not a line", "foo.go:bar: not a line"},
+		{":42: no file", ":42: no file"},
+	}
+
+	enc := NewANSIEncoder(AnsiTextOption(TextNoTime()), ANSIHyperlinkCaller("https://example.com/src/"))
+	defer enc.Free()
+	sink := &testBuffer{}
+	for _, tt := range tests {
+		assert.NoError(t, enc.WriteEntry(sink, "", tt.msg, InfoLevel, epoch), "Unexpected failure writing entry.")
+		expected := defaultInfoColor + "[I] " + tt.expected + resetColor
+		assert.Equal(t, expected, sink.Stripped(), "Unexpected output for message %q.", tt.msg)
+		sink.Reset()
+	}
+}
+
+func TestANSIHyperlinkCallerDisabled(t *testing.T) {
+	enc := NewANSIEncoder(AnsiTextOption(TextNoTime()))
+	defer enc.Free()
+	sink := &testBuffer{}
+	assert.NoError(t, enc.WriteEntry(sink, "", "foo.go:42: hello", InfoLevel, epoch), "Unexpected failure writing entry.")
+	assert.Equal(t, defaultInfoColor+"[I] foo.go:42: hello"+resetColor, sink.Stripped(), "Expected no hyperlinks by default.")
+}
+
+func TestANSIWriteEntryf(t *testing.T) {
+	enc := NewANSIEncoder(AnsiTextOption(TextNoTime()), AnsiTextOption(TextMinLevel(WarnLevel))).(*ansiEncoder)
+	defer enc.Free()
+	arg := &countingStringer{}
+	sink := &testBuffer{}
+
+	assert.NoError(t, enc.WriteEntryf(sink, "", InfoLevel, epoch, "%v", arg), "Unexpected failure writing a filtered entry.")
+	assert.Equal(t, 0, arg.calls, "Expected filtered entries not to be formatted.")
+
+	assert.NoError(t, enc.WriteEntryf(sink, "", WarnLevel, epoch, "%v!", arg), "Unexpected failure writing entry.")
+	assert.Equal(t, defaultWarnColor+"[W] formatted!"+resetColor, sink.Stripped(), "Unexpected formatted output.")
+}
+
+func TestANSISplitMessageWithHyperlink(t *testing.T) {
+	enc := NewANSIEncoder(
+		AnsiTextOption(TextNoTime()),
+		AnsiTextOption(TextSplitMessage(12)),
+		ANSIHyperlinkCaller("https://example.com/"),
+	)
+	defer enc.Free()
+	sink := &testBuffer{}
+	assert.NoError(t, enc.WriteEntry(sink, "", "foo.go:42: hello world", InfoLevel, epoch), "Unexpected failure writing entry.")
+	assert.Equal(
+		t,
+		defaultInfoColor+"[I] \x1b]8;;https://example.com/foo.go#L42\x1b\\foo.go:42\x1b]8;;\x1b\\: h msg_1=\"ello world\""+resetColor,
+		sink.Stripped(),
+		"Expected the caller link in the first chunk of a split message.",
+	)
+}
@@ -0,0 +1,95 @@
+// Copyright (c) 2016 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zap
+
+import "github.com/mgutz/ansi"
+
+// fieldColors are the escape codes used to color field keys and values. After
+// each colored key or value, colors are reset and the line's color is
+// restored.
+type fieldColors struct {
+	key, value, restore string
+}
+
+// fieldColors returns the colors for the fields of an entry at the given
+// level, or nil if fields aren't colored.
+func (enc *ansiEncoder) fieldColors(lvl Level) *fieldColors {
+	if enc.keyColor == "" && enc.valueColor == "" {
+		return nil
+	}
+	return &fieldColors{
+		key:     enc.keyColor,
+		value:   enc.valueColor,
+		restore: enc.levelColor(lvl),
+	}
+}
+
+// colorFields colors the keys and values of the top-level fields in final.
+func (enc *textEncoder) colorFields(final *textEncoder, spans []fieldSpan, colors *fieldColors) {
+	// Work backwards, so that inserting escape codes doesn't move the fields
+	// that are still to be colored.
+	for i := len(spans) - 1; i >= 0; i-- {
+		s := spans[i]
+		if colors.value != "" && s.key != "_size" {
+			// The size's value is filled in after the fields are colored.
+			final.bytes = insertString(final.bytes, s.end, resetColor+colors.restore)
+			final.bytes = insertString(final.bytes, s.start, colors.value)
+		}
+		if colors.key != "" {
+			// Keys are followed by the key-value separator.
+			sep := s.start - len(enc.keyValueSep())
+			final.bytes = insertString(final.bytes, sep, resetColor+colors.restore)
+			final.bytes = insertString(final.bytes, sep-enc.keyLen(s.key), colors.key)
+		}
+	}
+}
+
+// keyLen returns the length of a key once it's written to the buffer, after
+// any quoting and escaping.
+func (enc *textEncoder) keyLen(key string) int {
+	return len(enc.appendString(nil, key))
+}
+
+// insertString inserts s into buf at offset i.
+func insertString(buf []byte, i int, s string) []byte {
+	buf = append(buf, s...)
+	copy(buf[i+len(s):], buf[i:len(buf)-len(s)])
+	copy(buf[i:], s)
+	return buf
+}
+
+// ANSIKeyColor colors the keys of fields, which makes dense entries easier
+// to scan. The color is any style understood by github.com/mgutz/ansi (e.g.,
+// "black+h" or "cyan"). After each key, colors are reset and the level's
+// color is restored.
+func ANSIKeyColor(color string) ANSIOption {
+	return ansiOptionFunc(func(enc *ansiEncoder) {
+		enc.keyColor = ansi.ColorCode(color)
+	})
+}
+
+// ANSIValueColor colors the values of fields, like ANSIKeyColor does for
+// keys. Nested objects are colored as a single value.
+func ANSIValueColor(color string) ANSIOption {
+	return ansiOptionFunc(func(enc *ansiEncoder) {
+		enc.valueColor = ansi.ColorCode(color)
+	})
+}
@@ -0,0 +1,131 @@
+// Copyright (c) 2016 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zap
+
+import (
+	"testing"
+
+	"github.com/mgutz/ansi"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func addColorTestFields(enc Encoder) {
+	enc.AddString("user", "jane doe")
+	enc.AddInt("attempts", 3)
+	enc.AddMarshaler("obj", loggable{true})
+	enc.AddString("key with spaces", "v")
+}
+
+func TestANSIFieldColors(t *testing.T) {
+	key, val := ansi.ColorCode("black+h"), ansi.ColorCode("cyan")
+	enc := NewANSIEncoder(AnsiTextOption(TextNoTime()), ANSIKeyColor("black+h"), ANSIValueColor("cyan"))
+	defer enc.Free()
+	enc.AddString("user", "jane")
+	enc.AddInt("n", 1)
+
+	sink := &testBuffer{}
+	require.NoError(t, enc.WriteEntry(sink, "", "hello", WarnLevel, epoch), "Unexpected failure writing entry.")
+	restore := resetColor + defaultWarnColor
+	assert.Equal(t,
+		defaultWarnColor+"[W] hello "+
+			key+"user"+restore+"="+val+"jane"+restore+" "+
+			key+"n"+restore+"="+val+"1"+restore+
+			resetColor+"\n",
+		sink.String(),
+		"Unexpected colored fields.",
+	)
+}
+
+func TestANSIFieldColorsEscapedKeys(t *testing.T) {
+	key := ansi.ColorCode("black+h")
+	enc := NewANSIEncoder(
+		AnsiTextOption(TextNoTime()),
+		AnsiTextOption(TextQuoteStrings(false)),
+		AnsiTextOption(TextEscapeNewlines()),
+		ANSIKeyColor("black+h"),
+	)
+	defer enc.Free()
+	enc.AddString("a\tb", "1")
+
+	sink := &testBuffer{}
+	require.NoError(t, enc.WriteEntry(sink, "", "hello", WarnLevel, epoch), "Unexpected failure writing entry.")
+	restore := resetColor + defaultWarnColor
+	assert.Equal(t,
+		defaultWarnColor+"[W] hello "+key+`a\tb`+restore+"=1"+resetColor+"\n",
+		sink.String(),
+		"Expected the color to span the escaped key.",
+	)
+}
+
+func TestANSIFieldColorsContent(t *testing.T) {
+	tests := []struct {
+		desc string
+		opts []ANSIOption
+	}{
+		{"key color", []ANSIOption{ANSIKeyColor("blue")}},
+		{"value color", []ANSIOption{ANSIValueColor("green+b")}},
+		{"both colors", []ANSIOption{ANSIKeyColor("blue"), ANSIValueColor("green+b")}},
+	}
+
+	text := NewTextEncoder(TextNoTime(), TextComponent("api"))
+	defer text.Free()
+	addColorTestFields(text)
+	plain := &testBuffer{}
+	require.NoError(t, text.WriteEntry(plain, "", "hello", InfoLevel, epoch), "Unexpected failure writing entry.")
+
+	for _, tt := range tests {
+		opts := append([]ANSIOption{AnsiTextOption(TextNoTime()), AnsiTextOption(TextComponent("api"))}, tt.opts...)
+		enc := NewANSIEncoder(opts...)
+		addColorTestFields(enc)
+		sink := &testBuffer{}
+		require.NoError(t, enc.WriteEntry(sink, "", "hello", InfoLevel, epoch), "Unexpected failure writing entry.")
+		enc.Free()
+
+		assert.Equal(t, plain.String(), stripEscapes(sink.String()), "Expected colors not to change the content with %s.", tt.desc)
+	}
+}
+
+func TestANSIFieldColorsDisabled(t *testing.T) {
+	opts := []TextOption{TextNoTime(), TextDeltaFields(), TextHeaderFieldSeparator(" | "), TextComponent("api")}
+	text := NewTextEncoder(opts...)
+	defer text.Free()
+	ansiOpts := make([]ANSIOption, len(opts))
+	for i, opt := range opts {
+		ansiOpts[i] = AnsiTextOption(opt)
+	}
+	enc := NewANSIEncoder(ansiOpts...)
+	defer enc.Free()
+
+	plain, colored := &testBuffer{}, &testBuffer{}
+	for i := 0; i < 2; i++ {
+		addColorTestFields(text)
+		addColorTestFields(enc)
+		require.NoError(t, text.WriteEntry(plain, "", "hello", InfoLevel, epoch), "Unexpected failure writing entry.")
+		require.NoError(t, enc.WriteEntry(colored, "", "hello", InfoLevel, epoch), "Unexpected failure writing entry.")
+	}
+
+	var expected []string
+	for _, line := range plain.Lines() {
+		expected = append(expected, defaultInfoColor+line+resetColor)
+	}
+	assert.Equal(t, expected, colored.Lines(), "Expected uncolored fields to match the text encoder's byte for byte.")
+}
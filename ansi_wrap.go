@@ -0,0 +1,171 @@
+// Copyright (c) 2016 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zap
+
+import "unicode/utf8"
+
+// _ansiWrapIndent indents the continuation lines of wrapped entries.
+const _ansiWrapIndent = "    "
+
+// wrap wraps the entry that starts at lineStart in the final buffer, which
+// must end with a newline, if the ANSIWrapWidth option is set.
+func (enc *ansiEncoder) wrap(final *textEncoder, lineStart int) {
+	if enc.wrapWidth <= 0 {
+		return
+	}
+	end := len(final.bytes) - 1
+	scratch := textPool.Get().(*textEncoder)
+	scratch.bytes = wrapANSI(scratch.bytes[:0], final.bytes[lineStart:end], enc.wrapWidth)
+	final.bytes = append(final.bytes[:lineStart], scratch.bytes...)
+	final.bytes = append(final.bytes, '\n')
+	scratch.Free()
+}
+
+// wrapANSI appends line to buf, breaking it into lines of at most width
+// visible columns. Lines are broken at the last space that fits, or mid-word
+// if there's none, and continuation lines are indented. Escape sequences take
+// up no columns and are never split; if a color is active at a break, it's
+// reset before the newline and restored after the indentation, so each line
+// renders correctly on its own.
+func wrapANSI(buf, line []byte, width int) []byte {
+	indent := _ansiWrapIndent
+	if len(indent) > width/2 {
+		indent = indent[:width/2]
+	}
+
+	var color []byte // The active SGR sequence, if any.
+	col, minCol := 0, 0
+	// Offset in buf of the last space on the current line, the column it's
+	// in, and the color active when it was written.
+	space, spaceCol := -1, 0
+	var spaceColor []byte
+
+	for i := 0; i < len(line); {
+		if n := escapeLen(line[i:]); n > 0 {
+			seq := line[i : i+n]
+			buf = append(buf, seq...)
+			if isSGR(seq) {
+				color = seq
+				if isReset(seq) {
+					color = nil
+				}
+			}
+			i += n
+			continue
+		}
+
+		r, size := utf8.DecodeRune(line[i:])
+		if col >= width && r == ' ' {
+			// Break at this space rather than carrying it onto the next line.
+			buf = insertBreak(buf, len(buf), false, color, indent)
+			col, minCol, space = len(indent), len(indent), -1
+			i += size
+			continue
+		}
+		if col >= width {
+			minCol = len(indent)
+			if space >= 0 {
+				buf = insertBreak(buf, space, true, spaceColor, indent)
+				col = len(indent) + col - spaceCol - 1
+			} else {
+				buf = insertBreak(buf, len(buf), false, color, indent)
+				col = len(indent)
+			}
+			space = -1
+		}
+		if r == ' ' && col > minCol {
+			space, spaceCol, spaceColor = len(buf), col, color
+		}
+		buf = append(buf, line[i:i+size]...)
+		col++
+		i += size
+	}
+	return buf
+}
+
+// insertBreak inserts a line break at offset i in buf, replacing the byte
+// there if replace is true. If a color is active, it's reset before the
+// newline and restored after the indentation.
+func insertBreak(buf []byte, i int, replace bool, color []byte, indent string) []byte {
+	n := 1 + len(indent)
+	if len(color) > 0 {
+		n += len(resetColor) + len(color)
+	}
+	tail := len(buf) - i
+	if replace {
+		tail--
+		n--
+	}
+	buf = append(buf, make([]byte, n)...)
+	copy(buf[len(buf)-tail:], buf[len(buf)-n-tail:len(buf)-n])
+
+	j := i
+	if len(color) > 0 {
+		j += copy(buf[j:], resetColor)
+	}
+	buf[j] = '\n'
+	j++
+	j += copy(buf[j:], indent)
+	copy(buf[j:], color)
+	return buf
+}
+
+// escapeLen returns the length of the escape sequence at the start of b, or
+// zero if b doesn't start with one. It recognizes CSI sequences (e.g., colors)
+// and OSC sequences (e.g., hyperlinks), which are terminated by BEL or ST.
+func escapeLen(b []byte) int {
+	if len(b) < 2 || b[0] != '\x1b' {
+		return 0
+	}
+	switch b[1] {
+	case '[':
+		for i := 2; i < len(b); i++ {
+			if b[i] >= 0x40 && b[i] <= 0x7e {
+				return i + 1
+			}
+		}
+	case ']':
+		for i := 2; i < len(b); i++ {
+			if b[i] == '\a' {
+				return i + 1
+			}
+			if b[i] == '\x1b' && i+1 < len(b) && b[i+1] == '\\' {
+				return i + 2
+			}
+		}
+	default:
+		return 2
+	}
+	// An unterminated sequence runs to the end of the line.
+	return len(b)
+}
+
+// isSGR reports whether an escape sequence sets graphic attributes, like
+// colors.
+func isSGR(seq []byte) bool {
+	return seq[1] == '[' && seq[len(seq)-1] == 'm'
+}
+
+// isReset reports whether an SGR sequence resets all graphic attributes.
+func isReset(seq []byte) bool {
+	s := string(seq)
+	return s == "\x1b[m" || s == "\x1b[0m"
+}
@@ -0,0 +1,127 @@
+// Copyright (c) 2016 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zap
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// stripEscapes removes escape sequences from s.
+func stripEscapes(s string) string {
+	b := []byte(s)
+	var out []byte
+	for i := 0; i < len(b); {
+		if n := escapeLen(b[i:]); n > 0 {
+			i += n
+			continue
+		}
+		out = append(out, b[i])
+		i++
+	}
+	return string(out)
+}
+
+func TestWrapANSI(t *testing.T) {
+	const green = "\x1b[0;32m"
+	link := "\x1b]8;;https://example.com/src/foo.go#L42\x1b\\"
+	tests := []struct {
+		desc     string
+		line     string
+		width    int
+		expected string
+	}{
+		{"short line", "aaa bbb", 10, "aaa bbb"},
+		{"break at spaces", "aaa bbb ccc ddd", 12, "aaa bbb ccc\n    ddd"},
+		{"break at a space at the limit", "aaaaa bbb", 5, "aaaaa\n  bbb"},
+		{"break mid-word", "abcdefghij", 4, "abcd\n  ef\n  gh\n  ij"},
+		{
+			"restore colors",
+			green + "hello world" + resetColor,
+			10,
+			green + "hello" + resetColor + "\n    " + green + "world" + resetColor,
+		},
+		{
+			"no color after reset",
+			green + "[I]" + resetColor + " hello world",
+			10,
+			green + "[I]" + resetColor + " hello\n    world",
+		},
+		{
+			"escape sequences take no columns",
+			link + "foo.go:42" + "\x1b]8;;\x1b\\" + " hi",
+			12,
+			link + "foo.go:42" + "\x1b]8;;\x1b\\" + " hi",
+		},
+		{
+			"escape sequences are never split",
+			"abcd" + link + "efgh",
+			6,
+			"abcd" + link + "ef\n   gh",
+		},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.expected, string(wrapANSI(nil, []byte(tt.line), tt.width)), "Unexpected wrapping for %s.", tt.desc)
+	}
+}
+
+func TestANSIWrapWidth(t *testing.T) {
+	enc := NewANSIEncoder(AnsiTextOption(TextNoTime()), ANSIWrapWidth(24))
+	defer enc.Free()
+	enc.AddString("user", "jane")
+	enc.AddInt("attempts", 3)
+	enc.AddString("path", "/api/v1/widgets")
+
+	sink := &testBuffer{}
+	require.NoError(t, enc.WriteEntry(sink, "", "request completed", WarnLevel, epoch), "Unexpected error writing entry.")
+
+	out := sink.String()
+	require.True(t, strings.HasSuffix(out, "\n"), "Expected the entry to end with a newline.")
+	lines := strings.Split(strings.TrimSuffix(out, "\n"), "\n")
+	require.True(t, len(lines) > 1, "Expected the entry to wrap.")
+	for i, line := range lines {
+		prefix := defaultWarnColor
+		if i > 0 {
+			prefix = _ansiWrapIndent + defaultWarnColor
+		}
+		assert.True(t, strings.HasPrefix(line, prefix), "Expected line %d to start with %q, got %q.", i, prefix, line)
+		assert.True(t, strings.HasSuffix(line, resetColor), "Expected line %d to reset colors.", i)
+		assert.True(t, utf8.RuneCountInString(stripEscapes(line)) <= 24, "Expected line %d to fit in 24 columns, got %q.", i, line)
+	}
+	assert.Equal(
+		t,
+		"[I] request completed user=jane attempts=3 path=/api/v1/widgets",
+		strings.Replace(strings.Join(strings.Fields(stripEscapes(out)), " "), "[W]", "[I]", 1),
+		"Expected wrapping to preserve the entry's content.",
+	)
+
+	// Cloned encoders keep the width.
+	clone := enc.Clone()
+	defer clone.Free()
+	sink.Reset()
+	require.NoError(t, clone.WriteEntry(sink, "", "request completed", WarnLevel, epoch), "Unexpected error writing entry.")
+	assert.True(t, len(sink.Lines()) > 1, "Expected clones to wrap entries.")
+}
@@ -0,0 +1,109 @@
+// Copyright (c) 2016 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zap
+
+import (
+	"io"
+	"sync"
+)
+
+// _defaultBufferedSinkSize is the buffer size used for non-positive sizes.
+const _defaultBufferedSinkSize = 4096
+
+type bufferedSink struct {
+	sync.Mutex
+
+	w    io.Writer
+	size int
+	buf  []byte
+}
+
+// NewBufferedSink creates a sink that batches small writes to w, which saves
+// a system call per entry when logging heavily to a file or socket. Entries
+// are buffered until the next one wouldn't fit in size bytes, and then the
+// buffer is written to w in a single call. Entries are never split across
+// writes, and entries at least as large as the buffer are written directly.
+// A non-positive size uses a 4KiB buffer.
+//
+// Buffered entries are lost if they aren't flushed, so call Flush (or Sync)
+// before the program exits. Wrapped with AddSync, the sink is flushed by the
+// logger before it panics or exits on Panic and Fatal entries. Sync also
+// syncs w if it's a WriteSyncer. The sink is safe to use concurrently.
+func NewBufferedSink(w io.Writer, size int) WriteFlusher {
+	if size < 1 {
+		size = _defaultBufferedSinkSize
+	}
+	return &bufferedSink{w: w, size: size, buf: make([]byte, 0, size)}
+}
+
+func (s *bufferedSink) Write(bs []byte) (int, error) {
+	s.Lock()
+	defer s.Unlock()
+	if len(s.buf)+len(bs) > s.size {
+		if err := s.flush(); err != nil {
+			return 0, err
+		}
+	}
+	if len(bs) >= s.size {
+		return s.w.Write(bs)
+	}
+	// Encoders re-use their buffers, so we must copy.
+	s.buf = append(s.buf, bs...)
+	return len(bs), nil
+}
+
+// Flush writes any buffered entries to the underlying writer.
+func (s *bufferedSink) Flush() error {
+	s.Lock()
+	err := s.flush()
+	s.Unlock()
+	return err
+}
+
+// Sync flushes the sink and then syncs the underlying writer, if it's a
+// WriteSyncer.
+func (s *bufferedSink) Sync() error {
+	s.Lock()
+	defer s.Unlock()
+	if err := s.flush(); err != nil {
+		return err
+	}
+	if ws, ok := s.w.(WriteSyncer); ok {
+		return ws.Sync()
+	}
+	return nil
+}
+
+// flush writes the buffer to the underlying writer. If the write fails, the
+// unwritten bytes stay buffered, so the next flush retries them.
+func (s *bufferedSink) flush() error {
+	if len(s.buf) == 0 {
+		return nil
+	}
+	n, err := s.w.Write(s.buf)
+	if n < len(s.buf) && err == nil {
+		err = io.ErrShortWrite
+	}
+	if n > 0 {
+		s.buf = s.buf[:copy(s.buf, s.buf[n:])]
+	}
+	return err
+}
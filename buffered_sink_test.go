@@ -0,0 +1,186 @@
+// Copyright (c) 2016 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zap
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/uber-go/zap/spywrite"
+)
+
+// countingWriter records the number of calls to Write.
+type countingWriter struct {
+	bytes.Buffer
+	writes int
+}
+
+func (w *countingWriter) Write(bs []byte) (int, error) {
+	w.writes++
+	return w.Buffer.Write(bs)
+}
+
+func TestBufferedSinkBatchesWrites(t *testing.T) {
+	out := &countingWriter{}
+	sink := NewBufferedSink(out, 20)
+	enc := NewTextEncoder(TextNoTime())
+	defer enc.Free()
+
+	for i := 0; i < 3; i++ {
+		require.NoError(t, enc.WriteEntry(sink, "", fmt.Sprint(i), InfoLevel, epoch), "Unexpected failure writing entry.")
+	}
+	assert.Equal(t, 0, out.writes, "Expected entries to be buffered.")
+
+	// Each entry is 6 bytes, so the fourth doesn't fit.
+	require.NoError(t, enc.WriteEntry(sink, "", "3", InfoLevel, epoch), "Unexpected failure writing entry.")
+	assert.Equal(t, 1, out.writes, "Expected a full buffer to be written at once.")
+	assert.Equal(t, "[I] 0\n[I] 1\n[I] 2\n", out.String(), "Expected only whole entries to be written.")
+
+	require.NoError(t, sink.Flush(), "Unexpected failure flushing.")
+	assert.Equal(t, 2, out.writes, "Expected Flush to write the buffer.")
+	require.NoError(t, sink.Flush(), "Unexpected failure flushing an empty buffer.")
+	assert.Equal(t, 2, out.writes, "Expected flushing an empty buffer to be a no-op.")
+	assert.Equal(t, "[I] 0\n[I] 1\n[I] 2\n[I] 3\n", out.String(), "Unexpected output after flushing.")
+}
+
+func TestBufferedSinkLargeEntries(t *testing.T) {
+	out := &countingWriter{}
+	sink := NewBufferedSink(out, 10)
+	sink.Write([]byte("small\n"))
+	large := strings.Repeat("x", 20) + "\n"
+	n, err := sink.Write([]byte(large))
+	require.NoError(t, err, "Unexpected failure writing a large entry.")
+	assert.Equal(t, len(large), n, "Unexpected number of bytes written.")
+	assert.Equal(t, 2, out.writes, "Expected the buffer to be flushed before writing a large entry directly.")
+	assert.Equal(t, "small\n"+large, out.String(), "Expected entries to stay in order.")
+}
+
+func TestBufferedSinkDefaultSize(t *testing.T) {
+	sink := NewBufferedSink(ioutil.Discard, 0).(*bufferedSink)
+	assert.Equal(t, _defaultBufferedSinkSize, sink.size, "Unexpected default size.")
+}
+
+func TestBufferedSinkSync(t *testing.T) {
+	out := &bytes.Buffer{}
+	ws := &spywrite.WriteSyncer{Writer: out}
+	sink := NewBufferedSink(ws, 100)
+	sink.Write([]byte("entry\n"))
+
+	require.NoError(t, AddSync(sink).Sync(), "Unexpected failure syncing.")
+	assert.Equal(t, "entry\n", out.String(), "Expected Sync to flush the buffer.")
+	assert.True(t, ws.Called(), "Expected Sync to sync the underlying writer.")
+}
+
+func TestBufferedSinkFlushedOnFatal(t *testing.T) {
+	out := &bytes.Buffer{}
+	sink := NewBufferedSink(out, 1024)
+	logger := New(NewTextEncoder(TextNoTime()), Output(AddSync(sink)))
+	logger.Info("buffered")
+	assert.Empty(t, out.String(), "Expected entries to be buffered.")
+	assert.Panics(t, func() { logger.Panic("crash") }, "Expected Panic to panic.")
+	assert.Equal(t, "[I] buffered\n[P] crash\n", out.String(), "Expected the logger to flush before panicking.")
+}
+
+func TestBufferedSinkErrors(t *testing.T) {
+	sink := NewBufferedSink(spywrite.FailWriter{}, 10)
+	_, err := sink.Write([]byte("12345\n"))
+	require.NoError(t, err, "Unexpected failure buffering an entry.")
+	_, err = sink.Write([]byte("12345\n"))
+	assert.Error(t, err, "Expected a failed flush to fail the write.")
+
+	short := NewBufferedSink(spywrite.ShortWriter{}, 10)
+	short.Write([]byte("12345\n"))
+	assert.Equal(t, io.ErrShortWrite, short.Flush(), "Expected short writes to be reported.")
+	assert.Equal(t, "\n", string(short.(*bufferedSink).buf), "Expected the unwritten bytes to stay buffered.")
+}
+
+func TestBufferedSinkConcurrency(t *testing.T) {
+	out := &countingWriter{}
+	sink := NewBufferedSink(out, 256)
+	const goroutines, entries = 10, 100
+
+	var wg sync.WaitGroup
+	for i := 0; i < goroutines; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			enc := NewTextEncoder(TextNoTime())
+			defer enc.Free()
+			for j := 0; j < entries; j++ {
+				enc.WriteEntry(sink, "", fmt.Sprintf("g%d-%d", i, j), InfoLevel, epoch)
+				if j%10 == 0 {
+					sink.Flush()
+				}
+			}
+		}(i)
+	}
+	wg.Wait()
+	require.NoError(t, sink.Flush(), "Unexpected failure flushing.")
+
+	lines := strings.Split(strings.TrimSuffix(out.String(), "\n"), "\n")
+	require.Len(t, lines, goroutines*entries, "Expected every entry to be written.")
+	seen := make(map[string]bool, len(lines))
+	for _, line := range lines {
+		assert.True(t, strings.HasPrefix(line, "[I] g"), "Expected entries to be written intact, got %q.", line)
+		seen[line] = true
+	}
+	assert.Len(t, seen, goroutines*entries, "Expected each entry to be written once.")
+	assert.True(t, out.writes < goroutines*entries, "Expected fewer writes (%d) than entries.", out.writes)
+}
+
+func benchmarkFileSink(b *testing.B, wrap func(io.Writer) io.Writer) {
+	f, err := ioutil.TempFile("", "zap-buffered-sink")
+	require.NoError(b, err, "Failed to create temporary file.")
+	defer os.Remove(f.Name())
+	defer f.Close()
+
+	sink := wrap(f)
+	enc := NewTextEncoder()
+	defer enc.Free()
+	enc.AddString("user", "jane")
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			enc.WriteEntry(sink, "", "a fairly typical log message", InfoLevel, epoch)
+		}
+	})
+	if flusher, ok := sink.(WriteFlusher); ok {
+		flusher.Flush()
+	}
+}
+
+// The unbuffered benchmark makes a write system call per entry, while the
+// buffered one makes one per 64KiB.
+func BenchmarkUnbufferedFileSink(b *testing.B) {
+	benchmarkFileSink(b, func(w io.Writer) io.Writer { return w })
+}
+
+func BenchmarkBufferedFileSink(b *testing.B) {
+	benchmarkFileSink(b, func(w io.Writer) io.Writer { return NewBufferedSink(w, 64*1024) })
+}
@@ -0,0 +1,83 @@
+// Copyright (c) 2016 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zap
+
+import (
+	"os"
+	"time"
+)
+
+// Bunyan's numeric levels. Bunyan has no equivalent of PanicLevel, so it's
+// treated as fatal.
+const (
+	_bunyanDebug = 20
+	_bunyanInfo  = 30
+	_bunyanWarn  = 40
+	_bunyanError = 50
+	_bunyanFatal = 60
+)
+
+// NewBunyanEncoder creates a JSON encoder whose output is compatible with
+// Node.js's Bunyan, so that the bunyan CLI can pretty-print zap logs. Each
+// entry includes the fields Bunyan requires: v, name (the supplied
+// application name), hostname, pid, a numeric level, msg, and an ISO 8601
+// time. Logger names are added under the "component" key, and all other
+// fields are added at the top level.
+//
+// Additional options are applied after the Bunyan defaults, so they may
+// override them.
+func NewBunyanEncoder(name string, options ...JSONOption) Encoder {
+	opts := make([]JSONOption, 0, len(options)+4)
+	opts = append(opts,
+		LevelFormatter(bunyanLevel),
+		TimeFormatter(bunyanTime),
+		MessageKey("msg"),
+		NameKey("component"),
+	)
+	opts = append(opts, options...)
+
+	enc := NewJSONEncoder(opts...)
+	hostname, _ := os.Hostname()
+	enc.AddInt("v", 0)
+	enc.AddString("name", name)
+	enc.AddString("hostname", hostname)
+	enc.AddInt("pid", os.Getpid())
+	return enc
+}
+
+func bunyanLevel(lvl Level) Field {
+	switch {
+	case lvl <= DebugLevel:
+		return Int("level", _bunyanDebug)
+	case lvl == InfoLevel:
+		return Int("level", _bunyanInfo)
+	case lvl == WarnLevel:
+		return Int("level", _bunyanWarn)
+	case lvl == ErrorLevel:
+		return Int("level", _bunyanError)
+	default:
+		return Int("level", _bunyanFatal)
+	}
+}
+
+func bunyanTime(t time.Time) Field {
+	return String("time", t.UTC().Format("2006-01-02T15:04:05.000Z07:00"))
+}
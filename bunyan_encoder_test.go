@@ -0,0 +1,81 @@
+// Copyright (c) 2016 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zap
+
+import (
+	"encoding/json"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBunyanEncoderRequiredFields(t *testing.T) {
+	enc := NewBunyanEncoder("myapp")
+	defer enc.Free()
+	enc.AddString("user", "jane")
+
+	sink := &testBuffer{}
+	ts := time.Date(2016, time.October, 1, 12, 30, 15, 123456789, time.UTC)
+	require.NoError(t, enc.WriteEntry(sink, "db", "hello", WarnLevel, ts), "Unexpected failure writing entry.")
+
+	var entry map[string]interface{}
+	require.NoError(t, json.Unmarshal(sink.Bytes(), &entry), "Expected valid JSON output.")
+
+	hostname, _ := os.Hostname()
+	assert.Equal(t, map[string]interface{}{
+		"v":         float64(0),
+		"name":      "myapp",
+		"hostname":  hostname,
+		"pid":       float64(os.Getpid()),
+		"level":     float64(40),
+		"msg":       "hello",
+		"time":      "2016-10-01T12:30:15.123Z",
+		"component": "db",
+		"user":      "jane",
+	}, entry, "Unexpected Bunyan entry.")
+}
+
+func TestBunyanEncoderLevels(t *testing.T) {
+	tests := []struct {
+		level    Level
+		expected float64
+	}{
+		{DebugLevel, 20},
+		{InfoLevel, 30},
+		{WarnLevel, 40},
+		{ErrorLevel, 50},
+		{PanicLevel, 60},
+		{FatalLevel, 60},
+	}
+
+	enc := NewBunyanEncoder("myapp")
+	defer enc.Free()
+	for _, tt := range tests {
+		sink := &testBuffer{}
+		require.NoError(t, enc.WriteEntry(sink, "", "hello", tt.level, epoch), "Unexpected failure writing entry.")
+		var entry struct{ Level float64 }
+		require.NoError(t, json.Unmarshal(sink.Bytes(), &entry), "Expected valid JSON output.")
+		assert.Equal(t, tt.expected, entry.Level, "Unexpected Bunyan level for %s.", tt.level)
+	}
+}
@@ -1,7 +1,28 @@
 package zap
 
+import (
+	"unicode"
+	"unicode/utf8"
+)
+
 const hextable = "0123456789ABCDEF"
 
+// appendAddress appends an address as "0x" followed by its value in hex,
+// without leading zeros (e.g., "0xC000012345" or "0x0").
+func appendAddress(dst []byte, addr uintptr) []byte {
+	dst = append(dst, "0x"...)
+	if addr == 0 {
+		return append(dst, '0')
+	}
+	var scratch [16]byte
+	i := len(scratch)
+	for ; addr > 0; addr >>= 4 {
+		i--
+		scratch[i] = hextable[addr&0x0F]
+	}
+	return append(dst, scratch[i:]...)
+}
+
 func hexEncode(dst []byte, src []byte) []byte {
 	dst = append(dst, "0x"...)
 	for _, v := range src {
@@ -10,3 +31,19 @@ func hexEncode(dst []byte, src []byte) []byte {
 	}
 	return dst
 }
+
+// isPrintable reports whether src is valid UTF-8 made up entirely of
+// printable runes.
+func isPrintable(src []byte) bool {
+	if !utf8.Valid(src) {
+		return false
+	}
+	for len(src) > 0 {
+		r, size := utf8.DecodeRune(src)
+		if !unicode.IsPrint(r) {
+			return false
+		}
+		src = src[size:]
+	}
+	return true
+}
@@ -0,0 +1,94 @@
+// Copyright (c) 2016 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zap
+
+import "time"
+
+// _cloudWatchTimeFormat is the ISO 8601 layout, with millisecond precision,
+// that CloudWatch Logs Insights recognizes.
+const _cloudWatchTimeFormat = "2006-01-02T15:04:05.000Z07:00"
+
+type cloudWatchEncoder struct {
+	Encoder
+}
+
+// NewCloudWatchEncoder creates a JSON encoder whose output is easy to query
+// with AWS CloudWatch Logs Insights, which discovers top-level JSON keys
+// automatically. Each entry includes its level under the "level" key, the
+// message under "@message", an ISO 8601 time under "@timestamp", and the
+// logger name under "logger". Since Insights queries flat fields best, fields
+// added with AddMarshaler are flattened into dotted keys (e.g., "user.name")
+// rather than nested. Values added with AddObject are still serialized as
+// nested JSON.
+//
+// Additional options are applied after the CloudWatch defaults, so they may
+// override them.
+func NewCloudWatchEncoder(options ...JSONOption) Encoder {
+	opts := make([]JSONOption, 0, len(options)+4)
+	opts = append(opts,
+		LevelString("level"),
+		TimeFormatter(cloudWatchTime),
+		MessageKey("@message"),
+		NameKey("logger"),
+	)
+	opts = append(opts, options...)
+	return cloudWatchEncoder{NewJSONEncoder(opts...)}
+}
+
+func (enc cloudWatchEncoder) Clone() Encoder {
+	return cloudWatchEncoder{enc.Encoder.Clone()}
+}
+
+// AddMarshaler flattens the object's fields into keys prefixed with the
+// supplied key and a dot.
+func (enc cloudWatchEncoder) AddMarshaler(key string, obj LogMarshaler) error {
+	return obj.MarshalLog(prefixedKeyValue{enc.Encoder, key + "."})
+}
+
+func cloudWatchTime(t time.Time) Field {
+	return String("@timestamp", t.UTC().Format(_cloudWatchTimeFormat))
+}
+
+// prefixedKeyValue adds a prefix to the keys of all the fields added to it,
+// flattening any nested objects.
+type prefixedKeyValue struct {
+	kv     KeyValue
+	prefix string
+}
+
+func (p prefixedKeyValue) AddBool(key string, val bool)       { p.kv.AddBool(p.prefix+key, val) }
+func (p prefixedKeyValue) AddByte(key string, val byte)       { p.kv.AddByte(p.prefix+key, val) }
+func (p prefixedKeyValue) AddBytes(key string, val []byte)    { p.kv.AddBytes(p.prefix+key, val) }
+func (p prefixedKeyValue) AddFloat32(key string, val float32) { p.kv.AddFloat32(p.prefix+key, val) }
+func (p prefixedKeyValue) AddFloat64(key string, val float64) { p.kv.AddFloat64(p.prefix+key, val) }
+func (p prefixedKeyValue) AddInt(key string, val int)         { p.kv.AddInt(p.prefix+key, val) }
+func (p prefixedKeyValue) AddInt64(key string, val int64)     { p.kv.AddInt64(p.prefix+key, val) }
+func (p prefixedKeyValue) AddUint(key string, val uint)       { p.kv.AddUint(p.prefix+key, val) }
+func (p prefixedKeyValue) AddUint64(key string, val uint64)   { p.kv.AddUint64(p.prefix+key, val) }
+func (p prefixedKeyValue) AddString(key, val string)          { p.kv.AddString(p.prefix+key, val) }
+
+func (p prefixedKeyValue) AddMarshaler(key string, obj LogMarshaler) error {
+	return obj.MarshalLog(prefixedKeyValue{p.kv, p.prefix + key + "."})
+}
+
+func (p prefixedKeyValue) AddObject(key string, val interface{}) error {
+	return p.kv.AddObject(p.prefix+key, val)
+}
@@ -0,0 +1,83 @@
+// Copyright (c) 2016 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zap
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCloudWatchEncoder(t *testing.T) {
+	enc := NewCloudWatchEncoder()
+	defer enc.Free()
+	enc.AddString("request_id", "abc")
+
+	sink := &testBuffer{}
+	ts := time.Date(2016, 7, 1, 12, 0, 0, 123456789, time.FixedZone("PDT", -7*60*60))
+	require.NoError(t, enc.WriteEntry(sink, "svc", "hello", InfoLevel, ts), "Unexpected failure writing entry.")
+	assert.Equal(t,
+		`{"level":"info","@timestamp":"2016-07-01T19:00:00.123Z","logger":"svc","@message":"hello","request_id":"abc"}`,
+		sink.Stripped(),
+		"Unexpected CloudWatch entry.",
+	)
+}
+
+func TestCloudWatchEncoderFlattening(t *testing.T) {
+	enc := NewCloudWatchEncoder()
+	defer enc.Free()
+	user := LogMarshalerFunc(func(kv KeyValue) error {
+		kv.AddString("name", "jane")
+		kv.AddInt("age", 42)
+		return kv.AddMarshaler("address", LogMarshalerFunc(func(kv KeyValue) error {
+			kv.AddString("city", "Oakland")
+			return kv.AddObject("tags", []string{"home"})
+		}))
+	})
+	require.NoError(t, enc.AddMarshaler("user", user), "Unexpected error adding a marshaler.")
+
+	// Flattening applies to clones and to fields added with the Marshaler
+	// constructor.
+	clone := enc.Clone()
+	defer clone.Free()
+	Marshaler("session", LogMarshalerFunc(func(kv KeyValue) error {
+		kv.AddBool("new", true)
+		return nil
+	})).AddTo(clone)
+
+	sink := &testBuffer{}
+	require.NoError(t, clone.WriteEntry(sink, "", "hello", InfoLevel, epoch), "Unexpected failure writing entry.")
+	var entry map[string]interface{}
+	require.NoError(t, json.Unmarshal(sink.Bytes(), &entry), "Expected valid JSON.")
+	assert.Equal(t, map[string]interface{}{
+		"level":             "info",
+		"@timestamp":        "1970-01-01T00:00:00.000Z",
+		"@message":          "hello",
+		"user.name":         "jane",
+		"user.age":          float64(42),
+		"user.address.city": "Oakland",
+		"user.address.tags": []interface{}{"home"},
+		"session.new":       true,
+	}, entry, "Expected nested objects to be flattened into dotted keys.")
+}
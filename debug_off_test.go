@@ -0,0 +1,47 @@
+// Copyright (c) 2016 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+//go:build !zapdebug
+// +build !zapdebug
+
+package zap
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTextReleaseDebugHelpers(t *testing.T) {
+	withTextEncoder(func(enc *textEncoder) {
+		enc.AddDeep("k", []int{1, 2}, 3)
+		assert.Equal(t, "k=<debug-only>", string(enc.bytes), "Expected verbose helpers to write a placeholder in release builds.")
+	})
+
+	withTextEncoder(func(enc *textEncoder) {
+		assert.NoError(t, enc.AddObject("k", []string{"a", "b"}), "Unexpected error adding an object.")
+		assert.Equal(t, `k="[a b]"`, string(enc.bytes), "Expected AddObject to work in release builds.")
+	})
+
+	assertTextEncoderOutput(t, "AddDeep", "[I] hello k=<debug-only> n=1", func(enc TextEncoder) {
+		enc.AddDeep("k", []int{1, 2}, 3)
+		enc.AddInt("n", 1)
+	})
+}
@@ -19,4 +19,12 @@
 // THE SOFTWARE.
 
 // Package zap provides fast, structured, leveled logging in Go.
+//
+// By default, the text and ANSI encoders compile out their verbose debugging
+// helpers (like AddDeep), so debug output costs almost nothing in release
+// builds; the helpers write only their keys and the placeholder
+// "<debug-only>". AddObject and other fields are unaffected. To enable the
+// helpers, build with the zapdebug tag:
+//
+//	go build -tags zapdebug
 package zap
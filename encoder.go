@@ -23,6 +23,7 @@ package zap
 import (
 	"io"
 	"time"
+	"unsafe"
 )
 
 // Encoder is a format-agnostic interface for all log entry marshalers. Since
@@ -36,6 +37,45 @@ import (
 type Encoder interface {
 	KeyValue
 
+	// AddDuration adds a duration, rendered in a format appropriate for the
+	// encoder.
+	AddDuration(key string, value time.Duration)
+	// AddTime adds a timestamp, rendered in a format appropriate for the
+	// encoder.
+	AddTime(key string, value time.Time)
+	// AddError adds an error's message, along with the messages of any errors
+	// it wraps.
+	AddError(key string, err error)
+	// AddComplex128 and AddComplex64 add complex numbers in the form
+	// "(real+imagi)".
+	AddComplex128(key string, value complex128)
+	AddComplex64(key string, value complex64)
+	// Add sized integers, which are widened to 64 bits.
+	AddInt32(key string, value int32)
+	AddInt16(key string, value int16)
+	AddInt8(key string, value int8)
+	AddUint32(key string, value uint32)
+	AddUint16(key string, value uint16)
+	AddUint8(key string, value uint8)
+	// AddStrings and AddInts add slices as lists, rendered in a format
+	// appropriate for the encoder. Nil and empty slices are both written as
+	// empty lists.
+	AddStrings(key string, values []string)
+	AddInts(key string, values []int)
+	// AddRune adds a single Unicode code point as a character rather than
+	// an integer. Invalid code points are replaced with U+FFFD.
+	AddRune(key string, value rune)
+	// AddBase64Bytes adds binary data encoded with standard, padded base64,
+	// which is more compact than AddBytes's hex encoding.
+	AddBase64Bytes(key string, value []byte)
+	// AddStackFrames captures the current stack and adds it as a list of
+	// frames, each with a file, line, and function, excluding zap's frames.
+	AddStackFrames(key string)
+	// AddUintptr and AddPointer add memory addresses, rendered in hex with a
+	// "0x" prefix. A nil pointer is rendered as "0x0".
+	AddUintptr(key string, value uintptr)
+	AddPointer(key string, value unsafe.Pointer)
+
 	// Copy the encoder, ensuring that adding fields to the copy doesn't affect
 	// the original.
 	Clone() Encoder
@@ -0,0 +1,90 @@
+// Copyright (c) 2016 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zap
+
+import (
+	"io"
+	"time"
+)
+
+// _badKey is the key used for values that aren't preceded by a string key.
+const _badKey = "!BADKEY"
+
+// An EntryKVWriter is an Encoder that can write entries with loosely-typed
+// key-value pairs. The text, ANSI, and JSON encoders implement it.
+type EntryKVWriter interface {
+	Encoder
+
+	// WriteEntryKV is like WriteEntry, but it also adds alternating keys and
+	// values (e.g., "user", "jane", "attempts", 3) to the entry.
+	WriteEntryKV(sink io.Writer, name string, lvl Level, t time.Time, msg string, keysAndValues ...interface{}) error
+}
+
+// writeEntryKV adds alternating keys and values to a clone of the encoder
+// and writes an entry with it. A value without a string key, including an odd
+// trailing argument, is added under the "!BADKEY" key.
+func writeEntryKV(enc Encoder, sink io.Writer, name string, lvl Level, t time.Time, msg string, keysAndValues []interface{}) error {
+	if sink == nil {
+		return errNilSink
+	}
+	clone := enc.Clone()
+	defer clone.Free()
+	for i := 0; i < len(keysAndValues); {
+		key, ok := keysAndValues[i].(string)
+		if !ok || i == len(keysAndValues)-1 {
+			addValue(clone, _badKey, keysAndValues[i])
+			i++
+			continue
+		}
+		addValue(clone, key, keysAndValues[i+1])
+		i += 2
+	}
+	return clone.WriteEntry(sink, name, msg, lvl, t)
+}
+
+// WriteEntryKV is like WriteEntry, but it also adds loosely-typed key-value
+// pairs (e.g., "user", "jane", "attempts", 3) to the entry, which eases
+// migrating from printf-style and sugared loggers. Values are added with the
+// Add method matching their type. An argument in a key's position that isn't
+// a string, and a trailing key without a value, are added under the "!BADKEY"
+// key. Entries filtered out by the encoder's level options are dropped before
+// any fields are added.
+func (enc *textEncoder) WriteEntryKV(sink io.Writer, name string, lvl Level, t time.Time, msg string, keysAndValues ...interface{}) error {
+	if sink != nil && enc.filtered(lvl, t) {
+		return nil
+	}
+	return writeEntryKV(enc, sink, name, lvl, t, msg, keysAndValues)
+}
+
+// WriteEntryKV is like WriteEntry, but it also adds loosely-typed key-value
+// pairs to the entry; see the text encoder's WriteEntryKV for details.
+func (enc *ansiEncoder) WriteEntryKV(sink io.Writer, name string, lvl Level, t time.Time, msg string, keysAndValues ...interface{}) error {
+	if sink != nil && enc.filtered(lvl, t) {
+		return nil
+	}
+	return writeEntryKV(enc, sink, name, lvl, t, msg, keysAndValues)
+}
+
+// WriteEntryKV is like WriteEntry, but it also adds loosely-typed key-value
+// pairs to the entry; see the text encoder's WriteEntryKV for details.
+func (enc *jsonEncoder) WriteEntryKV(sink io.Writer, name string, lvl Level, t time.Time, msg string, keysAndValues ...interface{}) error {
+	return writeEntryKV(enc, sink, name, lvl, t, msg, keysAndValues)
+}
@@ -0,0 +1,84 @@
+// Copyright (c) 2016 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zap
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWriteEntryKV(t *testing.T) {
+	tests := []struct {
+		desc     string
+		kvs      []interface{}
+		expected string
+	}{
+		{
+			"typed values",
+			[]interface{}{"user", "jane", "attempts", 3, "ok", true, "ratio", 0.5, "err", errors.New("boom"), "obj", loggable{true}},
+			"[I] hello foo=bar user=jane attempts=3 ok=true ratio=0.5 err=boom obj={loggable=yes}",
+		},
+		{"odd trailing argument", []interface{}{"user", "jane", "dangling"}, "[I] hello foo=bar user=jane !BADKEY=dangling"},
+		{"non-string key", []interface{}{42, "user", "jane"}, "[I] hello foo=bar !BADKEY=42 user=jane"},
+		{"no pairs", nil, "[I] hello foo=bar"},
+	}
+
+	for _, tt := range tests {
+		enc := NewTextEncoder(TextNoTime()).(TextEncoder)
+		enc.AddString("foo", "bar")
+		sink := &testBuffer{}
+		assert.NoError(t, enc.WriteEntryKV(sink, "", InfoLevel, epoch, "hello", tt.kvs...), "Unexpected failure writing entry (%s).", tt.desc)
+		assert.Equal(t, tt.expected, sink.Stripped(), "Unexpected output (%s).", tt.desc)
+
+		sink.Reset()
+		assert.NoError(t, enc.WriteEntry(sink, "", "hello", InfoLevel, epoch), "Unexpected failure writing entry.")
+		assert.Equal(t, "[I] hello foo=bar", sink.Stripped(), "WriteEntryKV shouldn't modify the encoder (%s).", tt.desc)
+		enc.Free()
+	}
+}
+
+func TestWriteEntryKVEncoders(t *testing.T) {
+	tests := []struct {
+		enc      EntryKVWriter
+		expected string
+	}{
+		{NewANSIEncoder(AnsiTextOption(TextNoTime())).(EntryKVWriter), defaultInfoColor + "[I] hello user=jane n=1" + resetColor},
+		{NewJSONEncoder().(EntryKVWriter), `{"level":"info","ts":0,"msg":"hello","user":"jane","n":1}`},
+	}
+
+	for _, tt := range tests {
+		sink := &testBuffer{}
+		assert.NoError(t, tt.enc.WriteEntryKV(sink, "", InfoLevel, epoch, "hello", "user", "jane", "n", 1), "Unexpected failure writing entry.")
+		assert.Equal(t, tt.expected, sink.Stripped(), "Unexpected output.")
+		assert.Equal(t, errNilSink, tt.enc.WriteEntryKV(nil, "", InfoLevel, epoch, "hello"), "Expected an error writing to a nil sink.")
+		tt.enc.Free()
+	}
+}
+
+func TestWriteEntryKVFiltered(t *testing.T) {
+	enc := NewTextEncoder(TextMinLevel(WarnLevel)).(*textEncoder)
+	defer enc.Free()
+	sink := &testBuffer{}
+	assert.NoError(t, enc.WriteEntryKV(sink, "", InfoLevel, epoch, "hello", "user", "jane"), "Unexpected failure writing a filtered entry.")
+	assert.Equal(t, 0, sink.Len(), "Expected filtered entries to be dropped.")
+}
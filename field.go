@@ -37,10 +37,12 @@ const (
 	float32Type
 	float64Type
 	intType
+	int8Type
 	int16Type
 	int32Type
 	int64Type
 	uintType
+	uint8Type
 	uint16Type
 	uint32Type
 	uint64Type
@@ -116,6 +118,12 @@ func Int(key string, val int) Field {
 	return Field{key: key, fieldType: intType, ival: int64(val)}
 }
 
+// Int8 constructs a Field with the given key and value. Like ints, int8s are
+// marshaled lazily.
+func Int8(key string, val int8) Field {
+	return Field{key: key, fieldType: int8Type, ival: int64(val)}
+}
+
 // Int16 constructs a Field with the given key and value. Like ints, int16s are
 // marshaled lazily.
 func Int16(key string, val int16) Field {
@@ -139,6 +147,12 @@ func Uint(key string, val uint) Field {
 	return Field{key: key, fieldType: uintType, ival: int64(val)}
 }
 
+// Uint8 constructs a Field with the given key and value. Unlike Byte, it's
+// rendered as an integer.
+func Uint8(key string, val uint8) Field {
+	return Field{key: key, fieldType: uint8Type, ival: int64(val)}
+}
+
 // Uint16 constructs a Field with the given key and value.
 func Uint16(key string, val uint16) Field {
 	return Field{key: key, fieldType: uint16Type, ival: int64(val)}
@@ -248,11 +262,11 @@ func (f Field) AddTo(kv KeyValue) {
 		kv.AddFloat64(f.key, math.Float64frombits(uint64(f.ival)))
 	case intType:
 		kv.AddInt(f.key, int(f.ival))
-	case int16Type, int32Type, int64Type:
+	case int8Type, int16Type, int32Type, int64Type:
 		kv.AddInt64(f.key, f.ival)
 	case uintType:
 		kv.AddUint(f.key, uint(f.ival))
-	case uint16Type, uint32Type, uint64Type:
+	case uint8Type, uint16Type, uint32Type, uint64Type:
 		kv.AddUint64(f.key, uint64(f.ival))
 	case stringType:
 		kv.AddString(f.key, f.str)
@@ -126,6 +126,11 @@ func TestIntField(t *testing.T) {
 	assertCanBeReused(t, Int("foo", 1))
 }
 
+func TestInt8Field(t *testing.T) {
+	assertFieldJSON(t, `"foo":-1`, Int8("foo", int8(-1)))
+	assertCanBeReused(t, Int8("foo", int8(-1)))
+}
+
 func TestInt16Field(t *testing.T) {
 	assertFieldJSON(t, `"foo":1`, Int16("foo", int16(1)))
 	assertCanBeReused(t, Int16("foo", int16(1)))
@@ -146,6 +151,11 @@ func TestUintField(t *testing.T) {
 	assertCanBeReused(t, Uint("foo", 1))
 }
 
+func TestUint8Field(t *testing.T) {
+	assertFieldJSON(t, `"foo":255`, Uint8("foo", uint8(255)))
+	assertCanBeReused(t, Uint8("foo", uint8(255)))
+}
+
 func TestUint16Field(t *testing.T) {
 	assertFieldJSON(t, `"foo":1`, Uint16("foo", uint16(1)))
 	assertCanBeReused(t, Uint16("foo", uint16(1)))
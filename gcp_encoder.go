@@ -0,0 +1,131 @@
+// Copyright (c) 2016 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zap
+
+import (
+	"io"
+	"time"
+)
+
+// _gcpSourceLocationKey is the key Cloud Logging reads source locations from.
+const _gcpSourceLocationKey = "logging.googleapis.com/sourceLocation"
+
+type gcpEncoder struct {
+	Encoder
+	sourceLocation bool
+}
+
+// NewGCPEncoder creates a JSON encoder whose output is understood by Google
+// Cloud Logging's structured logging agent. Each entry includes a severity
+// string that Cloud Logging accepts (DEBUG, INFO, WARNING, ERROR, CRITICAL,
+// ALERT, or EMERGENCY), the message under the "message" key, and an RFC3339
+// time. Logger names are added under the "logger" key. With the
+// GCPSourceLocation option, the caller information that the AddCaller option
+// prefixes to messages is moved into a "logging.googleapis.com/sourceLocation"
+// object, which the log viewer links to.
+//
+// Additional options are applied after the Cloud Logging defaults, so they
+// may override them.
+func NewGCPEncoder(options ...JSONOption) Encoder {
+	opts := make([]JSONOption, 0, len(options)+4)
+	opts = append(opts,
+		LevelFormatter(gcpSeverity),
+		TimeFormatter(gcpTime),
+		MessageKey("message"),
+		NameKey("logger"),
+	)
+	opts = append(opts, options...)
+	enc := gcpEncoder{Encoder: NewJSONEncoder(opts...)}
+	for _, opt := range options {
+		if _, ok := opt.(gcpSourceLocationOption); ok {
+			enc.sourceLocation = true
+		}
+	}
+	return enc
+}
+
+// GCPSourceLocation makes a GCP encoder treat the "file:line: " prefix that
+// the AddCaller option adds to messages as caller information, and move it into
+// a "logging.googleapis.com/sourceLocation" object. Only use it with loggers
+// that have the AddCaller option. Otherwise, messages that happen to start
+// with something like "host:5432: " lose their prefix to a bogus source
+// location. It has no effect on other JSON encoders.
+func GCPSourceLocation() JSONOption {
+	return gcpSourceLocationOption{}
+}
+
+type gcpSourceLocationOption struct{}
+
+func (gcpSourceLocationOption) apply(*jsonEncoder) {}
+
+func (enc gcpEncoder) Clone() Encoder {
+	return gcpEncoder{enc.Encoder.Clone(), enc.sourceLocation}
+}
+
+func (enc gcpEncoder) WriteEntry(sink io.Writer, name string, msg string, lvl Level, t time.Time) error {
+	if !enc.sourceLocation {
+		return enc.Encoder.WriteEntry(sink, name, msg, lvl, t)
+	}
+	file, line, ok := splitCaller(msg)
+	if !ok {
+		return enc.Encoder.WriteEntry(sink, name, msg, lvl, t)
+	}
+	withCaller := enc.Encoder.Clone()
+	defer withCaller.Free()
+	withCaller.AddMarshaler(_gcpSourceLocationKey, gcpSourceLocation{file, line})
+	// Skip the caller and the ": " separator.
+	return withCaller.WriteEntry(sink, name, msg[len(file)+len(line)+3:], lvl, t)
+}
+
+type gcpSourceLocation struct {
+	file, line string
+}
+
+func (loc gcpSourceLocation) MarshalLog(kv KeyValue) error {
+	kv.AddString("file", loc.file)
+	// Cloud Logging's API represents lines as 64-bit integers, which its JSON
+	// mapping encodes as strings.
+	kv.AddString("line", loc.line)
+	return nil
+}
+
+func gcpSeverity(lvl Level) Field {
+	switch lvl {
+	case DebugLevel:
+		return String("severity", "DEBUG")
+	case InfoLevel:
+		return String("severity", "INFO")
+	case WarnLevel:
+		return String("severity", "WARNING")
+	case ErrorLevel:
+		return String("severity", "ERROR")
+	case PanicLevel:
+		return String("severity", "CRITICAL")
+	case FatalLevel:
+		return String("severity", "EMERGENCY")
+	default:
+		return String("severity", "DEFAULT")
+	}
+}
+
+func gcpTime(t time.Time) Field {
+	return String("time", t.UTC().Format(time.RFC3339Nano))
+}
@@ -0,0 +1,111 @@
+// Copyright (c) 2016 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zap
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGCPEncoderSeverity(t *testing.T) {
+	tests := []struct {
+		lvl      Level
+		severity string
+	}{
+		{DebugLevel, "DEBUG"},
+		{InfoLevel, "INFO"},
+		{WarnLevel, "WARNING"},
+		{ErrorLevel, "ERROR"},
+		{PanicLevel, "CRITICAL"},
+		{FatalLevel, "EMERGENCY"},
+		{Level(42), "DEFAULT"},
+	}
+
+	enc := NewGCPEncoder()
+	defer enc.Free()
+	for _, tt := range tests {
+		sink := &testBuffer{}
+		require.NoError(t, enc.WriteEntry(sink, "", "hello", tt.lvl, epoch), "Unexpected failure writing entry.")
+		assert.Equal(t, `{"severity":"`+tt.severity+`","time":"1970-01-01T00:00:00Z","message":"hello"}`, sink.Stripped(), "Unexpected output for level %v.", tt.lvl)
+	}
+}
+
+func TestGCPEncoderSourceLocation(t *testing.T) {
+	enc := NewGCPEncoder(GCPSourceLocation())
+	defer enc.Free()
+	enc.AddString("foo", "bar")
+	sink := &testBuffer{}
+	require.NoError(t, enc.WriteEntry(sink, "svc", "server.go:42: listening", InfoLevel, epoch), "Unexpected failure writing entry.")
+
+	var entry map[string]interface{}
+	require.NoError(t, json.Unmarshal(sink.Bytes(), &entry), "Expected valid JSON.")
+	assert.Equal(t, map[string]interface{}{
+		"severity": "INFO",
+		"time":     "1970-01-01T00:00:00Z",
+		"logger":   "svc",
+		"message":  "listening",
+		"foo":      "bar",
+		"logging.googleapis.com/sourceLocation": map[string]interface{}{
+			"file": "server.go",
+			"line": "42",
+		},
+	}, entry, "Unexpected entry with caller information.")
+
+	sink.Reset()
+	require.NoError(t, enc.WriteEntry(sink, "", "no caller", InfoLevel, epoch), "Unexpected failure writing entry.")
+	assert.Equal(t, `{"severity":"INFO","time":"1970-01-01T00:00:00Z","message":"no caller","foo":"bar"}`, sink.Stripped(), "Writing an entry with a caller shouldn't modify the encoder.")
+}
+
+func TestGCPEncoderSourceLocationClone(t *testing.T) {
+	enc := NewGCPEncoder(GCPSourceLocation())
+	defer enc.Free()
+	clone := enc.Clone()
+	defer clone.Free()
+
+	sink := &testBuffer{}
+	require.NoError(t, clone.WriteEntry(sink, "", "server.go:42: listening", InfoLevel, epoch), "Unexpected failure writing entry.")
+	assert.Contains(t, sink.Stripped(), `"logging.googleapis.com/sourceLocation":{"file":"server.go","line":"42"}`, "Expected clones to keep extracting source locations.")
+}
+
+func TestGCPEncoderMessagesWithoutCaller(t *testing.T) {
+	msg := "db.internal:5432: connection refused"
+	expected := `{"severity":"ERROR","time":"1970-01-01T00:00:00Z","message":"db.internal:5432: connection refused"}`
+
+	enc := NewGCPEncoder()
+	defer enc.Free()
+	sink := &testBuffer{}
+	require.NoError(t, enc.WriteEntry(sink, "", msg, ErrorLevel, epoch), "Unexpected failure writing entry.")
+	assert.Equal(t, expected, sink.Stripped(), "Expected messages to pass through unchanged without the GCPSourceLocation option.")
+
+	logger := New(NewGCPEncoder(GCPSourceLocation(), NoTime()), DebugLevel, Output(AddSync(sink)), AddCaller())
+	sink.Reset()
+	logger.Error(msg)
+	var entry map[string]interface{}
+	require.NoError(t, json.Unmarshal(sink.Bytes(), &entry), "Expected valid JSON.")
+	assert.Equal(t, msg, entry["message"], "Expected only the caller added by AddCaller to be removed from the message.")
+	loc, ok := entry["logging.googleapis.com/sourceLocation"].(map[string]interface{})
+	require.True(t, ok, "Expected a source location object.")
+	assert.Equal(t, "gcp_encoder_test.go", loc["file"], "Expected the caller's file in the source location.")
+	assert.Regexp(t, `^\d+$`, loc["line"], "Expected the caller's line in the source location.")
+}
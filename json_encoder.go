@@ -23,12 +23,13 @@ package zap
 import (
 	"encoding/json"
 	"errors"
-	"fmt"
 	"io"
 	"math"
 	"strconv"
 	"sync"
 	"time"
+	"unsafe"
+	"unicode/utf16"
 	"unicode/utf8"
 )
 
@@ -64,6 +65,10 @@ type jsonEncoder struct {
 	messageF MessageFormatter
 	timeF    TimeFormatter
 	levelF   LevelFormatter
+	// Whether to write null for an empty logger name or message.
+	explicitNulls bool
+	// Whether to escape all non-ASCII characters.
+	escapeUnicode bool
 }
 
 // NewJSONEncoder creates a fast, low-allocation JSON encoder. By default, JSON
@@ -87,6 +92,8 @@ func NewJSONEncoder(options ...JSONOption) Encoder {
 	enc.messageF = defaultMessageF
 	enc.timeF = defaultTimeF
 	enc.levelF = defaultLevelF
+	enc.explicitNulls = false
+	enc.escapeUnicode = false
 	for _, opt := range options {
 		opt.apply(enc)
 	}
@@ -159,6 +166,42 @@ func (enc *jsonEncoder) AddUint64(key string, val uint64) {
 	enc.bytes = strconv.AppendUint(enc.bytes, val, 10)
 }
 
+// AddInt32 adds a string key and integer value to the encoder's fields. The key
+// is JSON-escaped.
+func (enc *jsonEncoder) AddInt32(key string, val int32) {
+	enc.AddInt64(key, int64(val))
+}
+
+// AddInt16 adds a string key and integer value to the encoder's fields. The key
+// is JSON-escaped.
+func (enc *jsonEncoder) AddInt16(key string, val int16) {
+	enc.AddInt64(key, int64(val))
+}
+
+// AddInt8 adds a string key and integer value to the encoder's fields. The key
+// is JSON-escaped.
+func (enc *jsonEncoder) AddInt8(key string, val int8) {
+	enc.AddInt64(key, int64(val))
+}
+
+// AddUint32 adds a string key and integer value to the encoder's fields. The key
+// is JSON-escaped.
+func (enc *jsonEncoder) AddUint32(key string, val uint32) {
+	enc.AddUint64(key, uint64(val))
+}
+
+// AddUint16 adds a string key and integer value to the encoder's fields. The key
+// is JSON-escaped.
+func (enc *jsonEncoder) AddUint16(key string, val uint16) {
+	enc.AddUint64(key, uint64(val))
+}
+
+// AddUint8 adds a string key and integer value to the encoder's fields. The key
+// is JSON-escaped.
+func (enc *jsonEncoder) AddUint8(key string, val uint8) {
+	enc.AddUint64(key, uint64(val))
+}
+
 // AddFloat32 adds a string key and float32 value to the encoder's fields. The
 // key is JSON-escaped, and the floating-point value is encoded using
 // strconv.FormatFloat's 'f' option (always use grade-school notation, even for
@@ -189,6 +232,117 @@ func (enc *jsonEncoder) addFloat(key string, val float64, bitSize int) {
 	}
 }
 
+// AddDuration adds a duration as an integer number of nanoseconds, matching
+// the Duration field.
+func (enc *jsonEncoder) AddDuration(key string, val time.Duration) {
+	enc.AddInt64(key, int64(val))
+}
+
+// AddTime adds a timestamp as a floating-point number of seconds since epoch,
+// matching the Time field.
+func (enc *jsonEncoder) AddTime(key string, val time.Time) {
+	enc.AddFloat64(key, timeToSeconds(val))
+}
+
+// AddError adds an error's message, along with the messages of any errors it
+// wraps. A nil error is written as null.
+func (enc *jsonEncoder) AddError(key string, err error) {
+	if err == nil {
+		enc.addKey(key)
+		enc.bytes = append(enc.bytes, "null"...)
+		return
+	}
+	enc.AddString(key, errorChain(err))
+}
+
+// AddComplex128 adds a complex number as a string in the form "(1.5-2i)",
+// since JSON has no complex type.
+func (enc *jsonEncoder) AddComplex128(key string, val complex128) {
+	enc.AddString(key, string(appendComplex(nil, val, 64)))
+}
+
+// AddComplex64 adds a complex number as a string in the form "(1.5-2i)",
+// since JSON has no complex type.
+func (enc *jsonEncoder) AddComplex64(key string, val complex64) {
+	enc.AddString(key, string(appendComplex(nil, complex128(val), 32)))
+}
+
+// AddStrings adds a JSON array of strings. The key and values are
+// JSON-escaped, and a nil slice is written as an empty array.
+func (enc *jsonEncoder) AddStrings(key string, vals []string) {
+	enc.addKey(key)
+	enc.bytes = append(enc.bytes, '[')
+	for i, v := range vals {
+		if i > 0 {
+			enc.bytes = append(enc.bytes, ',')
+		}
+		enc.bytes = append(enc.bytes, '"')
+		enc.safeAddString(v)
+		enc.bytes = append(enc.bytes, '"')
+	}
+	enc.bytes = append(enc.bytes, ']')
+}
+
+// AddInts adds a JSON array of integers. A nil slice is written as an empty
+// array.
+func (enc *jsonEncoder) AddInts(key string, vals []int) {
+	enc.addKey(key)
+	enc.bytes = append(enc.bytes, '[')
+	for i, v := range vals {
+		if i > 0 {
+			enc.bytes = append(enc.bytes, ',')
+		}
+		enc.bytes = strconv.AppendInt(enc.bytes, int64(v), 10)
+	}
+	enc.bytes = append(enc.bytes, ']')
+}
+
+// AddRune adds a single character as a JSON string. Invalid code points are
+// replaced with U+FFFD.
+func (enc *jsonEncoder) AddRune(key string, val rune) {
+	enc.AddString(key, string(val))
+}
+
+// AddBase64Bytes adds binary data as a string encoded with standard, padded
+// base64.
+func (enc *jsonEncoder) AddBase64Bytes(key string, val []byte) {
+	enc.addKey(key)
+	enc.bytes = append(enc.bytes, '"')
+	enc.bytes = appendBase64(enc.bytes, val)
+	enc.bytes = append(enc.bytes, '"')
+}
+
+// AddStackFrames captures the current stack and adds it as an array of
+// objects with "file", "line", and "func" keys, innermost frame first. Zap's
+// own frames are excluded.
+func (enc *jsonEncoder) AddStackFrames(key string) {
+	enc.addKey(key)
+	enc.bytes = append(enc.bytes, '[')
+	for i, f := range takeStackFrames() {
+		if i > 0 {
+			enc.bytes = append(enc.bytes, ',')
+		}
+		enc.bytes = append(enc.bytes, '{')
+		f.MarshalLog(enc)
+		enc.bytes = append(enc.bytes, '}')
+	}
+	enc.bytes = append(enc.bytes, ']')
+}
+
+// AddUintptr adds an address as a hex string, since JSON numbers can't
+// represent every 64-bit address exactly.
+func (enc *jsonEncoder) AddUintptr(key string, val uintptr) {
+	enc.addKey(key)
+	enc.bytes = append(enc.bytes, '"')
+	enc.bytes = appendAddress(enc.bytes, val)
+	enc.bytes = append(enc.bytes, '"')
+}
+
+// AddPointer adds the address a pointer holds, like AddUintptr.
+func (enc *jsonEncoder) AddPointer(key string, val unsafe.Pointer) {
+	enc.AddUintptr(key, uintptr(val))
+}
+
 // AddMarshaler adds a LogMarshaler to the encoder's fields.
 func (enc *jsonEncoder) AddMarshaler(key string, obj LogMarshaler) error {
 	enc.addKey(key)
@@ -198,8 +352,12 @@ func (enc *jsonEncoder) AddMarshaler(key string, obj LogMarshaler) error {
 	return err
 }
 
-// AddObject uses reflection to add an arbitrary object to the logging context.
+// AddObject uses reflection to add an arbitrary object to the logging context,
+// unless a formatter is registered for its type (see RegisterObjectFormatter).
 func (enc *jsonEncoder) AddObject(key string, obj interface{}) error {
+	if ok, err := addFormattedObject(enc, key, obj); ok {
+		return err
+	}
 	marshaled, err := json.Marshal(obj)
 	if err != nil {
 		return err
@@ -218,6 +376,8 @@ func (enc *jsonEncoder) Clone() Encoder {
 	clone.messageF = enc.messageF
 	clone.timeF = enc.timeF
 	clone.levelF = enc.levelF
+	clone.explicitNulls = enc.explicitNulls
+	clone.escapeUnicode = enc.escapeUnicode
 	return clone
 }
 
@@ -232,13 +392,20 @@ func (enc *jsonEncoder) WriteEntry(sink io.Writer, name string, msg string, lvl
 
 	final := jsonPool.Get().(*jsonEncoder)
 	final.truncate()
+	final.escapeUnicode = enc.escapeUnicode
 	final.bytes = append(final.bytes, '{')
 	enc.levelF(lvl).AddTo(final)
 	enc.timeF(t).AddTo(final)
 	if name != "" {
 		enc.nameF(name).AddTo(final)
+	} else if enc.explicitNulls {
+		final.addNull(enc.nameF(name))
+	}
+	if msg == "" && enc.explicitNulls {
+		final.addNull(enc.messageF(msg))
+	} else {
+		enc.messageF(msg).AddTo(final)
 	}
-	enc.messageF(msg).AddTo(final)
 	if len(enc.bytes) > 0 {
 		if len(final.bytes) > 1 {
 			// All the formatters may have been no-ops.
@@ -255,11 +422,21 @@ func (enc *jsonEncoder) WriteEntry(sink io.Writer, name string, msg string, lvl
 		return err
 	}
 	if n != expectedBytes {
-		return fmt.Errorf("incomplete write: only wrote %v of %v bytes", n, expectedBytes)
+		return &ShortWriteError{Wrote: n, Expected: expectedBytes}
 	}
 	return nil
 }
 
+// addNull adds a null value under the field's key, unless the field is a
+// no-op.
+func (enc *jsonEncoder) addNull(f Field) {
+	if f.fieldType == skipType {
+		return
+	}
+	enc.addKey(f.key)
+	enc.bytes = append(enc.bytes, "null"...)
+}
+
 func (enc *jsonEncoder) truncate() {
 	enc.bytes = enc.bytes[:0]
 }
@@ -308,7 +485,27 @@ func (enc *jsonEncoder) safeAddString(s string) {
 			i++
 			continue
 		}
-		enc.bytes = append(enc.bytes, s[i:i+size]...)
 		i += size
+		if enc.escapeUnicode {
+			enc.addRuneEscape(c)
+			continue
+		}
+		enc.bytes = append(enc.bytes, s[i-size:i]...)
+	}
+}
+
+// addRuneEscape appends a \uXXXX escape for a non-ASCII rune, using a UTF-16
+// surrogate pair for runes outside the Basic Multilingual Plane.
+func (enc *jsonEncoder) addRuneEscape(r rune) {
+	if r1, r2 := utf16.EncodeRune(r); r1 != utf8.RuneError {
+		enc.addUTF16Escape(r1)
+		enc.addUTF16Escape(r2)
+		return
 	}
+	enc.addUTF16Escape(r)
+}
+
+func (enc *jsonEncoder) addUTF16Escape(r rune) {
+	enc.bytes = append(enc.bytes, '\\', 'u')
+	enc.bytes = append(enc.bytes, _hex[r>>12&0xF], _hex[r>>8&0xF], _hex[r>>4&0xF], _hex[r&0xF])
 }
@@ -22,6 +22,7 @@ package zap
 
 import (
 	"bytes"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
@@ -303,3 +304,43 @@ func TestJSONOptions(t *testing.T) {
 		)
 	}
 }
+
+func TestJSONWriteEntryRoundTrip(t *testing.T) {
+	enc := NewJSONEncoder()
+	defer enc.Free()
+	enc.AddString("str", "quote \" and newline \n")
+	enc.AddBool("bool", true)
+	enc.AddInt("int", -1)
+	enc.AddUint64("uint64", math.MaxUint32)
+	enc.AddFloat64("float64", 1.5)
+	enc.AddDuration("duration", time.Second)
+	enc.AddError("error", errors.New("fail"))
+	enc.AddComplex128("complex", complex(1, -2))
+	enc.AddInt8("int8", math.MinInt8)
+	require.NoError(t, enc.AddMarshaler("obj", loggable{true}), "Unexpected error adding a marshaler.")
+	require.NoError(t, enc.AddObject("slice", []int{1, 2}), "Unexpected error adding an object.")
+
+	sink := &bytes.Buffer{}
+	require.NoError(t, enc.WriteEntry(sink, "svc", "hello", InfoLevel, epoch), "Unexpected error writing entry.")
+	assert.Equal(t, 1, strings.Count(sink.String(), "\n"), "Expected newline-delimited JSON.")
+
+	var entry map[string]interface{}
+	require.NoError(t, json.Unmarshal(sink.Bytes(), &entry), "Expected valid JSON.")
+	assert.Equal(t, map[string]interface{}{
+		"level":    "info",
+		"ts":       float64(0),
+		"name":     "svc",
+		"msg":      "hello",
+		"str":      "quote \" and newline \n",
+		"bool":     true,
+		"int":      float64(-1),
+		"uint64":   float64(math.MaxUint32),
+		"float64":  1.5,
+		"duration": float64(time.Second),
+		"error":    "fail",
+		"complex":  "(1-2i)",
+		"int8":     float64(math.MinInt8),
+		"obj":      map[string]interface{}{"loggable": "yes"},
+		"slice":    []interface{}{float64(1), float64(2)},
+	}, entry, "Unexpected round-tripped entry.")
+}
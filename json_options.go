@@ -28,6 +28,33 @@ type JSONOption interface {
 	apply(*jsonEncoder)
 }
 
+type jsonOptionFunc func(*jsonEncoder)
+
+func (opt jsonOptionFunc) apply(enc *jsonEncoder) {
+	opt(enc)
+}
+
+// JSONExplicitNulls writes null under the logger name and message keys when an
+// entry's name or message is empty, rather than omitting the name or writing
+// an empty message. This gives every entry the same set of keys, which some
+// schema validators require.
+func JSONExplicitNulls() JSONOption {
+	return jsonOptionFunc(func(enc *jsonEncoder) {
+		enc.explicitNulls = true
+	})
+}
+
+// JSONEscapeUnicode escapes every non-ASCII character as \uXXXX (or a UTF-16
+// surrogate pair of escapes), so the encoded output is pure ASCII. By default,
+// the encoder writes UTF-8 literally, which JSON permits and which is smaller
+// and easier to read for non-Latin text; escaping is only useful for
+// consumers that can't handle UTF-8.
+func JSONEscapeUnicode() JSONOption {
+	return jsonOptionFunc(func(enc *jsonEncoder) {
+		enc.escapeUnicode = true
+	})
+}
+
 // A MessageFormatter defines how to convert a log message into a Field.
 // MessageFormatters implement the JSONOption interface.
 type MessageFormatter func(string) Field
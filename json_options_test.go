@@ -21,6 +21,7 @@
 package zap
 
 import (
+	"encoding/json"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -92,3 +93,59 @@ func TestLevelFormatters(t *testing.T) {
 		assert.Equal(t, tt.expected, tt.formatter(lvl), "Unexpected output from LevelFormatter %s.", tt.name)
 	}
 }
+
+func TestJSONExplicitNulls(t *testing.T) {
+	tests := []struct {
+		desc     string
+		options  []JSONOption
+		name     string
+		msg      string
+		expected string
+	}{
+		{"default", nil, "", "", `{"level":"info","ts":0,"msg":""}`},
+		{"explicit nulls", []JSONOption{JSONExplicitNulls()}, "", "", `{"level":"info","ts":0,"name":null,"msg":null}`},
+		{"explicit nulls with values", []JSONOption{JSONExplicitNulls()}, "svc", "hi", `{"level":"info","ts":0,"name":"svc","msg":"hi"}`},
+		{"custom keys", []JSONOption{JSONExplicitNulls(), NameKey("logger"), MessageKey("message")}, "", "", `{"level":"info","ts":0,"logger":null,"message":null}`},
+		{"skipped name", []JSONOption{JSONExplicitNulls(), NameFormatter(func(string) Field { return Skip() })}, "", "", `{"level":"info","ts":0,"msg":null}`},
+	}
+
+	for _, tt := range tests {
+		enc := NewJSONEncoder(tt.options...)
+		clone := enc.Clone()
+		for _, e := range []Encoder{enc, clone} {
+			sink := &testBuffer{}
+			assert.NoError(t, e.WriteEntry(sink, tt.name, tt.msg, InfoLevel, epoch), "Unexpected failure writing entry (%s).", tt.desc)
+			assert.Equal(t, tt.expected, sink.Stripped(), "Unexpected output (%s).", tt.desc)
+		}
+		clone.Free()
+		enc.Free()
+	}
+}
+
+func TestJSONEscapeUnicode(t *testing.T) {
+	tests := []struct {
+		desc     string
+		options  []JSONOption
+		expected string
+	}{
+		{"default", nil, `{"level":"info","ts":0,"msg":"日本語 ☃","user":"山田","emoji":"😀"}`},
+		{"escaped", []JSONOption{JSONEscapeUnicode()}, `{"level":"info","ts":0,"msg":"\u65e5\u672c\u8a9e \u2603","user":"\u5c71\u7530","emoji":"\ud83d\ude00"}`},
+	}
+
+	for _, tt := range tests {
+		enc := NewJSONEncoder(tt.options...)
+		enc.AddString("user", "山田")
+		enc.AddString("emoji", "😀")
+		clone := enc.Clone()
+		for _, e := range []Encoder{enc, clone} {
+			sink := &testBuffer{}
+			assert.NoError(t, e.WriteEntry(sink, "", "日本語 ☃", InfoLevel, epoch), "Unexpected failure writing entry (%s).", tt.desc)
+			assert.Equal(t, tt.expected, sink.Stripped(), "Unexpected output (%s).", tt.desc)
+			var decoded map[string]interface{}
+			assert.NoError(t, json.Unmarshal(sink.Bytes(), &decoded), "Expected valid JSON (%s).", tt.desc)
+			assert.Equal(t, "😀", decoded["emoji"], "Unexpected round trip (%s).", tt.desc)
+		}
+		clone.Free()
+		enc.Free()
+	}
+}
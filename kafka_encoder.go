@@ -0,0 +1,118 @@
+// Copyright (c) 2016 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zap
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"sync"
+	"time"
+)
+
+var _framePool = sync.Pool{New: func() interface{} {
+	return bytes.NewBuffer(make([]byte, 0, _initialBufSize))
+}}
+
+type kafkaFrameEncoder struct {
+	Encoder
+	// If true, the wrapped encoder frames its own writes (see
+	// lengthPrefixer), so entries are written straight to the sink.
+	direct bool
+}
+
+// A lengthPrefixer is an encoder that can prefix each of its writes with
+// the write's length, which lets it write frames directly to the sink. The
+// text and ANSI encoders are lengthPrefixers, so their per-sink options
+// (e.g., TextFormatHeader and TextWriteTimeout) and hooks (e.g., FatalHook)
+// see the real sink rather than an intermediate buffer.
+type lengthPrefixer interface {
+	prefixLengths()
+}
+
+// NewKafkaFrameEncoder wraps an encoder, prefixing each entry it writes with
+// the entry's length as a 4-byte, big-endian integer. This lets consumers read
+// discrete records (e.g., Kafka messages) off a byte stream, regardless of the
+// wrapped encoder's output format. The returned Encoder takes ownership of
+// the wrapped encoder, which shouldn't be used directly afterward.
+//
+// Text and ANSI encoders write each frame directly to the sink, so their
+// per-sink options and hooks behave as they do without framing; a per-sink
+// preamble, like the format header, shares a frame with the sink's first
+// entry. Other encoders render each entry into a buffer before it's framed.
+func NewKafkaFrameEncoder(inner Encoder) Encoder {
+	if lp, ok := inner.(lengthPrefixer); ok {
+		lp.prefixLengths()
+		return kafkaFrameEncoder{inner, true}
+	}
+	return kafkaFrameEncoder{inner, false}
+}
+
+func (enc kafkaFrameEncoder) Clone() Encoder {
+	return kafkaFrameEncoder{enc.Encoder.Clone(), enc.direct}
+}
+
+func (enc kafkaFrameEncoder) WriteEntry(sink io.Writer, name string, msg string, lvl Level, t time.Time) error {
+	if sink == nil {
+		return errNilSink
+	}
+	if enc.direct {
+		return enc.Encoder.WriteEntry(sink, name, msg, lvl, t)
+	}
+
+	buf := _framePool.Get().(*bytes.Buffer)
+	defer _framePool.Put(buf)
+	buf.Reset()
+	if err := enc.Encoder.WriteEntry(buf, name, msg, lvl, t); err != nil {
+		return err
+	}
+	if buf.Len() == 0 {
+		// The wrapped encoder chose not to write anything.
+		return nil
+	}
+	framed := appendLengthPrefix(buf.Bytes())
+
+	n, err := writeFully(sink, framed)
+	if err != nil {
+		return err
+	}
+	if n != len(framed) {
+		return &ShortWriteError{Wrote: n, Expected: len(framed)}
+	}
+	return nil
+}
+
+// appendLengthPrefix prefixes a non-empty entry with its length as a 4-byte,
+// big-endian integer, shifting the entry in place.
+func appendLengthPrefix(entry []byte) []byte {
+	n := len(entry)
+	if n == 0 {
+		return entry
+	}
+	entry = append(entry, 0, 0, 0, 0)
+	copy(entry[4:], entry[:n])
+	binary.BigEndian.PutUint32(entry, uint32(n))
+	return entry
+}
+
+func (enc *textEncoder) prefixLengths() {
+	enc.lengthPrefix = true
+}
@@ -0,0 +1,166 @@
+// Copyright (c) 2016 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zap
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/uber-go/zap/spywrite"
+)
+
+func readFrames(t testing.TB, r io.Reader) []string {
+	var frames []string
+	for {
+		var size uint32
+		err := binary.Read(r, binary.BigEndian, &size)
+		if err == io.EOF {
+			return frames
+		}
+		require.NoError(t, err, "Unexpected error reading frame length.")
+		frame := make([]byte, size)
+		_, err = io.ReadFull(r, frame)
+		require.NoError(t, err, "Unexpected error reading frame.")
+		frames = append(frames, string(frame))
+	}
+}
+
+func TestKafkaFrameEncoder(t *testing.T) {
+	enc := NewKafkaFrameEncoder(NewTextEncoder(TextNoTime()))
+	defer enc.Free()
+	enc.AddString("foo", "bar")
+	clone := enc.Clone()
+	defer clone.Free()
+	clone.AddInt("n", 1)
+
+	sink := &bytes.Buffer{}
+	assert.NoError(t, enc.WriteEntry(sink, "", "first", InfoLevel, epoch), "Unexpected failure writing entry.")
+	assert.NoError(t, clone.WriteEntry(sink, "", "second", WarnLevel, epoch), "Unexpected failure writing entry.")
+
+	assert.Equal(t, []string{
+		"[I] first foo=bar\n",
+		"[W] second foo=bar n=1\n",
+	}, readFrames(t, sink), "Unexpected framed entries.")
+}
+
+func TestKafkaFrameEncoderJSON(t *testing.T) {
+	enc := NewKafkaFrameEncoder(NewJSONEncoder(NoTime()))
+	defer enc.Free()
+
+	sink := &bytes.Buffer{}
+	for i := 0; i < 3; i++ {
+		assert.NoError(t, enc.WriteEntry(sink, "", "hello", InfoLevel, epoch), "Unexpected failure writing entry.")
+	}
+	frames := readFrames(t, sink)
+	assert.Len(t, frames, 3, "Expected one frame per entry.")
+	for _, f := range frames {
+		assert.Equal(t, `{"level":"info","msg":"hello"}`+"\n", f, "Unexpected framed JSON entry.")
+	}
+}
+
+func TestKafkaFrameEncoderSkippedEntries(t *testing.T) {
+	enc := NewKafkaFrameEncoder(NewTextEncoder(TextSkipEmptyEntries()))
+	defer enc.Free()
+
+	sink := &bytes.Buffer{}
+	assert.NoError(t, enc.WriteEntry(sink, "", "", InfoLevel, epoch), "Unexpected failure writing entry.")
+	assert.Equal(t, 0, sink.Len(), "Expected no frame for skipped entries.")
+}
+
+func TestKafkaFrameEncoderWriteFailure(t *testing.T) {
+	enc := NewKafkaFrameEncoder(NewTextEncoder())
+	defer enc.Free()
+
+	assert.Equal(t, errNilSink, enc.WriteEntry(nil, "", "hello", InfoLevel, epoch), "Expected an error writing to a nil sink.")
+	assert.Error(t, enc.WriteEntry(spywrite.FailWriter{}, "", "hello", InfoLevel, epoch), "Expected an error when the sink fails.")
+	assert.Error(t, enc.WriteEntry(spywrite.ShortWriter{}, "", "hello", InfoLevel, epoch), "Expected an error on partial writes.")
+}
+
+func TestKafkaFrameEncoderPreamblesPerSink(t *testing.T) {
+	enc := NewKafkaFrameEncoder(NewTextEncoder(TextNoTime(), TextNoName(), TextFormatHeader()))
+	defer enc.Free()
+	clone := enc.Clone()
+	defer clone.Free()
+
+	first, second := &bytes.Buffer{}, &bytes.Buffer{}
+	for _, e := range []Encoder{enc, clone, enc} {
+		require.NoError(t, e.WriteEntry(first, "", "hello", InfoLevel, epoch), "Unexpected failure writing entry.")
+		require.NoError(t, e.WriteEntry(second, "", "hello", InfoLevel, epoch), "Unexpected failure writing entry.")
+	}
+	for _, sink := range []*bytes.Buffer{first, second} {
+		assert.Equal(t, []string{
+			"#zap-format: text v1 fields=level,msg\n[I] hello\n",
+			"[I] hello\n",
+			"[I] hello\n",
+		}, readFrames(t, sink), "Expected the format header exactly once per sink.")
+	}
+}
+
+func TestKafkaFrameEncoderANSI(t *testing.T) {
+	enc := NewKafkaFrameEncoder(NewANSIEncoder(AnsiTextOption(TextNoTime())))
+	defer enc.Free()
+
+	sink := &bytes.Buffer{}
+	require.NoError(t, enc.WriteEntry(sink, "", "hello", InfoLevel, epoch), "Unexpected failure writing entry.")
+	frames := readFrames(t, sink)
+	require.Len(t, frames, 1, "Expected one frame per entry.")
+	assert.Equal(t, "[I] hello\n", stripEscapes(frames[0]), "Unexpected framed ANSI entry.")
+}
+
+func TestKafkaFrameEncoderWriteTimeout(t *testing.T) {
+	defer func() { _timeNow = time.Now }()
+	_timeNow = func() time.Time { return epoch }
+
+	enc := NewKafkaFrameEncoder(NewTextEncoder(TextNoTime(), TextWriteTimeout(time.Second)))
+	defer enc.Free()
+
+	sink := &deadlineRecorder{}
+	require.NoError(t, enc.WriteEntry(sink, "", "hello", InfoLevel, epoch), "Unexpected failure writing entry.")
+	assert.Equal(t, []time.Time{epoch.Add(time.Second)}, sink.deadlines, "Expected a write deadline on the real sink.")
+	assert.Equal(t, []string{"[I] hello\n"}, readFrames(t, &sink.testBuffer), "Unexpected framed entries.")
+}
+
+func TestKafkaFrameEncoderFatalHook(t *testing.T) {
+	sink := &bytes.Buffer{}
+	var written []string
+	enc := NewKafkaFrameEncoder(NewTextEncoder(TextNoTime(), FatalHook(func() {
+		written = readFrames(t, bytes.NewReader(sink.Bytes()))
+	})))
+	defer enc.Free()
+
+	require.NoError(t, enc.WriteEntry(sink, "", "fatal", FatalLevel, epoch), "Unexpected failure writing entry.")
+	assert.Equal(t, []string{"[F] fatal\n"}, written, "Expected the fatal hook to fire after the frame was written.")
+}
+
+func TestKafkaFrameEncoderRetriesShortWrites(t *testing.T) {
+	for _, inner := range []Encoder{NewTextEncoder(TextNoTime()), NewJSONEncoder(NoTime())} {
+		enc := NewKafkaFrameEncoder(inner)
+		sink := &trickleWriter{}
+		require.NoError(t, enc.WriteEntry(sink, "", "hello", InfoLevel, epoch), "Unexpected error writing to a slow sink.")
+		assert.Len(t, readFrames(t, &sink.testBuffer), 1, "Expected the full frame to reach a sink that writes one byte at a time.")
+		enc.Free()
+	}
+}
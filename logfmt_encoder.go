@@ -0,0 +1,361 @@
+// Copyright (c) 2016 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zap
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"sync"
+	"time"
+	"unicode"
+	"unicode/utf8"
+	"unsafe"
+)
+
+var logfmtPool = sync.Pool{New: func() interface{} {
+	return &logfmtEncoder{
+		bytes: make([]byte, 0, _initialBufSize),
+	}
+}}
+
+type logfmtEncoder struct {
+	bytes []byte
+	// Prefix for keys added by nested LogMarshalers.
+	prefix string
+}
+
+// NewLogfmtEncoder creates an encoder that renders each entry as a strict
+// logfmt line (e.g., `level=info ts=... msg="hello world" user=jane`), which
+// any logfmt parser can read back. Unlike the text encoder, every value has a
+// key: the level, timestamp, logger name, and message are written first, in
+// that order, under the reserved keys "level", "ts", "logger", and "msg", and
+// fields follow in the order they were added.
+//
+// Values are written as bare tokens when that's unambiguous. Empty values and
+// values containing spaces, equals signs, double quotes, or non-printable
+// characters are double-quoted, with quotes, backslashes, and control
+// characters escaped as in JSON strings. Since logfmt keys can't be quoted,
+// characters that aren't allowed in a key are replaced with underscores.
+// Nested objects and lists are flattened into dotted keys (e.g.,
+// "user.name=jane" and "tags.0=a"), and byte slices are hex-encoded.
+func NewLogfmtEncoder() Encoder {
+	enc := logfmtPool.Get().(*logfmtEncoder)
+	enc.truncate()
+	return enc
+}
+
+func (enc *logfmtEncoder) Free() {
+	logfmtPool.Put(enc)
+}
+
+func (enc *logfmtEncoder) AddString(key, val string) {
+	enc.addKey(key)
+	enc.bytes = appendLogfmtValue(enc.bytes, val)
+}
+
+func (enc *logfmtEncoder) AddBool(key string, val bool) {
+	enc.addKey(key)
+	enc.bytes = strconv.AppendBool(enc.bytes, val)
+}
+
+func (enc *logfmtEncoder) AddByte(key string, val byte) {
+	enc.AddUint64(key, uint64(val))
+}
+
+// AddBytes hex-encodes the slice without a "0x" prefix, so the value is
+// always a bare token.
+func (enc *logfmtEncoder) AddBytes(key string, val []byte) {
+	enc.addKey(key)
+	for _, v := range val {
+		enc.bytes = append(enc.bytes, hextable[v>>4], hextable[v&0x0F])
+	}
+}
+
+func (enc *logfmtEncoder) AddInt(key string, val int) {
+	enc.AddInt64(key, int64(val))
+}
+
+func (enc *logfmtEncoder) AddInt64(key string, val int64) {
+	enc.addKey(key)
+	enc.bytes = strconv.AppendInt(enc.bytes, val, 10)
+}
+
+func (enc *logfmtEncoder) AddUint(key string, val uint) {
+	enc.AddUint64(key, uint64(val))
+}
+
+func (enc *logfmtEncoder) AddUint64(key string, val uint64) {
+	enc.addKey(key)
+	enc.bytes = strconv.AppendUint(enc.bytes, val, 10)
+}
+
+func (enc *logfmtEncoder) AddInt32(key string, val int32) {
+	enc.AddInt64(key, int64(val))
+}
+
+func (enc *logfmtEncoder) AddInt16(key string, val int16) {
+	enc.AddInt64(key, int64(val))
+}
+
+func (enc *logfmtEncoder) AddInt8(key string, val int8) {
+	enc.AddInt64(key, int64(val))
+}
+
+func (enc *logfmtEncoder) AddUint32(key string, val uint32) {
+	enc.AddUint64(key, uint64(val))
+}
+
+func (enc *logfmtEncoder) AddUint16(key string, val uint16) {
+	enc.AddUint64(key, uint64(val))
+}
+
+func (enc *logfmtEncoder) AddUint8(key string, val uint8) {
+	enc.AddUint64(key, uint64(val))
+}
+
+func (enc *logfmtEncoder) AddFloat32(key string, val float32) {
+	enc.addFloat(key, float64(val), 32)
+}
+
+func (enc *logfmtEncoder) AddFloat64(key string, val float64) {
+	enc.addFloat(key, val, 64)
+}
+
+func (enc *logfmtEncoder) addFloat(key string, val float64, bitSize int) {
+	enc.addKey(key)
+	switch {
+	case math.IsNaN(val):
+		enc.bytes = append(enc.bytes, "NaN"...)
+	case math.IsInf(val, 1):
+		enc.bytes = append(enc.bytes, "+Inf"...)
+	case math.IsInf(val, -1):
+		enc.bytes = append(enc.bytes, "-Inf"...)
+	default:
+		enc.bytes = strconv.AppendFloat(enc.bytes, val, 'f', -1, bitSize)
+	}
+}
+
+func (enc *logfmtEncoder) AddDuration(key string, val time.Duration) {
+	enc.addKey(key)
+	enc.bytes = appendDuration(enc.bytes, val)
+}
+
+func (enc *logfmtEncoder) AddTime(key string, val time.Time) {
+	enc.addKey(key)
+	enc.bytes = val.AppendFormat(enc.bytes, time.RFC3339Nano)
+}
+
+func (enc *logfmtEncoder) AddComplex128(key string, val complex128) {
+	enc.addKey(key)
+	enc.bytes = appendComplex(enc.bytes, val, 64)
+}
+
+func (enc *logfmtEncoder) AddComplex64(key string, val complex64) {
+	enc.addKey(key)
+	enc.bytes = appendComplex(enc.bytes, complex128(val), 32)
+}
+
+// AddError adds an error's message, along with the messages of any errors it
+// wraps. A nil error is written as an empty value.
+func (enc *logfmtEncoder) AddError(key string, err error) {
+	if err == nil {
+		enc.AddString(key, "")
+		return
+	}
+	enc.AddString(key, errorChain(err))
+}
+
+// AddStrings flattens the slice into keys suffixed with each value's index
+// (e.g., "tags.0=a tags.1=b"). Nothing is written for an empty slice.
+func (enc *logfmtEncoder) AddStrings(key string, vals []string) {
+	for i, v := range vals {
+		enc.AddString(key+"."+strconv.Itoa(i), v)
+	}
+}
+
+// AddInts flattens the slice into indexed keys, like AddStrings.
+func (enc *logfmtEncoder) AddInts(key string, vals []int) {
+	for i, v := range vals {
+		enc.AddInt64(key+"."+strconv.Itoa(i), int64(v))
+	}
+}
+
+func (enc *logfmtEncoder) AddRune(key string, val rune) {
+	enc.AddString(key, string(val))
+}
+
+func (enc *logfmtEncoder) AddBase64Bytes(key string, val []byte) {
+	enc.AddString(key, string(appendBase64(nil, val)))
+}
+
+// AddStackFrames flattens each frame's fields into keys prefixed with the
+// supplied key and the frame's index (e.g., "stack.0.file=main.go").
+func (enc *logfmtEncoder) AddStackFrames(key string) {
+	for i, f := range takeStackFrames() {
+		enc.AddMarshaler(key+"."+strconv.Itoa(i), f)
+	}
+}
+
+func (enc *logfmtEncoder) AddUintptr(key string, val uintptr) {
+	enc.addKey(key)
+	enc.bytes = appendAddress(enc.bytes, val)
+}
+
+func (enc *logfmtEncoder) AddPointer(key string, val unsafe.Pointer) {
+	enc.AddUintptr(key, uintptr(val))
+}
+
+// AddMarshaler flattens the object's fields into keys prefixed with the
+// supplied key and a dot.
+func (enc *logfmtEncoder) AddMarshaler(key string, obj LogMarshaler) error {
+	prefix := enc.prefix
+	enc.prefix = prefix + key + "."
+	err := obj.MarshalLog(enc)
+	enc.prefix = prefix
+	return err
+}
+
+func (enc *logfmtEncoder) AddObject(key string, obj interface{}) error {
+	if ok, err := addFormattedObject(enc, key, obj); ok {
+		return err
+	}
+	enc.AddString(key, fmt.Sprintf("%+v", obj))
+	return nil
+}
+
+func (enc *logfmtEncoder) Clone() Encoder {
+	clone := logfmtPool.Get().(*logfmtEncoder)
+	clone.truncate()
+	clone.bytes = append(clone.bytes, enc.bytes...)
+	return clone
+}
+
+func (enc *logfmtEncoder) WriteEntry(sink io.Writer, name string, msg string, lvl Level, t time.Time) error {
+	if sink == nil {
+		return errNilSink
+	}
+
+	final := logfmtPool.Get().(*logfmtEncoder)
+	final.truncate()
+	final.AddString("level", lvl.String())
+	final.AddTime("ts", t)
+	if name != "" {
+		final.AddString("logger", name)
+	}
+	final.AddString("msg", msg)
+	if len(enc.bytes) > 0 {
+		final.bytes = append(final.bytes, ' ')
+		final.bytes = append(final.bytes, enc.bytes...)
+	}
+	final.bytes = append(final.bytes, '\n')
+
+	expectedBytes := len(final.bytes)
+	n, err := sink.Write(final.bytes)
+	final.Free()
+	if err != nil {
+		return err
+	}
+	if n != expectedBytes {
+		return &ShortWriteError{Wrote: n, Expected: expectedBytes}
+	}
+	return nil
+}
+
+func (enc *logfmtEncoder) truncate() {
+	enc.bytes = enc.bytes[:0]
+	enc.prefix = ""
+}
+
+func (enc *logfmtEncoder) addKey(key string) {
+	if len(enc.bytes) > 0 {
+		enc.bytes = append(enc.bytes, ' ')
+	}
+	if enc.prefix == "" && key == "" {
+		enc.bytes = append(enc.bytes, '_')
+	} else {
+		enc.bytes = appendLogfmtKey(enc.bytes, enc.prefix)
+		enc.bytes = appendLogfmtKey(enc.bytes, key)
+	}
+	enc.bytes = append(enc.bytes, '=')
+}
+
+// isLogfmtBare reports whether r may appear in an unquoted logfmt key or
+// value.
+func isLogfmtBare(r rune) bool {
+	return r > ' ' && r != '=' && r != '"' && r != utf8.RuneError && unicode.IsPrint(r)
+}
+
+// appendLogfmtKey appends s, replacing any characters that logfmt doesn't
+// allow in keys with underscores.
+func appendLogfmtKey(buf []byte, s string) []byte {
+	for i := 0; i < len(s); {
+		r, size := utf8.DecodeRuneInString(s[i:])
+		if isLogfmtBare(r) {
+			buf = append(buf, s[i:i+size]...)
+		} else {
+			buf = append(buf, '_')
+		}
+		i += size
+	}
+	return buf
+}
+
+// appendLogfmtValue appends s as a bare token if that's unambiguous, and as a
+// double-quoted string with JSON-style escapes otherwise. Invalid UTF-8 is
+// replaced with the Unicode replacement character.
+func appendLogfmtValue(buf []byte, s string) []byte {
+	bare := s != ""
+	for _, r := range s {
+		if !isLogfmtBare(r) {
+			bare = false
+			break
+		}
+	}
+	if bare {
+		return append(buf, s...)
+	}
+
+	buf = append(buf, '"')
+	for i := 0; i < len(s); {
+		r, size := utf8.DecodeRuneInString(s[i:])
+		switch {
+		case r == '"' || r == '\\':
+			buf = append(buf, '\\', byte(r))
+		case r == '\n':
+			buf = append(buf, `\n`...)
+		case r == '\r':
+			buf = append(buf, `\r`...)
+		case r == '\t':
+			buf = append(buf, `\t`...)
+		case r < ' ' || r == 0x7F:
+			buf = append(buf, `\u00`...)
+			buf = append(buf, hextable[r>>4], hextable[r&0x0F])
+		case r == utf8.RuneError && size == 1:
+			buf = append(buf, "\ufffd"...)
+		default:
+			buf = append(buf, s[i:i+size]...)
+		}
+		i += size
+	}
+	return append(buf, '"')
+}
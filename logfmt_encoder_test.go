@@ -0,0 +1,374 @@
+// Copyright (c) 2016 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zap
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/uber-go/zap/spywrite"
+)
+
+type logfmtPair struct{ key, val string }
+
+// decodeLogfmt is a strict decoder for the canonical logfmt grammar: a
+// space-separated list of bare keys, each optionally followed by an equals sign
+// and either a bare value or a double-quoted, JSON-escaped value.
+func decodeLogfmt(line string) ([]logfmtPair, error) {
+	var pairs []logfmtPair
+	i := 0
+	for {
+		for i < len(line) && line[i] <= ' ' {
+			i++
+		}
+		if i == len(line) {
+			return pairs, nil
+		}
+		start := i
+		for i < len(line) && line[i] > ' ' && line[i] != '=' {
+			if line[i] == '"' {
+				return nil, fmt.Errorf("unexpected quote in key at offset %d", i)
+			}
+			i++
+		}
+		if i == start {
+			return nil, fmt.Errorf("empty key at offset %d", i)
+		}
+		pair := logfmtPair{key: line[start:i]}
+		if i < len(line) && line[i] == '=' {
+			i++
+			var err error
+			if i < len(line) && line[i] == '"' {
+				pair.val, i, err = decodeLogfmtQuoted(line, i+1)
+			} else {
+				pair.val, i, err = decodeLogfmtBare(line, i)
+			}
+			if err != nil {
+				return nil, err
+			}
+		}
+		pairs = append(pairs, pair)
+	}
+}
+
+func decodeLogfmtBare(line string, i int) (string, int, error) {
+	start := i
+	for i < len(line) && line[i] > ' ' {
+		if line[i] == '"' || line[i] == '=' {
+			return "", i, fmt.Errorf("unexpected %q in bare value at offset %d", line[i], i)
+		}
+		i++
+	}
+	return line[start:i], i, nil
+}
+
+func decodeLogfmtQuoted(line string, i int) (string, int, error) {
+	var buf []byte
+	for i < len(line) {
+		c := line[i]
+		switch {
+		case c == '"':
+			if i+1 < len(line) && line[i+1] > ' ' {
+				return "", i, fmt.Errorf("unexpected %q after quoted value at offset %d", line[i+1], i+1)
+			}
+			return string(buf), i + 1, nil
+		case c < ' ':
+			return "", i, fmt.Errorf("unescaped control character at offset %d", i)
+		case c != '\\':
+			buf = append(buf, c)
+			i++
+			continue
+		}
+		if i+1 == len(line) {
+			break
+		}
+		switch line[i+1] {
+		case '"', '\\', '/':
+			buf = append(buf, line[i+1])
+		case 'b':
+			buf = append(buf, '\b')
+		case 'f':
+			buf = append(buf, '\f')
+		case 'n':
+			buf = append(buf, '\n')
+		case 'r':
+			buf = append(buf, '\r')
+		case 't':
+			buf = append(buf, '\t')
+		case 'u':
+			if i+6 > len(line) {
+				return "", i, fmt.Errorf("short unicode escape at offset %d", i)
+			}
+			r, err := strconv.ParseUint(line[i+2:i+6], 16, 16)
+			if err != nil {
+				return "", i, err
+			}
+			buf = append(buf, string(rune(r))...)
+			i += 4
+		default:
+			return "", i, fmt.Errorf("invalid escape %q at offset %d", line[i+1], i)
+		}
+		i += 2
+	}
+	return "", i, errors.New("unterminated quoted value")
+}
+
+func decodeLogfmtEntry(t testing.TB, enc Encoder, name, msg string) []logfmtPair {
+	sink := &testBuffer{}
+	require.NoError(t, enc.WriteEntry(sink, name, msg, InfoLevel, epoch), "Unexpected failure writing entry.")
+	lines := sink.Lines()
+	require.Len(t, lines, 1, "Expected exactly one line per entry.")
+	pairs, err := decodeLogfmt(lines[0])
+	require.NoError(t, err, "Expected output to be valid logfmt: %q.", lines[0])
+	return pairs
+}
+
+func TestLogfmtEncoderRoundTrip(t *testing.T) {
+	enc := NewLogfmtEncoder()
+	defer enc.Free()
+	enc.AddString("bare", "hello")
+	enc.AddString("spaces", "hello world")
+	enc.AddString("empty", "")
+	enc.AddString("quotes", `say "hi"`)
+	enc.AddString("equals", "a=b")
+	enc.AddString("backslash", `C:\Users`)
+	enc.AddString("control", "line1\nline2\ttab\r\x00\x7f")
+	enc.AddString("unicode", "héllo✓日本")
+	enc.AddString("nbsp", "a\u00a0b")
+	enc.AddString("invalid", "a\xffb")
+	enc.AddString("key with\"=", "v")
+	enc.AddBool("true", true)
+	enc.AddBool("false", false)
+	enc.AddInt("int", -42)
+	enc.AddUint64("uint", math.MaxUint64)
+	enc.AddFloat64("float", 1.5)
+	enc.AddFloat64("inf", math.Inf(1))
+	enc.AddByte("byte", 0xff)
+	enc.AddBytes("bytes", []byte{0xde, 0xad})
+	enc.AddDuration("dur", 1500*time.Millisecond)
+	enc.AddStrings("tags", []string{"a", "b c"})
+	enc.AddError("err", errors.New("failed: bad input"))
+	assert.NoError(t, enc.AddMarshaler("obj", loggable{true}), "Unexpected error adding a marshaler.")
+
+	pairs := decodeLogfmtEntry(t, enc, "my logger", `what "why" = 100%`)
+	assert.Equal(t, []logfmtPair{
+		{"level", "info"},
+		{"ts", "1970-01-01T00:00:00Z"},
+		{"logger", "my logger"},
+		{"msg", `what "why" = 100%`},
+		{"bare", "hello"},
+		{"spaces", "hello world"},
+		{"empty", ""},
+		{"quotes", `say "hi"`},
+		{"equals", "a=b"},
+		{"backslash", `C:\Users`},
+		{"control", "line1\nline2\ttab\r\x00\x7f"},
+		{"unicode", "héllo✓日本"},
+		{"nbsp", "a\u00a0b"},
+		{"invalid", "a\ufffdb"},
+		{"key_with__", "v"},
+		{"true", "true"},
+		{"false", "false"},
+		{"int", "-42"},
+		{"uint", "18446744073709551615"},
+		{"float", "1.5"},
+		{"inf", "+Inf"},
+		{"byte", "255"},
+		{"bytes", "DEAD"},
+		{"dur", "1.5s"},
+		{"tags.0", "a"},
+		{"tags.1", "b c"},
+		{"err", "failed: bad input"},
+		{"obj.loggable", "yes"},
+	}, pairs, "Unexpected key-value pairs after decoding the entry.")
+}
+
+func TestLogfmtEncoderQuoting(t *testing.T) {
+	tests := []struct {
+		val      string
+		expected string
+	}{
+		{"hello", `k=hello`},
+		{"", `k=""`},
+		{"a b", `k="a b"`},
+		{"a=b", `k="a=b"`},
+		{`a"b`, `k="a\"b"`},
+		{`a\b`, `k=a\b`},
+		{"a\\b c", `k="a\\b c"`},
+		{"a\nb", `k="a\nb"`},
+		{"\x01", `k="\u0001"`},
+		{"✓", `k=✓`},
+		{"\xff", `k="` + string(utf8.RuneError) + `"`},
+	}
+
+	for _, tt := range tests {
+		enc := NewLogfmtEncoder().(*logfmtEncoder)
+		enc.AddString("k", tt.val)
+		assert.Equal(t, tt.expected, string(enc.bytes), "Unexpected encoding for %q.", tt.val)
+		enc.Free()
+	}
+}
+
+func TestLogfmtEncoderKeys(t *testing.T) {
+	enc := NewLogfmtEncoder().(*logfmtEncoder)
+	defer enc.Free()
+	enc.AddString("", "empty")
+	enc.AddString("a b=c\"d\xff", "v")
+	assert.NoError(t, enc.AddMarshaler("my obj", loggable{true}), "Unexpected error adding a marshaler.")
+	assert.Equal(t, `_=empty a_b_c_d_=v my_obj.loggable=yes`, string(enc.bytes), "Expected invalid key characters to be replaced.")
+}
+
+func TestLogfmtEncoderClone(t *testing.T) {
+	parent := NewLogfmtEncoder()
+	defer parent.Free()
+	parent.AddString("foo", "bar")
+	clone := parent.Clone()
+	defer clone.Free()
+	clone.AddString("baz", "bing")
+
+	assert.Equal(t, []logfmtPair{
+		{"level", "info"},
+		{"ts", "1970-01-01T00:00:00Z"},
+		{"msg", ""},
+		{"foo", "bar"},
+		{"baz", "bing"},
+	}, decodeLogfmtEntry(t, clone, "", ""), "Expected clones to inherit fields and omit empty logger names.")
+	assert.Equal(t, []logfmtPair{
+		{"level", "info"},
+		{"ts", "1970-01-01T00:00:00Z"},
+		{"msg", "hello"},
+		{"foo", "bar"},
+	}, decodeLogfmtEntry(t, parent, "", "hello"), "Adding to a clone shouldn't affect the parent.")
+}
+
+func TestLogfmtEncoderWriteFailure(t *testing.T) {
+	enc := NewLogfmtEncoder()
+	defer enc.Free()
+	assert.Equal(t, errNilSink, enc.WriteEntry(nil, "", "hello", InfoLevel, epoch), "Expected an error writing to a nil sink.")
+	assert.Error(t, enc.WriteEntry(spywrite.FailWriter{}, "", "hello", InfoLevel, epoch), "Expected an error when the sink fails.")
+	assert.Error(t, enc.WriteEntry(spywrite.ShortWriter{}, "", "hello", InfoLevel, epoch), "Expected an error on partial writes.")
+}
+
+// TestLogfmtEncoderGoldenLines checks the encoder against lines produced by
+// the reference implementation, github.com/go-logfmt/logfmt, so that the
+// round-trip tests don't rely only on decodeLogfmt.
+func TestLogfmtEncoderGoldenLines(t *testing.T) {
+	tests := []struct {
+		desc     string
+		add      func(Encoder)
+		expected string
+	}{
+		{"bare", func(e Encoder) { e.AddString("k", "v") }, `k=v`},
+		{"placeholder", func(e Encoder) { e.AddString("k", "<nil>") }, `k=<nil>`},
+		{"bool", func(e Encoder) { e.AddBool("k", true) }, `k=true`},
+		{"int", func(e Encoder) { e.AddInt("k", 1) }, `k=1`},
+		{"float", func(e Encoder) { e.AddFloat64("k", 1.025) }, `k=1.025`},
+		{"small float", func(e Encoder) { e.AddFloat64("k", 1e-3) }, `k=0.001`},
+		{"space", func(e Encoder) { e.AddString("k", "v v") }, `k="v v"`},
+		{"only space", func(e Encoder) { e.AddString("k", " ") }, `k=" "`},
+		{"quote", func(e Encoder) { e.AddString("k", `"`) }, `k="\""`},
+		{"equals", func(e Encoder) { e.AddString("k", "=") }, `k="="`},
+		{"backslash", func(e Encoder) { e.AddString("k", `\`) }, `k=\`},
+		{"equals and backslash", func(e Encoder) { e.AddString("k", `=\`) }, `k="=\\"`},
+		{"backslash and quote", func(e Encoder) { e.AddString("k", `\"`) }, `k="\\\""`},
+		{"tab", func(e Encoder) { e.AddString("k", "a\tb") }, `k="a\tb"`},
+		{"newline", func(e Encoder) { e.AddString("k", "a\nb") }, `k="a\nb"`},
+		{"multiple", func(e Encoder) {
+			e.AddString("a", "1")
+			e.AddString("b", "bar")
+			e.AddString("ƒ", "2h3s")
+			e.AddString("r", "esc\t")
+			e.AddString("x", "sf")
+		}, `a=1 b=bar ƒ=2h3s r="esc\t" x=sf`},
+	}
+
+	for _, tt := range tests {
+		enc := NewLogfmtEncoder().(*logfmtEncoder)
+		tt.add(enc)
+		assert.Equal(t, tt.expected, string(enc.bytes), "Unexpected encoding (%s).", tt.desc)
+		enc.Free()
+	}
+}
+
+// TestDecodeLogfmtGoldenLines checks decodeLogfmt against lines and decoded
+// pairs from the reference implementation's tests and from Brandur Leach's
+// description of the format.
+func TestDecodeLogfmtGoldenLines(t *testing.T) {
+	tests := []struct {
+		line     string
+		expected []logfmtPair
+	}{
+		{`a=1 b="bar" ƒ=2h3s r="esc\t" d x=sf`, []logfmtPair{
+			{"a", "1"}, {"b", "bar"}, {"ƒ", "2h3s"}, {"r", "esc\t"}, {"d", ""}, {"x", "sf"},
+		}},
+		{`x= `, []logfmtPair{{"x", ""}}},
+		{`y=`, []logfmtPair{{"y", ""}}},
+		{`y`, []logfmtPair{{"y", ""}}},
+		{`y=f`, []logfmtPair{{"y", "f"}}},
+		{`y="\tf"`, []logfmtPair{{"y", "\tf"}}},
+		{`y="\\x"`, []logfmtPair{{"y", `\x`}}},
+		{`y="\u263a"`, []logfmtPair{{"y", "☺"}}},
+		{`at=info method=GET path=/ host=mutelight.org fwd="124.133.52.161" dyno=web.2 connect=4ms service=8ms status=200 bytes=1653`, []logfmtPair{
+			{"at", "info"},
+			{"method", "GET"},
+			{"path", "/"},
+			{"host", "mutelight.org"},
+			{"fwd", "124.133.52.161"},
+			{"dyno", "web.2"},
+			{"connect", "4ms"},
+			{"service", "8ms"},
+			{"status", "200"},
+			{"bytes", "1653"},
+		}},
+	}
+
+	for _, tt := range tests {
+		pairs, err := decodeLogfmt(tt.line)
+		require.NoError(t, err, "Unexpected error decoding %q.", tt.line)
+		assert.Equal(t, tt.expected, pairs, "Unexpected pairs decoded from %q.", tt.line)
+	}
+}
+
+func TestDecodeLogfmtRejectsMalformedLines(t *testing.T) {
+	for _, line := range []string{
+		`k="unterminated`,
+		`k=a"b`,
+		`k=a=b`,
+		`"k"=v`,
+		`=v`,
+		`k="a"b`,
+		`k="\x"`,
+	} {
+		_, err := decodeLogfmt(line)
+		assert.Error(t, err, "Expected an error decoding %q.", line)
+	}
+	pairs, err := decodeLogfmt(strings.Join([]string{`a=1`, `b`, `c=""`}, "  "))
+	require.NoError(t, err, "Unexpected error decoding a valid line.")
+	assert.Equal(t, []logfmtPair{{"a", "1"}, {"b", ""}, {"c", ""}}, pairs, "Unexpected pairs decoded.")
+}
@@ -0,0 +1,266 @@
+// Copyright (c) 2016 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zap
+
+import (
+	"io"
+	"time"
+	"unsafe"
+)
+
+type multiEncoder []Encoder
+
+// NewMultiEncoder creates an encoder that fans out to several encoders, which
+// lets a single logger write each entry in several formats (e.g., JSON to a
+// file and ANSI to the console). Fields are added to every encoder, and
+// WriteEntry calls each encoder's WriteEntry in order.
+//
+// Every encoder is passed the same sink. To write to a different sink for each
+// encoder, wrap the encoders with NewRoutingEncoder, which ignores the sink it's
+// passed in favor of the one its route function returns:
+//
+//	enc := NewMultiEncoder(
+//		NewRoutingEncoder(NewJSONEncoder(), func(Level, string, string) io.Writer { return file }),
+//		NewRoutingEncoder(NewANSIEncoder(), func(Level, string, string) io.Writer { return os.Stderr }),
+//	)
+//
+// Errors are returned from the first encoder that fails, but the remaining
+// encoders are still called, so a failing sink doesn't stop the others from
+// receiving entries.
+func NewMultiEncoder(encs ...Encoder) Encoder {
+	return multiEncoder(append([]Encoder(nil), encs...))
+}
+
+func (m multiEncoder) AddString(key, val string) {
+	for _, enc := range m {
+		enc.AddString(key, val)
+	}
+}
+
+func (m multiEncoder) AddBool(key string, val bool) {
+	for _, enc := range m {
+		enc.AddBool(key, val)
+	}
+}
+
+func (m multiEncoder) AddByte(key string, val byte) {
+	for _, enc := range m {
+		enc.AddByte(key, val)
+	}
+}
+
+func (m multiEncoder) AddBytes(key string, val []byte) {
+	for _, enc := range m {
+		enc.AddBytes(key, val)
+	}
+}
+
+func (m multiEncoder) AddInt(key string, val int) {
+	for _, enc := range m {
+		enc.AddInt(key, val)
+	}
+}
+
+func (m multiEncoder) AddInt64(key string, val int64) {
+	for _, enc := range m {
+		enc.AddInt64(key, val)
+	}
+}
+
+func (m multiEncoder) AddUint(key string, val uint) {
+	for _, enc := range m {
+		enc.AddUint(key, val)
+	}
+}
+
+func (m multiEncoder) AddUint64(key string, val uint64) {
+	for _, enc := range m {
+		enc.AddUint64(key, val)
+	}
+}
+
+func (m multiEncoder) AddFloat32(key string, val float32) {
+	for _, enc := range m {
+		enc.AddFloat32(key, val)
+	}
+}
+
+func (m multiEncoder) AddFloat64(key string, val float64) {
+	for _, enc := range m {
+		enc.AddFloat64(key, val)
+	}
+}
+
+func (m multiEncoder) AddDuration(key string, val time.Duration) {
+	for _, enc := range m {
+		enc.AddDuration(key, val)
+	}
+}
+
+func (m multiEncoder) AddTime(key string, val time.Time) {
+	for _, enc := range m {
+		enc.AddTime(key, val)
+	}
+}
+
+func (m multiEncoder) AddError(key string, err error) {
+	for _, enc := range m {
+		enc.AddError(key, err)
+	}
+}
+
+func (m multiEncoder) AddComplex128(key string, val complex128) {
+	for _, enc := range m {
+		enc.AddComplex128(key, val)
+	}
+}
+
+func (m multiEncoder) AddComplex64(key string, val complex64) {
+	for _, enc := range m {
+		enc.AddComplex64(key, val)
+	}
+}
+
+func (m multiEncoder) AddInt32(key string, val int32) {
+	for _, enc := range m {
+		enc.AddInt32(key, val)
+	}
+}
+
+func (m multiEncoder) AddInt16(key string, val int16) {
+	for _, enc := range m {
+		enc.AddInt16(key, val)
+	}
+}
+
+func (m multiEncoder) AddInt8(key string, val int8) {
+	for _, enc := range m {
+		enc.AddInt8(key, val)
+	}
+}
+
+func (m multiEncoder) AddUint32(key string, val uint32) {
+	for _, enc := range m {
+		enc.AddUint32(key, val)
+	}
+}
+
+func (m multiEncoder) AddUint16(key string, val uint16) {
+	for _, enc := range m {
+		enc.AddUint16(key, val)
+	}
+}
+
+func (m multiEncoder) AddUint8(key string, val uint8) {
+	for _, enc := range m {
+		enc.AddUint8(key, val)
+	}
+}
+
+func (m multiEncoder) AddStrings(key string, vals []string) {
+	for _, enc := range m {
+		enc.AddStrings(key, vals)
+	}
+}
+
+func (m multiEncoder) AddInts(key string, vals []int) {
+	for _, enc := range m {
+		enc.AddInts(key, vals)
+	}
+}
+
+func (m multiEncoder) AddRune(key string, val rune) {
+	for _, enc := range m {
+		enc.AddRune(key, val)
+	}
+}
+
+func (m multiEncoder) AddBase64Bytes(key string, val []byte) {
+	for _, enc := range m {
+		enc.AddBase64Bytes(key, val)
+	}
+}
+
+func (m multiEncoder) AddStackFrames(key string) {
+	for _, enc := range m {
+		enc.AddStackFrames(key)
+	}
+}
+
+func (m multiEncoder) AddUintptr(key string, val uintptr) {
+	for _, enc := range m {
+		enc.AddUintptr(key, val)
+	}
+}
+
+func (m multiEncoder) AddPointer(key string, val unsafe.Pointer) {
+	for _, enc := range m {
+		enc.AddPointer(key, val)
+	}
+}
+
+// AddMarshaler adds the object to each encoder, so its MarshalLog method is
+// called once per encoder.
+func (m multiEncoder) AddMarshaler(key string, obj LogMarshaler) error {
+	var first error
+	for _, enc := range m {
+		if err := enc.AddMarshaler(key, obj); err != nil && first == nil {
+			first = err
+		}
+	}
+	return first
+}
+
+func (m multiEncoder) AddObject(key string, obj interface{}) error {
+	var first error
+	for _, enc := range m {
+		if err := enc.AddObject(key, obj); err != nil && first == nil {
+			first = err
+		}
+	}
+	return first
+}
+
+// Clone clones each encoder.
+func (m multiEncoder) Clone() Encoder {
+	clone := make(multiEncoder, len(m))
+	for i, enc := range m {
+		clone[i] = enc.Clone()
+	}
+	return clone
+}
+
+// Free frees each encoder.
+func (m multiEncoder) Free() {
+	for _, enc := range m {
+		enc.Free()
+	}
+}
+
+func (m multiEncoder) WriteEntry(sink io.Writer, name string, msg string, lvl Level, t time.Time) error {
+	var first error
+	for _, enc := range m {
+		if err := enc.WriteEntry(sink, name, msg, lvl, t); err != nil && first == nil {
+			first = err
+		}
+	}
+	return first
+}
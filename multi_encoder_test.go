@@ -0,0 +1,154 @@
+// Copyright (c) 2016 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zap
+
+import (
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/uber-go/zap/spywrite"
+)
+
+// addEveryType adds a field of every type supported by the Encoder interface.
+func addEveryType(enc Encoder) {
+	enc.AddString("string", "s")
+	enc.AddBool("bool", true)
+	enc.AddByte("byte", 0x2a)
+	enc.AddBytes("bytes", []byte{1, 2})
+	enc.AddInt("int", -1)
+	enc.AddInt64("int64", -2)
+	enc.AddUint("uint", 3)
+	enc.AddUint64("uint64", 4)
+	enc.AddFloat32("float32", 1.5)
+	enc.AddFloat64("float64", 2.5)
+	enc.AddDuration("duration", time.Second)
+	enc.AddTime("time", epoch)
+	enc.AddError("error", errors.New("fail"))
+	enc.AddComplex128("complex128", complex(1, 2))
+	enc.AddComplex64("complex64", complex(3, 4))
+	enc.AddInt32("int32", -5)
+	enc.AddInt16("int16", -6)
+	enc.AddInt8("int8", -7)
+	enc.AddUint32("uint32", 5)
+	enc.AddUint16("uint16", 6)
+	enc.AddUint8("uint8", 7)
+	enc.AddStrings("strings", []string{"a", "b"})
+	enc.AddInts("ints", []int{1, 2})
+	enc.AddRune("rune", 'r')
+	enc.AddBase64Bytes("base64", []byte{0xde, 0xad})
+	enc.AddUintptr("uintptr", 0xbeef)
+	enc.AddPointer("pointer", nil)
+	enc.AddMarshaler("marshaler", loggable{true})
+	enc.AddObject("object", map[string]int{"n": 1})
+}
+
+func TestMultiEncoderMatchesChildren(t *testing.T) {
+	newChildren := func() []Encoder {
+		return []Encoder{NewJSONEncoder(NoTime()), NewTextEncoder(TextNoTime()), NewYAMLFlowEncoder()}
+	}
+
+	expected := &testBuffer{}
+	for _, enc := range newChildren() {
+		addEveryType(enc)
+		clone := enc.Clone()
+		clone.AddString("request", "r1")
+		require.NoError(t, clone.WriteEntry(expected, "name", "hello", InfoLevel, epoch), "Unexpected failure writing entry.")
+		clone.Free()
+		enc.Free()
+	}
+
+	multi := NewMultiEncoder(newChildren()...)
+	defer multi.Free()
+	addEveryType(multi)
+	clone := multi.Clone()
+	clone.AddString("request", "r1")
+	actual := &testBuffer{}
+	require.NoError(t, clone.WriteEntry(actual, "name", "hello", InfoLevel, epoch), "Unexpected failure writing entry.")
+	clone.Free()
+
+	assert.Equal(t, expected.Lines(), actual.Lines(), "Expected each child to render the same fields as it would alone.")
+	assert.Len(t, actual.Lines(), 3, "Expected one line per child.")
+}
+
+func TestMultiEncoderClone(t *testing.T) {
+	json, text := NewJSONEncoder(NoTime()), NewTextEncoder(TextNoTime())
+	multi := NewMultiEncoder(json, text)
+	defer multi.Free()
+	multi.AddString("shared", "yes")
+
+	clone := multi.Clone()
+	defer clone.Free()
+	clone.AddInt("cloned", 1)
+
+	sink := &testBuffer{}
+	require.NoError(t, multi.WriteEntry(sink, "", "parent", InfoLevel, epoch), "Unexpected failure writing entry.")
+	require.NoError(t, clone.WriteEntry(sink, "", "clone", InfoLevel, epoch), "Unexpected failure writing entry.")
+	assert.Equal(t, []string{
+		`{"level":"info","msg":"parent","shared":"yes"}`,
+		"[I] parent shared=yes",
+		`{"level":"info","msg":"clone","shared":"yes","cloned":1}`,
+		"[I] clone shared=yes cloned=1",
+	}, sink.Lines(), "Expected fields added to a clone to affect only the clone's children.")
+}
+
+func TestMultiEncoderDistinctSinks(t *testing.T) {
+	file, console := &testBuffer{}, &testBuffer{}
+	to := func(w io.Writer) func(Level, string, string) io.Writer {
+		return func(Level, string, string) io.Writer { return w }
+	}
+	multi := NewMultiEncoder(
+		NewRoutingEncoder(NewJSONEncoder(NoTime()), to(file)),
+		NewRoutingEncoder(NewTextEncoder(TextNoTime()), to(console)),
+	)
+	defer multi.Free()
+	multi.AddString("user", "jane")
+
+	require.NoError(t, multi.WriteEntry(Discard, "", "hello", InfoLevel, epoch), "Unexpected failure writing entry.")
+	assert.Equal(t, `{"level":"info","msg":"hello","user":"jane"}`, file.Stripped(), "Unexpected JSON output.")
+	assert.Equal(t, "[I] hello user=jane", console.Stripped(), "Unexpected text output.")
+}
+
+func TestMultiEncoderErrors(t *testing.T) {
+	sink := &testBuffer{}
+	multi := NewMultiEncoder(
+		NewRoutingEncoder(NewJSONEncoder(NoTime()), func(Level, string, string) io.Writer { return spywrite.FailWriter{} }),
+		NewTextEncoder(TextNoTime()),
+	)
+	defer multi.Free()
+
+	fail := errors.New("can't marshal")
+	err := multi.AddMarshaler("obj", LogMarshalerFunc(func(KeyValue) error { return fail }))
+	assert.Equal(t, fail, err, "Expected marshaling errors to be returned.")
+
+	assert.Error(t, multi.WriteEntry(sink, "", "hello", InfoLevel, epoch), "Expected an error when a child fails.")
+	assert.Equal(t, "[I] hello obj={}", sink.Stripped(), "Expected later children to write despite an earlier failure.")
+}
+
+func TestMultiEncoderEmpty(t *testing.T) {
+	multi := NewMultiEncoder()
+	addEveryType(multi)
+	assert.NoError(t, multi.Clone().WriteEntry(&testBuffer{}, "", "hello", InfoLevel, epoch), "Expected an empty multi-encoder to write nothing.")
+	multi.Free()
+}
@@ -23,6 +23,7 @@ package zap
 import (
 	"io"
 	"time"
+	"unsafe"
 )
 
 // nullEncoder is an Encoder implementation that throws everything away.
@@ -48,6 +49,25 @@ func (nullEncoder) AddUint64(_ string, _ uint64)   {}
 func (nullEncoder) AddFloat32(_ string, _ float32) {}
 func (nullEncoder) AddFloat64(_ string, _ float64) {}
 
+func (nullEncoder) AddDuration(_ string, _ time.Duration) {}
+func (nullEncoder) AddTime(_ string, _ time.Time)         {}
+func (nullEncoder) AddError(_ string, _ error)            {}
+func (nullEncoder) AddComplex128(_ string, _ complex128)  {}
+func (nullEncoder) AddComplex64(_ string, _ complex64)    {}
+func (nullEncoder) AddInt32(_ string, _ int32)            {}
+func (nullEncoder) AddInt16(_ string, _ int16)            {}
+func (nullEncoder) AddInt8(_ string, _ int8)              {}
+func (nullEncoder) AddUint32(_ string, _ uint32)          {}
+func (nullEncoder) AddUint16(_ string, _ uint16)          {}
+func (nullEncoder) AddUint8(_ string, _ uint8)            {}
+func (nullEncoder) AddStrings(_ string, _ []string)       {}
+func (nullEncoder) AddInts(_ string, _ []int)             {}
+func (nullEncoder) AddRune(_ string, _ rune)              {}
+func (nullEncoder) AddBase64Bytes(_ string, _ []byte)     {}
+func (nullEncoder) AddStackFrames(_ string)               {}
+func (nullEncoder) AddUintptr(_ string, _ uintptr)        {}
+func (nullEncoder) AddPointer(_ string, _ unsafe.Pointer) {}
+
 func (nullEncoder) AddMarshaler(_ string, _ LogMarshaler) error { return nil }
 func (nullEncoder) AddObject(_ string, _ interface{}) error     { return nil }
 
@@ -0,0 +1,89 @@
+// Copyright (c) 2016 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zap
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+// _objectFormatters holds the formatters registered with
+// RegisterObjectFormatter, keyed by the type they format.
+var _objectFormatters = struct {
+	sync.RWMutex
+	m map[reflect.Type]func(Encoder, interface{}) error
+}{m: make(map[reflect.Type]func(Encoder, interface{}) error)}
+
+// RegisterObjectFormatter registers a function that AddObject uses to encode
+// values with the same dynamic type as sample, in place of reflection. The
+// function is called with an encoder scoped to a nested object under the
+// field's key, so it can add structured fields (e.g., with AddString and
+// AddInt) rather than a single opaque string. Registering a nil function
+// removes the type's formatter.
+//
+// Formatters are matched by exact type, so a formatter registered for a
+// struct isn't used for pointers to it. Objects of types without a registered
+// formatter are encoded as before. RegisterObjectFormatter is safe to call
+// from multiple goroutines, but it's typically called from an init function.
+func RegisterObjectFormatter(sample interface{}, fn func(Encoder, interface{}) error) {
+	t := reflect.TypeOf(sample)
+	_objectFormatters.Lock()
+	if fn == nil {
+		delete(_objectFormatters.m, t)
+	} else {
+		_objectFormatters.m[t] = fn
+	}
+	_objectFormatters.Unlock()
+}
+
+// objectFormatterFor returns the formatter registered for obj's type, if any.
+func objectFormatterFor(obj interface{}) func(Encoder, interface{}) error {
+	_objectFormatters.RLock()
+	fn := _objectFormatters.m[reflect.TypeOf(obj)]
+	_objectFormatters.RUnlock()
+	return fn
+}
+
+// addFormattedObject adds obj with its registered formatter, reporting whether
+// one was found.
+func addFormattedObject(enc Encoder, key string, obj interface{}) (bool, error) {
+	fn := objectFormatterFor(obj)
+	if fn == nil {
+		return false, nil
+	}
+	return true, enc.AddMarshaler(key, formattedObject{fn, obj})
+}
+
+// formattedObject adapts an object and its registered formatter to the
+// LogMarshaler interface.
+type formattedObject struct {
+	fn  func(Encoder, interface{}) error
+	obj interface{}
+}
+
+func (f formattedObject) MarshalLog(kv KeyValue) error {
+	enc, ok := kv.(Encoder)
+	if !ok {
+		return fmt.Errorf("can't format %T: %T isn't an Encoder", f.obj, kv)
+	}
+	return f.fn(enc, f.obj)
+}
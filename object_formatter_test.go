@@ -0,0 +1,127 @@
+// Copyright (c) 2016 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zap
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type formattedPoint struct{ X, Y int }
+
+type unformattedPoint struct{ X, Y int }
+
+func formatPoint(enc Encoder, obj interface{}) error {
+	p := obj.(formattedPoint)
+	enc.AddInt("x", p.X)
+	enc.AddInt("y", p.Y)
+	return nil
+}
+
+func withPointFormatter(f func()) {
+	RegisterObjectFormatter(formattedPoint{}, formatPoint)
+	defer RegisterObjectFormatter(formattedPoint{}, nil)
+	f()
+}
+
+func TestRegisterObjectFormatter(t *testing.T) {
+	withPointFormatter(func() {
+		text := NewTextEncoder().(*textEncoder)
+		defer text.Free()
+		assert.NoError(t, text.AddObject("p", formattedPoint{1, 2}), "Unexpected error adding a formatted object.")
+		assert.Equal(t, "p={x=1 y=2}", string(text.bytes), "Expected the text encoder to use the registered formatter.")
+
+		json := newJSONEncoder()
+		defer json.Free()
+		assert.NoError(t, json.AddObject("p", formattedPoint{1, 2}), "Unexpected error adding a formatted object.")
+		assert.Equal(t, `"p":{"x":1,"y":2}`, string(json.bytes), "Expected the JSON encoder to use the registered formatter.")
+
+		qs := NewQueryStringEncoder().(*queryStringEncoder)
+		defer qs.Free()
+		assert.NoError(t, qs.AddObject("p", formattedPoint{1, 2}), "Unexpected error adding a formatted object.")
+		assert.Equal(t, "p.x=1&p.y=2", string(qs.bytes), "Expected the query string encoder to use the registered formatter.")
+
+		logfmt := NewLogfmtEncoder().(*logfmtEncoder)
+		defer logfmt.Free()
+		assert.NoError(t, logfmt.AddObject("p", formattedPoint{1, 2}), "Unexpected error adding a formatted object.")
+		assert.Equal(t, "p.x=1 p.y=2", string(logfmt.bytes), "Expected the logfmt encoder to use the registered formatter.")
+	})
+}
+
+func TestRegisterObjectFormatterFallback(t *testing.T) {
+	withPointFormatter(func() {
+		qs := NewQueryStringEncoder().(*queryStringEncoder)
+		defer qs.Free()
+		assert.NoError(t, qs.AddObject("p", unformattedPoint{1, 2}), "Unexpected error adding an object.")
+		assert.NoError(t, qs.AddObject("ptr", &formattedPoint{3, 4}), "Unexpected error adding an object.")
+		assert.Equal(t, "p=%7BX%3A1+Y%3A2%7D&ptr=%26%7BX%3A3+Y%3A4%7D", string(qs.bytes), "Expected unregistered types to fall back to %+v.")
+
+		json := newJSONEncoder()
+		defer json.Free()
+		assert.NoError(t, json.AddObject("p", unformattedPoint{1, 2}), "Unexpected error adding an object.")
+		assert.Equal(t, `"p":{"X":1,"Y":2}`, string(json.bytes), "Expected unregistered types to fall back to reflection.")
+
+		withTextEncoder(func(enc *textEncoder) {
+			assert.NoError(t, enc.AddObject("p", unformattedPoint{1, 2}), "Unexpected error adding an object.")
+			assert.Equal(t, `p="{X:1 Y:2}"`, string(enc.bytes), "Expected unregistered types to fall back to %+v.")
+		})
+	})
+
+	qs := NewQueryStringEncoder().(*queryStringEncoder)
+	defer qs.Free()
+	assert.NoError(t, qs.AddObject("p", formattedPoint{1, 2}), "Unexpected error adding an object.")
+	assert.Equal(t, "p=%7BX%3A1+Y%3A2%7D", string(qs.bytes), "Expected unregistering a formatter to restore the fallback.")
+}
+
+func TestRegisterObjectFormatterError(t *testing.T) {
+	RegisterObjectFormatter(unformattedPoint{}, func(Encoder, interface{}) error {
+		return errors.New("fail")
+	})
+	defer RegisterObjectFormatter(unformattedPoint{}, nil)
+
+	enc := NewTextEncoder()
+	defer enc.Free()
+	assert.Error(t, enc.AddObject("p", unformattedPoint{}), "Expected formatter errors to be returned.")
+}
+
+func TestRegisterObjectFormatterConcurrency(t *testing.T) {
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			withPointFormatter(func() {})
+		}()
+		go func() {
+			defer wg.Done()
+			enc := NewTextEncoder()
+			defer enc.Free()
+			for j := 0; j < 100; j++ {
+				enc.AddObject(fmt.Sprint(j), formattedPoint{j, j})
+			}
+		}()
+	}
+	wg.Wait()
+}
@@ -0,0 +1,300 @@
+// Copyright (c) 2016 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zap
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"sync"
+	"time"
+	"unsafe"
+)
+
+var queryStringPool = sync.Pool{New: func() interface{} {
+	return &queryStringEncoder{
+		bytes: make([]byte, 0, _initialBufSize),
+	}
+}}
+
+type queryStringEncoder struct {
+	bytes []byte
+	// Prefix for keys added by nested LogMarshalers.
+	prefix string
+}
+
+// NewQueryStringEncoder creates an encoder that renders each entry as a URL
+// query string (e.g., "level=info&ts=...&msg=hello+world&user=jane"), which
+// tools built around URLs can parse with standard library functions like
+// url.ParseQuery. The level, timestamp, logger name, and message are written
+// under the reserved keys "level", "ts", "logger", and "msg". Nested objects
+// are flattened into dotted keys, and all keys and values are
+// percent-encoded.
+func NewQueryStringEncoder() Encoder {
+	enc := queryStringPool.Get().(*queryStringEncoder)
+	enc.truncate()
+	return enc
+}
+
+func (enc *queryStringEncoder) Free() {
+	queryStringPool.Put(enc)
+}
+
+func (enc *queryStringEncoder) AddString(key, val string) {
+	enc.addKey(key)
+	enc.bytes = appendQueryEscaped(enc.bytes, val)
+}
+
+func (enc *queryStringEncoder) AddBool(key string, val bool) {
+	enc.addKey(key)
+	enc.bytes = strconv.AppendBool(enc.bytes, val)
+}
+
+func (enc *queryStringEncoder) AddByte(key string, val byte) {
+	enc.addKey(key)
+	enc.bytes = append(enc.bytes, "0x"...)
+	enc.bytes = append(enc.bytes, hextable[val>>4])
+	enc.bytes = append(enc.bytes, hextable[val&0x0F])
+}
+
+func (enc *queryStringEncoder) AddBytes(key string, val []byte) {
+	enc.addKey(key)
+	enc.bytes = hexEncode(enc.bytes, val)
+}
+
+func (enc *queryStringEncoder) AddInt(key string, val int) {
+	enc.AddInt64(key, int64(val))
+}
+
+func (enc *queryStringEncoder) AddInt64(key string, val int64) {
+	enc.addKey(key)
+	enc.bytes = strconv.AppendInt(enc.bytes, val, 10)
+}
+
+func (enc *queryStringEncoder) AddUint(key string, val uint) {
+	enc.AddUint64(key, uint64(val))
+}
+
+func (enc *queryStringEncoder) AddUint64(key string, val uint64) {
+	enc.addKey(key)
+	enc.bytes = strconv.AppendUint(enc.bytes, val, 10)
+}
+
+func (enc *queryStringEncoder) AddInt32(key string, val int32) {
+	enc.AddInt64(key, int64(val))
+}
+
+func (enc *queryStringEncoder) AddInt16(key string, val int16) {
+	enc.AddInt64(key, int64(val))
+}
+
+func (enc *queryStringEncoder) AddInt8(key string, val int8) {
+	enc.AddInt64(key, int64(val))
+}
+
+func (enc *queryStringEncoder) AddUint32(key string, val uint32) {
+	enc.AddUint64(key, uint64(val))
+}
+
+func (enc *queryStringEncoder) AddUint16(key string, val uint16) {
+	enc.AddUint64(key, uint64(val))
+}
+
+func (enc *queryStringEncoder) AddUint8(key string, val uint8) {
+	enc.AddUint64(key, uint64(val))
+}
+
+func (enc *queryStringEncoder) AddFloat32(key string, val float32) {
+	enc.addFloat(key, float64(val), 32)
+}
+
+func (enc *queryStringEncoder) AddFloat64(key string, val float64) {
+	enc.addFloat(key, val, 64)
+}
+
+func (enc *queryStringEncoder) addFloat(key string, val float64, bitSize int) {
+	enc.addKey(key)
+	switch {
+	case math.IsNaN(val):
+		enc.bytes = append(enc.bytes, "NaN"...)
+	case math.IsInf(val, 1):
+		enc.bytes = append(enc.bytes, "%2BInf"...)
+	case math.IsInf(val, -1):
+		enc.bytes = append(enc.bytes, "-Inf"...)
+	default:
+		enc.bytes = strconv.AppendFloat(enc.bytes, val, 'f', -1, bitSize)
+	}
+}
+
+func (enc *queryStringEncoder) AddDuration(key string, val time.Duration) {
+	// Sub-millisecond durations use the "µ" unit, which must be escaped.
+	enc.AddString(key, string(appendDuration(nil, val)))
+}
+
+func (enc *queryStringEncoder) AddTime(key string, val time.Time) {
+	enc.AddString(key, val.Format(time.RFC3339Nano))
+}
+
+func (enc *queryStringEncoder) AddComplex128(key string, val complex128) {
+	enc.AddString(key, string(appendComplex(nil, val, 64)))
+}
+
+func (enc *queryStringEncoder) AddComplex64(key string, val complex64) {
+	enc.AddString(key, string(appendComplex(nil, complex128(val), 32)))
+}
+
+// AddError adds an error's message, along with the messages of any errors it
+// wraps. A nil error is written as an empty value.
+func (enc *queryStringEncoder) AddError(key string, err error) {
+	if err == nil {
+		enc.AddString(key, "")
+		return
+	}
+	enc.AddString(key, errorChain(err))
+}
+
+// AddStrings repeats the key once per value (e.g., "tag=a&tag=b"), the way
+// url.Values encodes lists. Nothing is written for an empty slice.
+func (enc *queryStringEncoder) AddStrings(key string, vals []string) {
+	for _, v := range vals {
+		enc.AddString(key, v)
+	}
+}
+
+// AddInts repeats the key once per value, like AddStrings.
+func (enc *queryStringEncoder) AddInts(key string, vals []int) {
+	for _, v := range vals {
+		enc.AddInt64(key, int64(v))
+	}
+}
+
+func (enc *queryStringEncoder) AddRune(key string, val rune) {
+	enc.AddString(key, string(val))
+}
+
+func (enc *queryStringEncoder) AddBase64Bytes(key string, val []byte) {
+	enc.AddString(key, string(appendBase64(nil, val)))
+}
+
+// AddStackFrames flattens each frame's fields into keys prefixed with the
+// supplied key and the frame's index (e.g., "stack.0.file=main.go").
+func (enc *queryStringEncoder) AddStackFrames(key string) {
+	for i, f := range takeStackFrames() {
+		enc.AddMarshaler(key+"."+strconv.Itoa(i), f)
+	}
+}
+
+func (enc *queryStringEncoder) AddUintptr(key string, val uintptr) {
+	enc.AddString(key, string(appendAddress(nil, val)))
+}
+
+func (enc *queryStringEncoder) AddPointer(key string, val unsafe.Pointer) {
+	enc.AddUintptr(key, uintptr(val))
+}
+
+// AddMarshaler flattens the object's fields into keys prefixed with the
+// supplied key and a dot.
+func (enc *queryStringEncoder) AddMarshaler(key string, obj LogMarshaler) error {
+	prefix := enc.prefix
+	enc.prefix = prefix + key + "."
+	err := obj.MarshalLog(enc)
+	enc.prefix = prefix
+	return err
+}
+
+func (enc *queryStringEncoder) AddObject(key string, obj interface{}) error {
+	if ok, err := addFormattedObject(enc, key, obj); ok {
+		return err
+	}
+	enc.AddString(key, fmt.Sprintf("%+v", obj))
+	return nil
+}
+
+func (enc *queryStringEncoder) Clone() Encoder {
+	clone := queryStringPool.Get().(*queryStringEncoder)
+	clone.truncate()
+	clone.bytes = append(clone.bytes, enc.bytes...)
+	return clone
+}
+
+func (enc *queryStringEncoder) WriteEntry(sink io.Writer, name string, msg string, lvl Level, t time.Time) error {
+	if sink == nil {
+		return errNilSink
+	}
+
+	final := queryStringPool.Get().(*queryStringEncoder)
+	final.truncate()
+	final.AddString("level", lvl.String())
+	final.AddString("ts", t.Format(time.RFC3339Nano))
+	if name != "" {
+		final.AddString("logger", name)
+	}
+	final.AddString("msg", msg)
+	if len(enc.bytes) > 0 {
+		final.bytes = append(final.bytes, '&')
+		final.bytes = append(final.bytes, enc.bytes...)
+	}
+	final.bytes = append(final.bytes, '\n')
+
+	expectedBytes := len(final.bytes)
+	n, err := sink.Write(final.bytes)
+	final.Free()
+	if err != nil {
+		return err
+	}
+	if n != expectedBytes {
+		return &ShortWriteError{Wrote: n, Expected: expectedBytes}
+	}
+	return nil
+}
+
+func (enc *queryStringEncoder) truncate() {
+	enc.bytes = enc.bytes[:0]
+	enc.prefix = ""
+}
+
+func (enc *queryStringEncoder) addKey(key string) {
+	if len(enc.bytes) > 0 {
+		enc.bytes = append(enc.bytes, '&')
+	}
+	enc.bytes = appendQueryEscaped(enc.bytes, enc.prefix)
+	enc.bytes = appendQueryEscaped(enc.bytes, key)
+	enc.bytes = append(enc.bytes, '=')
+}
+
+// appendQueryEscaped appends s, escaped like url.QueryEscape, without
+// allocating.
+func appendQueryEscaped(buf []byte, s string) []byte {
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case 'a' <= c && c <= 'z', 'A' <= c && c <= 'Z', '0' <= c && c <= '9',
+			c == '-', c == '_', c == '.', c == '~':
+			buf = append(buf, c)
+		case c == ' ':
+			buf = append(buf, '+')
+		default:
+			buf = append(buf, '%', hextable[c>>4], hextable[c&0x0F])
+		}
+	}
+	return buf
+}
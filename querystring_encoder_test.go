@@ -0,0 +1,112 @@
+// Copyright (c) 2016 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zap
+
+import (
+	"math"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/uber-go/zap/spywrite"
+)
+
+func parseQueryEntry(t testing.TB, enc Encoder, name, msg string) url.Values {
+	sink := &testBuffer{}
+	require.NoError(t, enc.WriteEntry(sink, name, msg, InfoLevel, epoch), "Unexpected failure writing entry.")
+	vals, err := url.ParseQuery(sink.Stripped())
+	require.NoError(t, err, "Expected output to be a valid query string.")
+	return vals
+}
+
+func TestQueryStringEncoderRoundTrip(t *testing.T) {
+	enc := NewQueryStringEncoder()
+	defer enc.Free()
+	enc.AddString("spaces", "hello world")
+	enc.AddString("reserved", "a&b=c?d#e%f+g")
+	enc.AddString("unicode", "héllo ✓ 日本")
+	enc.AddString("key with spaces&=", "v")
+	enc.AddBool("bool", true)
+	enc.AddInt("int", -42)
+	enc.AddUint64("uint", math.MaxUint64)
+	enc.AddFloat64("float", 1.5)
+	enc.AddFloat64("inf", math.Inf(1))
+	enc.AddBytes("bytes", []byte{0xde, 0xad})
+	assert.NoError(t, enc.AddMarshaler("obj", loggable{true}), "Unexpected error adding a marshaler.")
+
+	vals := parseQueryEntry(t, enc, "my logger", "what & why = 100%")
+	assert.Equal(t, url.Values{
+		"level":             {"info"},
+		"ts":                {"1970-01-01T00:00:00Z"},
+		"logger":            {"my logger"},
+		"msg":               {"what & why = 100%"},
+		"spaces":            {"hello world"},
+		"reserved":          {"a&b=c?d#e%f+g"},
+		"unicode":           {"héllo ✓ 日本"},
+		"key with spaces&=": {"v"},
+		"bool":              {"true"},
+		"int":               {"-42"},
+		"uint":              {"18446744073709551615"},
+		"float":             {"1.5"},
+		"inf":               {"+Inf"},
+		"bytes":             {"0xDEAD"},
+		"obj.loggable":      {"yes"},
+	}, vals, "Unexpected values after round-tripping through url.ParseQuery.")
+}
+
+func TestQueryStringEncoderDuration(t *testing.T) {
+	enc := NewQueryStringEncoder()
+	defer enc.Free()
+	enc.AddDuration("d", 1500*time.Nanosecond)
+	enc.AddDuration("s", 1500*time.Millisecond)
+
+	sink := &testBuffer{}
+	require.NoError(t, enc.WriteEntry(sink, "", "", InfoLevel, epoch), "Unexpected failure writing entry.")
+	assert.Contains(t, sink.Stripped(), "d=1.5%C2%B5s&s=1.5s", "Expected durations to be percent-encoded.")
+
+	vals := parseQueryEntry(t, enc, "", "")
+	assert.Equal(t, "1.5µs", vals.Get("d"), "Unexpected sub-millisecond duration after round-tripping.")
+	assert.Equal(t, "1.5s", vals.Get("s"), "Unexpected duration after round-tripping.")
+}
+
+func TestQueryStringEncoderClone(t *testing.T) {
+	parent := NewQueryStringEncoder()
+	defer parent.Free()
+	parent.AddString("foo", "bar")
+	clone := parent.Clone()
+	defer clone.Free()
+	clone.AddString("baz", "bing")
+
+	assert.Equal(t, "bar", parseQueryEntry(t, clone, "", "").Get("foo"), "Expected clones to inherit fields.")
+	assert.Equal(t, "", parseQueryEntry(t, parent, "", "").Get("baz"), "Adding to a clone shouldn't affect the parent.")
+	_, ok := parseQueryEntry(t, parent, "", "")["logger"]
+	assert.False(t, ok, "Expected empty logger names to be omitted.")
+}
+
+func TestQueryStringEncoderWriteFailure(t *testing.T) {
+	enc := NewQueryStringEncoder()
+	defer enc.Free()
+	assert.Equal(t, errNilSink, enc.WriteEntry(nil, "", "hello", InfoLevel, epoch), "Expected an error writing to a nil sink.")
+	assert.Error(t, enc.WriteEntry(spywrite.FailWriter{}, "", "hello", InfoLevel, epoch), "Expected an error when the sink fails.")
+	assert.Error(t, enc.WriteEntry(spywrite.ShortWriter{}, "", "hello", InfoLevel, epoch), "Expected an error on partial writes.")
+}
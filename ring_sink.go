@@ -0,0 +1,85 @@
+// Copyright (c) 2016 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zap
+
+import "sync"
+
+// A RingSink is a WriteSyncer that keeps only the most recently written
+// entries in memory, overwriting the oldest once it's full. It's useful for
+// post-mortem debugging: a crash handler can dump the recent logs even if
+// they were never flushed to a durable sink.
+//
+// Each call to Write is treated as a single entry, which matches the way
+// encoders write to their sinks. It's safe to use a RingSink concurrently.
+type RingSink struct {
+	sync.Mutex
+
+	entries [][]byte
+	next    int
+	full    bool
+}
+
+// NewRingSink creates a RingSink that retains the last capacity entries.
+// Non-positive capacities are treated as one.
+func NewRingSink(capacity int) *RingSink {
+	if capacity < 1 {
+		capacity = 1
+	}
+	return &RingSink{entries: make([][]byte, capacity)}
+}
+
+// Write copies the supplied bytes into the ring, evicting the oldest entry if
+// necessary.
+func (r *RingSink) Write(bs []byte) (int, error) {
+	r.Lock()
+	// Encoders re-use their buffers, so we must copy. Re-use the evicted
+	// entry's storage where possible.
+	r.entries[r.next] = append(r.entries[r.next][:0], bs...)
+	r.next++
+	if r.next == len(r.entries) {
+		r.next = 0
+		r.full = true
+	}
+	r.Unlock()
+	return len(bs), nil
+}
+
+// Sync is a no-op, since the ring is purely in-memory.
+func (r *RingSink) Sync() error {
+	return nil
+}
+
+// Dump returns a copy of the retained entries, oldest first.
+func (r *RingSink) Dump() []byte {
+	r.Lock()
+	defer r.Unlock()
+
+	var buf []byte
+	if r.full {
+		for _, e := range r.entries[r.next:] {
+			buf = append(buf, e...)
+		}
+	}
+	for _, e := range r.entries[:r.next] {
+		buf = append(buf, e...)
+	}
+	return buf
+}
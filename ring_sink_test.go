@@ -0,0 +1,67 @@
+// Copyright (c) 2016 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zap
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRingSinkKeepsRecentEntries(t *testing.T) {
+	ring := NewRingSink(3)
+	enc := NewTextEncoder(TextNoTime())
+	defer enc.Free()
+
+	assert.Empty(t, ring.Dump(), "Expected an empty ring to dump nothing.")
+
+	for i := 0; i < 2; i++ {
+		assert.NoError(t, enc.WriteEntry(ring, "", fmt.Sprint(i), InfoLevel, epoch), "Unexpected failure writing to ring.")
+	}
+	assert.Equal(t, "[I] 0\n[I] 1\n", string(ring.Dump()), "Unexpected contents of partially-full ring.")
+
+	for i := 2; i < 5; i++ {
+		assert.NoError(t, enc.WriteEntry(ring, "", fmt.Sprint(i), InfoLevel, epoch), "Unexpected failure writing to ring.")
+	}
+	assert.Equal(t, "[I] 2\n[I] 3\n[I] 4\n", string(ring.Dump()), "Expected the ring to keep only the most recent entries, in order.")
+}
+
+func TestRingSinkConcurrency(t *testing.T) {
+	ring := NewRingSink(10)
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			enc := NewTextEncoder(TextNoTime())
+			for j := 0; j < 100; j++ {
+				enc.WriteEntry(ring, "", "concurrent", InfoLevel, epoch)
+				ring.Dump()
+			}
+			enc.Free()
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, strings.Repeat("[I] concurrent\n", 10), string(ring.Dump()), "Unexpected ring contents after concurrent writes.")
+}
@@ -0,0 +1,52 @@
+// Copyright (c) 2016 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zap
+
+import (
+	"io"
+	"time"
+)
+
+type routingEncoder struct {
+	Encoder
+	route func(lvl Level, name, msg string) io.Writer
+}
+
+// NewRoutingEncoder wraps an encoder, calling route to choose the destination
+// of each entry. This enables arbitrary content-based routing, like sending
+// audit messages to a separate file. Entries are rendered once by the wrapped
+// encoder and written only to the writer that route returns; the writer passed
+// to WriteEntry is ignored. If route returns nil, the entry is dropped.
+func NewRoutingEncoder(inner Encoder, route func(lvl Level, name, msg string) io.Writer) Encoder {
+	return routingEncoder{inner, route}
+}
+
+func (enc routingEncoder) Clone() Encoder {
+	return routingEncoder{enc.Encoder.Clone(), enc.route}
+}
+
+func (enc routingEncoder) WriteEntry(_ io.Writer, name string, msg string, lvl Level, t time.Time) error {
+	sink := enc.route(lvl, name, msg)
+	if sink == nil {
+		return nil
+	}
+	return enc.Encoder.WriteEntry(sink, name, msg, lvl, t)
+}
@@ -0,0 +1,63 @@
+// Copyright (c) 2016 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zap
+
+import (
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRoutingEncoder(t *testing.T) {
+	audit, app := &testBuffer{}, &testBuffer{}
+	enc := NewRoutingEncoder(NewTextEncoder(TextNoTime()), func(lvl Level, name, msg string) io.Writer {
+		switch {
+		case strings.HasPrefix(msg, "audit:"):
+			return audit
+		case lvl == DebugLevel:
+			return nil
+		default:
+			return app
+		}
+	})
+	defer enc.Free()
+	enc.AddString("foo", "bar")
+	clone := enc.Clone()
+	defer clone.Free()
+	clone.AddInt("n", 1)
+
+	assert.NoError(t, enc.WriteEntry(app, "", "audit: user deleted", InfoLevel, epoch), "Unexpected failure writing entry.")
+	assert.NoError(t, enc.WriteEntry(app, "", "hello", InfoLevel, epoch), "Unexpected failure writing entry.")
+	assert.NoError(t, clone.WriteEntry(app, "", "audit: from clone", WarnLevel, epoch), "Unexpected failure writing entry.")
+	assert.NoError(t, clone.WriteEntry(app, "", "dropped", DebugLevel, epoch), "Unexpected failure dropping entry.")
+	assert.NoError(t, enc.WriteEntry(nil, "", "nil sink is ignored", ErrorLevel, epoch), "Expected the caller's sink to be ignored.")
+
+	assert.Equal(t, []string{
+		"[I] audit: user deleted foo=bar",
+		"[W] audit: from clone foo=bar n=1",
+	}, audit.Lines(), "Unexpected entries routed to the audit sink.")
+	assert.Equal(t, []string{
+		"[I] hello foo=bar",
+		"[E] nil sink is ignored foo=bar",
+	}, app.Lines(), "Unexpected entries routed to the app sink.")
+}
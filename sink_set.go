@@ -0,0 +1,84 @@
+// Copyright (c) 2016 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zap
+
+import (
+	"io"
+	"reflect"
+	"sync"
+)
+
+// _maxTrackedSinks bounds the number of sinks remembered by a sinkSet.
+const _maxTrackedSinks = 1024
+
+// A sinkSet records which sinks have been written to, so that encoders can
+// write per-sink preambles (like format headers) exactly once. It's shared by
+// an encoder and all its clones.
+//
+// The set remembers at most _maxTrackedSinks sinks. When it's full, the sink
+// that was least recently written to is forgotten, so a program that rotates
+// through many short-lived sinks doesn't keep them all alive.
+type sinkSet struct {
+	sync.Mutex
+	// Each sink maps to the sequence number of its most recent write.
+	seen map[io.Writer]uint64
+	seq  uint64
+}
+
+func newSinkSet() *sinkSet {
+	return &sinkSet{seen: make(map[io.Writer]uint64)}
+}
+
+// add marks the sink as seen, returning true if it wasn't already. Sinks whose
+// dynamic types aren't comparable can't be tracked, so they're always treated
+// as already seen.
+func (s *sinkSet) add(sink io.Writer) bool {
+	if !reflect.TypeOf(sink).Comparable() {
+		return false
+	}
+	s.Lock()
+	defer s.Unlock()
+	s.seq++
+	if _, ok := s.seen[sink]; ok {
+		s.seen[sink] = s.seq
+		return false
+	}
+	if len(s.seen) >= _maxTrackedSinks {
+		s.evict()
+	}
+	s.seen[sink] = s.seq
+	return true
+}
+
+// evict forgets the least recently written sink. The caller must hold the
+// lock.
+func (s *sinkSet) evict() {
+	var (
+		oldest io.Writer
+		min    uint64
+	)
+	for sink, seq := range s.seen {
+		if oldest == nil || seq < min {
+			oldest, min = sink, seq
+		}
+	}
+	delete(s.seen, oldest)
+}
@@ -20,7 +20,18 @@
 
 package zap
 
-import "runtime"
+import (
+	"reflect"
+	"runtime"
+	"strings"
+)
+
+// _maxStackFrames caps the number of frames captured by takeStackFrames.
+const _maxStackFrames = 64
+
+// _zapPackage is the import path of this package, which is used to recognize
+// its frames.
+var _zapPackage = reflect.TypeOf(Field{}).PkgPath()
 
 // takeStacktrace attempts to use the provided byte slice to take a stacktrace.
 // If the provided slice isn't large enough, takeStacktrace will allocate
@@ -40,3 +51,58 @@ func takeStacktrace(buf []byte, includeAllGoroutines bool) string {
 	}
 	return string(buf[:n])
 }
+
+// A stackFrame is a single frame of a captured stack.
+type stackFrame struct {
+	file     string
+	line     int
+	function string
+}
+
+func (f stackFrame) MarshalLog(kv KeyValue) error {
+	kv.AddString("file", f.file)
+	kv.AddInt("line", f.line)
+	kv.AddString("func", f.function)
+	return nil
+}
+
+type stackFrames []stackFrame
+
+func (fs stackFrames) MarshalLogArray(arr ArrayEncoder) error {
+	for _, f := range fs {
+		if err := arr.AppendObject(f); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// takeStackFrames captures the current goroutine's stack, innermost frame
+// first, excluding frames from zap itself. At most 64 frames are captured.
+func takeStackFrames() stackFrames {
+	var pcs [_maxStackFrames]uintptr
+	// Skip runtime.Callers and takeStackFrames; the rest of zap's frames are
+	// filtered out below.
+	n := runtime.Callers(2, pcs[:])
+	frames := make(stackFrames, 0, n)
+	iter := runtime.CallersFrames(pcs[:n])
+	for {
+		frame, more := iter.Next()
+		if !isZapFrame(frame) {
+			frames = append(frames, stackFrame{frame.File, frame.Line, frame.Function})
+		}
+		if !more {
+			return frames
+		}
+	}
+}
+
+// isZapFrame reports whether a frame is from zap's own source, including its
+// subpackages. Frames from zap's tests aren't considered internal.
+func isZapFrame(frame runtime.Frame) bool {
+	fn := frame.Function
+	if !strings.HasPrefix(fn, _zapPackage+".") && !strings.HasPrefix(fn, _zapPackage+"/") {
+		return false
+	}
+	return !strings.HasSuffix(frame.File, "_test.go")
+}
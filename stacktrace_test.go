@@ -21,9 +21,13 @@
 package zap
 
 import (
+	"encoding/json"
+	"regexp"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestTakeStacktrace(t *testing.T) {
@@ -38,3 +42,65 @@ func TestTakeStacktrace(t *testing.T) {
 		assert.Contains(t, trace, "TestTakeStacktrace", "Stacktrace should contain the test function.")
 	}
 }
+
+func TestTakeStackFrames(t *testing.T) {
+	frames := takeStackFrames()
+	require.NotEmpty(t, frames, "Expected to capture some frames.")
+	assert.Equal(t, _zapPackage+".TestTakeStackFrames", frames[0].function, "Expected the caller to be the innermost frame.")
+	assert.True(t, strings.HasSuffix(frames[0].file, "stacktrace_test.go"), "Unexpected file for the innermost frame.")
+	assert.True(t, frames[0].line > 0, "Expected a line number.")
+	for _, f := range frames {
+		assert.False(t, strings.HasPrefix(f.function, _zapPackage+".takeStackFrames"), "Expected zap's frames to be excluded.")
+	}
+}
+
+func TestJSONAddStackFrames(t *testing.T) {
+	withJSONEncoder(func(enc *jsonEncoder) {
+		enc.AddStackFrames("stack")
+
+		var parsed struct {
+			Stack []map[string]interface{} `json:"stack"`
+		}
+		require.NoError(t, json.Unmarshal([]byte("{"+string(enc.bytes)+"}"), &parsed), "Expected valid JSON.")
+		require.NotEmpty(t, parsed.Stack, "Expected some frames.")
+		for _, f := range parsed.Stack {
+			assert.Len(t, f, 3, "Unexpected fields in frame %v.", f)
+			assert.IsType(t, "", f["file"], "Expected the file to be a string.")
+			assert.IsType(t, float64(0), f["line"], "Expected the line to be a number.")
+			assert.IsType(t, "", f["func"], "Expected the function to be a string.")
+			assert.NotEqual(t, _zapPackage+".(*jsonEncoder).AddStackFrames", f["func"], "Expected zap's frames to be excluded.")
+		}
+		assert.Equal(t, _zapPackage+".TestJSONAddStackFrames.func1", parsed.Stack[0]["func"], "Expected the caller to be the innermost frame.")
+	})
+}
+
+func TestTextAddStackFrames(t *testing.T) {
+	withTextEncoder(func(enc *textEncoder) {
+		enc.AddStackFrames("stack")
+		out := string(enc.bytes)
+		assert.Regexp(t, `^stack=\[\{file=\S+stacktrace_test.go line=\d+ func=`+regexp.QuoteMeta(_zapPackage)+`.TestTextAddStackFrames.func1\} \{`, out, "Unexpected text stack frames.")
+		assert.True(t, strings.HasSuffix(out, "}]"), "Expected a bracketed list of frames.")
+		assert.NotContains(t, out, "(*textEncoder)", "Expected zap's frames to be excluded.")
+	})
+}
+
+func TestOtherEncodersAddStackFrames(t *testing.T) {
+	yaml := NewYAMLFlowEncoder().(*yamlFlowEncoder)
+	defer yaml.Free()
+	yaml.AddStackFrames("stack")
+	assert.Regexp(t, `^stack: \[\{file: \S+stacktrace_test.go, line: \d+, func: \S+TestOtherEncodersAddStackFrames\}, \{`, string(yaml.bytes), "Unexpected YAML stack frames.")
+
+	qs := NewQueryStringEncoder().(*queryStringEncoder)
+	defer qs.Free()
+	qs.AddStackFrames("stack")
+	assert.Regexp(t, `^stack.0.file=\S+stacktrace_test.go&stack.0.line=\d+&stack.0.func=\S+TestOtherEncodersAddStackFrames&stack.1.file=`, string(qs.bytes), "Unexpected query string stack frames.")
+
+	multi := NewMultiEncoder(NewTextEncoder(TextNoTime()))
+	defer multi.Free()
+	multi.AddStackFrames("stack")
+	sink := &testBuffer{}
+	require.NoError(t, multi.WriteEntry(sink, "", "msg", InfoLevel, epoch), "Unexpected failure writing entry.")
+	assert.Contains(t, sink.String(), "TestOtherEncodersAddStackFrames}", "Expected the multi-encoder's frames to be excluded.")
+
+	NullEncoder().AddStackFrames("stack")
+}
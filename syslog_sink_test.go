@@ -0,0 +1,191 @@
+// Copyright (c) 2016 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zap
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// syslogTCPListener is a syslog collector that reads octet-counted frames and
+// can drop its connections.
+type syslogTCPListener struct {
+	sync.Mutex
+	t        testing.TB
+	ln       net.Listener
+	conns    []net.Conn
+	accepted int
+	frames   chan string
+}
+
+func newSyslogTCPListener(t testing.TB) *syslogTCPListener {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err, "Failed to listen on TCP.")
+	l := &syslogTCPListener{t: t, ln: ln, frames: make(chan string, 1024)}
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			l.Lock()
+			l.conns = append(l.conns, conn)
+			l.accepted++
+			l.Unlock()
+			go l.read(conn)
+		}
+	}()
+	return l
+}
+
+func (l *syslogTCPListener) read(conn net.Conn) {
+	r := bufio.NewReader(conn)
+	for {
+		prefix, err := r.ReadString(' ')
+		if err != nil {
+			return
+		}
+		n, err := strconv.Atoi(prefix[:len(prefix)-1])
+		if err != nil {
+			l.frames <- "bad length: " + prefix
+			return
+		}
+		msg := make([]byte, n)
+		if _, err := io.ReadFull(r, msg); err != nil {
+			return
+		}
+		l.frames <- string(msg)
+	}
+}
+
+func (l *syslogTCPListener) addr() string {
+	return l.ln.Addr().String()
+}
+
+func (l *syslogTCPListener) connections() int {
+	l.Lock()
+	defer l.Unlock()
+	return l.accepted
+}
+
+// drop closes all the accepted connections.
+func (l *syslogTCPListener) drop() {
+	l.Lock()
+	defer l.Unlock()
+	for _, c := range l.conns {
+		c.Close()
+	}
+	l.conns = nil
+}
+
+func (l *syslogTCPListener) close() {
+	l.ln.Close()
+	l.drop()
+}
+
+func (l *syslogTCPListener) next() string {
+	select {
+	case frame := <-l.frames:
+		return frame
+	case <-time.After(5 * time.Second):
+		l.t.Fatal("Timed out waiting for a frame from the sink.")
+		return ""
+	}
+}
+
+func TestSyslogTCPSinkFraming(t *testing.T) {
+	l := newSyslogTCPListener(t)
+	defer l.close()
+	sink := NewSyslogTCPSink(l.addr())
+	defer sink.Close()
+
+	for _, msg := range []string{"<14>1 - host app - - - hello\n", "multi\nline\n", "no newline", "\n"} {
+		n, err := sink.Write([]byte(msg))
+		require.NoError(t, err, "Unexpected failure writing %q.", msg)
+		assert.Equal(t, len(msg), n, "Expected the whole entry to be reported as written.")
+	}
+	assert.Equal(t, "<14>1 - host app - - - hello", l.next(), "Expected the trailing newline to be removed.")
+	assert.Equal(t, "multi\nline", l.next(), "Expected embedded newlines to be preserved.")
+	assert.Equal(t, "no newline", l.next(), "Unexpected frame without a trailing newline.")
+	assert.Equal(t, "", l.next(), "Unexpected empty frame.")
+}
+
+func TestSyslogTCPSinkReconnects(t *testing.T) {
+	l := newSyslogTCPListener(t)
+	defer l.close()
+	sink := NewSyslogTCPSink(l.addr())
+	defer sink.Close()
+	enc := NewTextEncoder(TextNoTime())
+	defer enc.Free()
+
+	require.NoError(t, enc.WriteEntry(sink, "", "before", InfoLevel, epoch), "Unexpected failure writing entry.")
+	assert.Equal(t, "[I] before", l.next(), "Unexpected first entry.")
+
+	// TCP reports a closed connection only after a write has been attempted,
+	// so entries written just after the drop may be lost.
+	l.drop()
+	deadline := time.Now().Add(5 * time.Second)
+	for i := 0; l.connections() < 2; i++ {
+		require.True(t, time.Now().Before(deadline), "Timed out waiting for the sink to reconnect.")
+		enc.WriteEntry(sink, "", fmt.Sprint("retry ", i), InfoLevel, epoch)
+		time.Sleep(time.Millisecond)
+	}
+	require.NoError(t, enc.WriteEntry(sink, "", "after", InfoLevel, epoch), "Unexpected failure writing entry.")
+	for {
+		frame := l.next()
+		assert.Regexp(t, `^\[I\] (retry \d+|after)$`, frame, "Expected only whole frames after reconnecting.")
+		if frame == "[I] after" {
+			break
+		}
+	}
+}
+
+func TestSyslogUDPSink(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err, "Failed to listen on UDP.")
+	defer conn.Close()
+	sink := NewSyslogUDPSink(conn.LocalAddr().String())
+	defer sink.Close()
+
+	entries := []string{"<14>1 - host app - - - first\n", "<11>1 - host app - - - multi\nline\n"}
+	for _, msg := range entries {
+		n, err := sink.Write([]byte(msg))
+		require.NoError(t, err, "Unexpected failure writing %q.", msg)
+		assert.Equal(t, len(msg), n, "Expected the whole entry to be reported as written.")
+	}
+
+	buf := make([]byte, 1024)
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	for _, msg := range entries {
+		n, _, err := conn.ReadFrom(buf)
+		require.NoError(t, err, "Failed to read datagram.")
+		assert.Equal(t, msg[:len(msg)-1], string(buf[:n]), "Expected one datagram per entry, without the trailing newline.")
+	}
+}
@@ -0,0 +1,121 @@
+// Copyright (c) 2016 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zap
+
+import "strconv"
+
+// AddArray adds an array, rendering its elements separated by spaces (e.g.,
+// "items=[{id=1 name=foo} {id=2 name=bar}]"). Nested arrays and objects are
+// enclosed in their own brackets and braces.
+func (enc *textEncoder) AddArray(key string, arr ArrayMarshaler) error {
+	enc.addKey(key)
+	return enc.addArray(arr)
+}
+
+// AddStrings adds a list of strings, rendered as bracketed, comma-separated
+// values (e.g., "tags=[a,b,c]"). Each value is quoted under the same rules as
+// AddString, and nil and empty slices are both rendered as "[]".
+func (enc *textEncoder) AddStrings(key string, vals []string) {
+	enc.addKey(key)
+	enc.bytes = append(enc.bytes, '[')
+	for i, v := range vals {
+		if i > 0 {
+			enc.bytes = append(enc.bytes, ',')
+		}
+		enc.bytes = enc.appendString(enc.bytes, enc.scanSecrets(v))
+	}
+	enc.bytes = append(enc.bytes, ']')
+}
+
+// AddInts adds a list of integers in the same form as AddStrings.
+func (enc *textEncoder) AddInts(key string, vals []int) {
+	enc.addKey(key)
+	enc.bytes = append(enc.bytes, '[')
+	for i, v := range vals {
+		if i > 0 {
+			enc.bytes = append(enc.bytes, ',')
+		}
+		enc.bytes = strconv.AppendInt(enc.bytes, int64(v), 10)
+	}
+	enc.bytes = append(enc.bytes, ']')
+}
+
+// AddStackFrames captures the current stack and adds its frames as a list of
+// objects, innermost first (e.g., "stack=[{file=/src/main.go line=12
+// func=main.main} ...]"). Zap's own frames are excluded.
+func (enc *textEncoder) AddStackFrames(key string) {
+	enc.AddArray(key, takeStackFrames())
+}
+
+func (enc *textEncoder) addArray(arr ArrayMarshaler) error {
+	enc.bytes = append(enc.bytes, '[')
+	enc.depth++
+	err := arr.MarshalLogArray(enc)
+	enc.depth--
+	enc.bytes = append(enc.bytes, ']')
+	return err
+}
+
+func (enc *textEncoder) AppendBool(val bool) {
+	enc.addElementSeparator()
+	enc.bytes = strconv.AppendBool(enc.bytes, val)
+}
+
+func (enc *textEncoder) AppendFloat64(val float64) {
+	enc.addElementSeparator()
+	enc.bytes = strconv.AppendFloat(enc.bytes, val, 'f', -1, 64)
+}
+
+func (enc *textEncoder) AppendInt64(val int64) {
+	enc.addElementSeparator()
+	enc.bytes = strconv.AppendInt(enc.bytes, val, 10)
+}
+
+func (enc *textEncoder) AppendUint64(val uint64) {
+	enc.addElementSeparator()
+	enc.bytes = strconv.AppendUint(enc.bytes, val, 10)
+}
+
+func (enc *textEncoder) AppendString(val string) {
+	enc.addElementSeparator()
+	enc.bytes = enc.appendRaw(enc.bytes, enc.scanSecrets(val))
+}
+
+func (enc *textEncoder) AppendObject(obj LogMarshaler) error {
+	enc.addElementSeparator()
+	enc.bytes = append(enc.bytes, '{')
+	enc.depth++
+	err := obj.MarshalLog(enc)
+	enc.depth--
+	enc.bytes = append(enc.bytes, '}')
+	return err
+}
+
+func (enc *textEncoder) AppendArray(arr ArrayMarshaler) error {
+	enc.addElementSeparator()
+	return enc.addArray(arr)
+}
+
+func (enc *textEncoder) addElementSeparator() {
+	if last := len(enc.bytes) - 1; last >= 0 && enc.bytes[last] != '[' {
+		enc.bytes = append(enc.bytes, ' ')
+	}
+}
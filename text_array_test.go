@@ -0,0 +1,169 @@
+// Copyright (c) 2016 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zap
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testUser struct {
+	id   int64
+	name string
+}
+
+func (u testUser) MarshalLog(kv KeyValue) error {
+	kv.AddInt64("id", u.id)
+	kv.AddString("name", u.name)
+	return nil
+}
+
+type testUsers []testUser
+
+func (us testUsers) MarshalLogArray(arr ArrayEncoder) error {
+	for _, u := range us {
+		if err := arr.AppendObject(u); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func TestTextEncoderAddArray(t *testing.T) {
+	tests := []struct {
+		desc     string
+		arr      ArrayMarshaler
+		expected string
+	}{
+		{"empty", testUsers{}, "arr=[]"},
+		{
+			"objects",
+			testUsers{{1, "foo"}, {2, "bar"}},
+			"arr=[{id=1 name=foo} {id=2 name=bar}]",
+		},
+		{
+			"scalars",
+			ArrayMarshalerFunc(func(arr ArrayEncoder) error {
+				arr.AppendBool(true)
+				arr.AppendFloat64(1.5)
+				arr.AppendInt64(-2)
+				arr.AppendUint64(3)
+				arr.AppendString("s")
+				return nil
+			}),
+			"arr=[true 1.5 -2 3 s]",
+		},
+		{
+			"nested arrays",
+			ArrayMarshalerFunc(func(arr ArrayEncoder) error {
+				arr.AppendArray(ArrayMarshalerFunc(func(inner ArrayEncoder) error {
+					inner.AppendInt64(1)
+					inner.AppendInt64(2)
+					return nil
+				}))
+				arr.AppendArray(testUsers{{3, "baz"}})
+				return arr.AppendArray(testUsers{})
+			}),
+			"arr=[[1 2] [{id=3 name=baz}] []]",
+		},
+	}
+
+	for _, tt := range tests {
+		assertTextOutput(t, tt.desc, tt.expected, func(e Encoder) {
+			assert.NoError(t, e.(*textEncoder).AddArray("arr", tt.arr), "Unexpected error adding array %s.", tt.desc)
+		})
+	}
+}
+
+func TestTextEncoderArrayInObject(t *testing.T) {
+	withTextEncoder(func(enc *textEncoder) {
+		err := enc.AddMarshaler("team", LogMarshalerFunc(func(kv KeyValue) error {
+			kv.AddString("name", "core")
+			return kv.(*textEncoder).AddArray("members", testUsers{{1, "foo"}, {2, "bar"}})
+		}))
+		assert.NoError(t, err, "Unexpected error adding an object containing an array.")
+		assert.Equal(t, "team={name=core members=[{id=1 name=foo} {id=2 name=bar}]}", string(enc.bytes), "Unexpected output.")
+		if ctx := enc.Context(); assert.Len(t, ctx, 1, "Expected nested fields to be excluded from the context.") {
+			assert.Equal(t, "team", ctx[0].key, "Unexpected key in context.")
+		}
+	})
+}
+
+func TestTextEncoderAddArrayError(t *testing.T) {
+	fail := errors.New("fail")
+	withTextEncoder(func(enc *textEncoder) {
+		err := enc.AddArray("arr", ArrayMarshalerFunc(func(arr ArrayEncoder) error {
+			arr.AppendString("partial")
+			return arr.AppendObject(LogMarshalerFunc(func(KeyValue) error { return fail }))
+		}))
+		assert.Equal(t, fail, err, "Expected errors from elements to be returned.")
+		assert.Equal(t, "arr=[partial {}]", string(enc.bytes), "Expected brackets to be balanced after an error.")
+	})
+}
+
+func TestTextEncoderAddSlices(t *testing.T) {
+	tests := []struct {
+		desc     string
+		f        func(Encoder)
+		expected string
+	}{
+		{"nil strings", func(e Encoder) { e.AddStrings("k", nil) }, "k=[]"},
+		{"empty strings", func(e Encoder) { e.AddStrings("k", []string{}) }, "k=[]"},
+		{"strings", func(e Encoder) { e.AddStrings("k", []string{"a", "b", "c"}) }, "k=[a,b,c]"},
+		{"quoted strings", func(e Encoder) { e.AddStrings("k", []string{"a b", "", "c"}) }, `k=["a b","",c]`},
+		{"nil ints", func(e Encoder) { e.AddInts("k", nil) }, "k=[]"},
+		{"empty ints", func(e Encoder) { e.AddInts("k", []int{}) }, "k=[]"},
+		{"ints", func(e Encoder) { e.AddInts("k", []int{1, -2, 3000}) }, "k=[1,-2,3000]"},
+		{"lazy strings", func(e Encoder) { addValue(e, "k", []string{"x", "y"}) }, "k=[x,y]"},
+		{"lazy ints", func(e Encoder) { addValue(e, "k", []int{4, 5}) }, "k=[4,5]"},
+	}
+
+	for _, tt := range tests {
+		assertTextOutput(t, tt.desc, tt.expected, tt.f)
+	}
+}
+
+func TestOtherEncodersAddSlices(t *testing.T) {
+	json := NewJSONEncoder().(*jsonEncoder)
+	defer json.Free()
+	json.AddStrings("s", []string{"a", `b"c`})
+	json.AddInts("i", []int{1, 2})
+	json.AddStrings("nil", nil)
+	assert.Equal(t, `"s":["a","b\"c"],"i":[1,2],"nil":[]`, string(json.bytes), "Unexpected JSON slices.")
+
+	yaml := NewYAMLFlowEncoder().(*yamlFlowEncoder)
+	defer yaml.Free()
+	yaml.AddStrings("s", []string{"a", "b c", "true"})
+	yaml.AddInts("i", nil)
+	assert.Equal(t, `s: [a, b c, "true"], i: []`, string(yaml.bytes), "Unexpected YAML slices.")
+
+	qs := NewQueryStringEncoder().(*queryStringEncoder)
+	defer qs.Free()
+	qs.AddStrings("s", []string{"a", "b c"})
+	qs.AddInts("none", nil)
+	qs.AddInts("i", []int{1})
+	assert.Equal(t, "s=a&s=b+c&i=1", string(qs.bytes), "Unexpected query string slices.")
+
+	NullEncoder().AddStrings("s", []string{"a"})
+	NullEncoder().AddInts("i", []int{1})
+}
@@ -0,0 +1,109 @@
+// Copyright (c) 2016 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zap
+
+import "sync"
+
+// _maxCardinalityKeys bounds the number of keys whose values are tracked by
+// TextCardinalityHook. Keys first seen after the limit is reached are ignored.
+const _maxCardinalityKeys = 1024
+
+// cardinality counts the distinct values seen for each top-level key across
+// the entries written by an encoder and its clones.
+type cardinality struct {
+	sync.Mutex
+	threshold int
+	hook      func(key string, distinctSeen int)
+	// Distinct values for each key that hasn't yet exceeded the threshold, so
+	// no set grows beyond threshold values.
+	seen map[string]map[string]struct{}
+	// Keys that exceeded the threshold. Their values are no longer tracked.
+	exceeded map[string]struct{}
+}
+
+// observeCardinality records the values of the fields in final and calls the hook for
+// each key that exceeds the threshold for the first time.
+func (enc *textEncoder) observeCardinality(final *textEncoder, spans []fieldSpan) {
+	c := enc.cardinality
+	var fired []string
+	c.Lock()
+	for _, s := range spans {
+		if s.key == "_size" || (s.key == "id" && enc.entryIDs) {
+			// Generated fields are either unfilled or unique by design.
+			continue
+		}
+		if _, ok := c.exceeded[s.key]; ok {
+			continue
+		}
+		vals, ok := c.seen[s.key]
+		if !ok {
+			if len(c.seen)+len(c.exceeded) >= _maxCardinalityKeys {
+				continue
+			}
+			vals = make(map[string]struct{})
+			c.seen[s.key] = vals
+		}
+		val := final.bytes[s.start:s.end]
+		if _, ok := vals[string(val)]; ok {
+			continue
+		}
+		if len(vals) < c.threshold {
+			vals[string(val)] = struct{}{}
+			continue
+		}
+		delete(c.seen, s.key)
+		c.exceeded[s.key] = struct{}{}
+		fired = append(fired, s.key)
+	}
+	c.Unlock()
+
+	// Call the hook without holding the lock, since it may well log.
+	for _, key := range fired {
+		c.hook(key, c.threshold+1)
+	}
+}
+
+// TextCardinalityHook is a diagnostic for finding fields whose values are too
+// varied for downstream indexes, like a user ID logged where a low-cardinality
+// label was expected. The encoder and its clones count the distinct values
+// written for each top-level key, and hook is called once for each key the
+// first time it exceeds threshold distinct values, with the number of
+// distinct values seen so far.
+//
+// Memory use is bounded: at most threshold values are remembered per key,
+// values are forgotten once a key exceeds the threshold, and only the first
+// 1024 keys are tracked. Values are compared as rendered, so the same value
+// rendered differently counts twice. A non-positive threshold or nil hook
+// disables tracking.
+func TextCardinalityHook(threshold int, hook func(key string, distinctSeen int)) TextOption {
+	return textOptionFunc(func(enc *textEncoder) {
+		if threshold <= 0 || hook == nil {
+			enc.cardinality = nil
+			return
+		}
+		enc.cardinality = &cardinality{
+			threshold: threshold,
+			hook:      hook,
+			seen:      make(map[string]map[string]struct{}),
+			exceeded:  make(map[string]struct{}),
+		}
+	})
+}
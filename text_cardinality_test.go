@@ -0,0 +1,105 @@
+// Copyright (c) 2016 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zap
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type cardinalityReport struct {
+	key  string
+	seen int
+}
+
+func TestTextCardinalityHook(t *testing.T) {
+	var reports []cardinalityReport
+	enc := NewTextEncoder(TextNoTime(), TextCardinalityHook(5, func(key string, seen int) {
+		reports = append(reports, cardinalityReport{key, seen})
+	}))
+	defer enc.Free()
+	enc.AddString("service", "api")
+	sink := &testBuffer{}
+
+	write := func(userID int) {
+		clone := enc.Clone()
+		defer clone.Free()
+		clone.AddInt("user_id", userID)
+		clone.AddString("status", []string{"ok", "error"}[userID%2])
+		require.NoError(t, clone.WriteEntry(sink, "", "request", InfoLevel, epoch), "Unexpected failure writing entry.")
+	}
+
+	for i := 0; i < 5; i++ {
+		write(i)
+		write(i)
+	}
+	assert.Empty(t, reports, "Expected no reports at the threshold.")
+
+	write(5)
+	assert.Equal(t, []cardinalityReport{{"user_id", 6}}, reports, "Expected a report once a key exceeds the threshold.")
+
+	for i := 6; i < 100; i++ {
+		write(i)
+	}
+	assert.Len(t, reports, 1, "Expected each key to be reported only once.")
+	assert.Equal(t, "[I] request service=api user_id=99 status=error", sink.Lines()[len(sink.Lines())-1], "Expected output to be unaffected.")
+
+	c := enc.(*textEncoder).cardinality
+	assert.NotContains(t, c.seen, "user_id", "Expected values to be forgotten once a key exceeds the threshold.")
+	assert.Len(t, c.seen["status"], 2, "Unexpected distinct values for a low-cardinality key.")
+}
+
+func TestTextCardinalityHookBoundsKeys(t *testing.T) {
+	enc := NewTextEncoder(TextNoTime(), TextCardinalityHook(1, func(string, int) {}))
+	defer enc.Free()
+	for i := 0; i < _maxCardinalityKeys+10; i++ {
+		enc.AddString("key"+strconv.Itoa(i), "v")
+	}
+	require.NoError(t, enc.WriteEntry(&testBuffer{}, "", "", InfoLevel, epoch), "Unexpected failure writing entry.")
+	assert.Len(t, enc.(*textEncoder).cardinality.seen, _maxCardinalityKeys, "Expected the number of tracked keys to be bounded.")
+}
+
+func TestTextCardinalityHookIgnoresGeneratedFields(t *testing.T) {
+	var reported []string
+	enc := NewTextEncoder(TextNoTime(), TextEntryUUID(), TextEmitSize(), TextCardinalityHook(1, func(key string, _ int) {
+		reported = append(reported, key)
+	}))
+	defer enc.Free()
+	sink := &testBuffer{}
+	for i := 0; i < 3; i++ {
+		require.NoError(t, enc.WriteEntry(sink, "", "hello", InfoLevel, epoch), "Unexpected failure writing entry.")
+	}
+	assert.Empty(t, reported, "Expected entry IDs and sizes not to be reported.")
+}
+
+func TestTextCardinalityHookDisabled(t *testing.T) {
+	for _, opt := range []TextOption{
+		TextCardinalityHook(0, func(string, int) {}),
+		TextCardinalityHook(3, nil),
+	} {
+		enc := NewTextEncoder(opt)
+		assert.Nil(t, enc.(*textEncoder).cardinality, "Expected tracking to be disabled.")
+		enc.Free()
+	}
+}
@@ -0,0 +1,52 @@
+// Copyright (c) 2016 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zap
+
+import "math"
+
+// AddComplex128 adds a complex number in the form "(real+imagi)" (e.g.,
+// "(1.5-2i)"). Both parts are always written, even if one is zero, and
+// infinite or NaN parts are written as they are by AddFloat64 (e.g.,
+// "(+Inf+NaNi)").
+func (enc *textEncoder) AddComplex128(key string, val complex128) {
+	enc.addKey(key)
+	enc.bytes = appendComplex(enc.bytes, val, 64)
+}
+
+// AddComplex64 adds a complex number in the same form as AddComplex128.
+func (enc *textEncoder) AddComplex64(key string, val complex64) {
+	enc.addKey(key)
+	enc.bytes = appendComplex(enc.bytes, complex128(val), 32)
+}
+
+// appendComplex appends a complex number in the form "(real+imagi)", with
+// each part formatted at the given bit size.
+func appendComplex(buf []byte, val complex128, bitSize int) []byte {
+	buf = append(buf, '(')
+	buf = appendTextFloat(buf, real(val), 'f', -1, bitSize)
+	im := imag(val)
+	if math.IsNaN(im) || (!math.Signbit(im) && !math.IsInf(im, 1)) {
+		// Negative numbers and +Inf already carry a sign.
+		buf = append(buf, '+')
+	}
+	buf = appendTextFloat(buf, im, 'f', -1, bitSize)
+	return append(buf, "i)"...)
+}
@@ -0,0 +1,93 @@
+// Copyright (c) 2016 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zap
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTextAddComplex128(t *testing.T) {
+	inf, nan := math.Inf(1), math.NaN()
+	tests := []struct {
+		val      complex128
+		expected string
+	}{
+		{complex(1.5, -2), "c=(1.5-2i)"},
+		{complex(1.5, 2), "c=(1.5+2i)"},
+		{complex(3, 0), "c=(3+0i)"},
+		{complex(0, 3), "c=(0+3i)"},
+		{complex(0, math.Copysign(0, -1)), "c=(0-0i)"},
+		{complex(-1, -1), "c=(-1-1i)"},
+		{complex(inf, inf), "c=(+Inf+Infi)"},
+		{complex(-inf, -inf), "c=(-Inf-Infi)"},
+		{complex(nan, nan), "c=(NaN+NaNi)"},
+		{complex(1, nan), "c=(1+NaNi)"},
+	}
+
+	for _, tt := range tests {
+		assertTextOutput(t, "complex128", tt.expected, func(e Encoder) {
+			e.AddComplex128("c", tt.val)
+		})
+	}
+}
+
+func TestTextAddComplex64(t *testing.T) {
+	inf := float32(math.Inf(1))
+	tests := []struct {
+		val      complex64
+		expected string
+	}{
+		{complex(1.1, -2.2), "c=(1.1-2.2i)"},
+		{complex(0, 0), "c=(0+0i)"},
+		{complex(-inf, 1), "c=(-Inf+1i)"},
+		{complex(float32(math.NaN()), inf), "c=(NaN+Infi)"},
+	}
+
+	for _, tt := range tests {
+		assertTextOutput(t, "complex64", tt.expected, func(e Encoder) {
+			e.AddComplex64("c", tt.val)
+		})
+	}
+}
+
+func TestOtherEncodersAddComplex(t *testing.T) {
+	json := NewJSONEncoder().(*jsonEncoder)
+	defer json.Free()
+	json.AddComplex128("c", complex(1.5, -2))
+	json.AddComplex64("c64", complex(0, 1))
+	assert.Equal(t, `"c":"(1.5-2i)","c64":"(0+1i)"`, string(json.bytes), "Unexpected JSON complex numbers.")
+
+	yaml := NewYAMLFlowEncoder().(*yamlFlowEncoder)
+	defer yaml.Free()
+	yaml.AddComplex128("c", complex(1.5, -2))
+	assert.Equal(t, "c: (1.5-2i)", string(yaml.bytes), "Unexpected YAML complex number.")
+
+	qs := NewQueryStringEncoder().(*queryStringEncoder)
+	defer qs.Free()
+	qs.AddComplex64("c", complex(1, 2))
+	assert.Equal(t, "c=%281%2B2i%29", string(qs.bytes), "Unexpected query string complex number.")
+
+	NullEncoder().AddComplex128("c", 1)
+	NullEncoder().AddComplex64("c", 1)
+}
@@ -0,0 +1,52 @@
+// Copyright (c) 2016 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zap
+
+// AddComponent sets the component stamped on every entry under the
+// "component" key, which identifies the library or module that emitted it
+// independently of the logger's name. By default, the component replaces any
+// inherited from the encoder this one was cloned from; with the
+// TextJoinComponents option, it's appended to the inherited component instead
+// (e.g., "component=storage/cache").
+func (enc *textEncoder) AddComponent(name string) {
+	if enc.componentSep != "" && enc.component != "" {
+		enc.component += enc.componentSep + name
+		return
+	}
+	enc.component = name
+}
+
+// TextComponent stamps every entry with a "component" field, which is useful
+// for filtering the logs of a monorepo by the module that emitted them. Use
+// AddComponent to change the component of a cloned encoder.
+func TextComponent(name string) TextOption {
+	return textOptionFunc(func(enc *textEncoder) {
+		enc.AddComponent(name)
+	})
+}
+
+// TextJoinComponents makes AddComponent append to the inherited component,
+// separated by sep, rather than replacing it.
+func TextJoinComponents(sep string) TextOption {
+	return textOptionFunc(func(enc *textEncoder) {
+		enc.componentSep = sep
+	})
+}
@@ -0,0 +1,80 @@
+// Copyright (c) 2016 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zap
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTextComponent(t *testing.T) {
+	enc := NewTextEncoder(TextNoTime(), TextComponent("storage"))
+	defer enc.Free()
+	enc.AddString("user", "jane")
+
+	sink := &testBuffer{}
+	require.NoError(t, enc.WriteEntry(sink, "db", "hello", InfoLevel, epoch), "Unexpected failure writing entry.")
+	assert.Equal(t, "[I] db hello user=jane component=storage", sink.Stripped(), "Expected a component field distinct from the logger name.")
+}
+
+func TestTextComponentClones(t *testing.T) {
+	tests := []struct {
+		desc     string
+		opts     []TextOption
+		expected []string
+	}{
+		{
+			"override",
+			[]TextOption{TextNoTime(), TextComponent("storage")},
+			[]string{"[I] parent component=storage", "[I] child component=cache", "[I] grandchild component=lru"},
+		},
+		{
+			"join",
+			[]TextOption{TextNoTime(), TextComponent("storage"), TextJoinComponents("/")},
+			[]string{"[I] parent component=storage", "[I] child component=storage/cache", "[I] grandchild component=storage/cache/lru"},
+		},
+		{
+			"join without a parent component",
+			[]TextOption{TextNoTime(), TextJoinComponents("/")},
+			[]string{"[I] parent", "[I] child component=cache", "[I] grandchild component=cache/lru"},
+		},
+	}
+
+	for _, tt := range tests {
+		parent := NewTextEncoder(tt.opts...)
+		child := parent.Clone()
+		child.(*textEncoder).AddComponent("cache")
+		grandchild := child.Clone()
+		grandchild.(*textEncoder).AddComponent("lru")
+
+		sink := &testBuffer{}
+		require.NoError(t, parent.WriteEntry(sink, "", "parent", InfoLevel, epoch), "Unexpected failure writing entry.")
+		require.NoError(t, child.WriteEntry(sink, "", "child", InfoLevel, epoch), "Unexpected failure writing entry.")
+		require.NoError(t, grandchild.WriteEntry(sink, "", "grandchild", InfoLevel, epoch), "Unexpected failure writing entry.")
+		assert.Equal(t, tt.expected, sink.Lines(), "Unexpected components with %s.", tt.desc)
+
+		for _, enc := range []Encoder{parent, child, grandchild} {
+			enc.Free()
+		}
+	}
+}
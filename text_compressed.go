@@ -0,0 +1,67 @@
+// Copyright (c) 2016 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zap
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"sync"
+)
+
+// _minCompressedSize is the smallest value that AddCompressed compresses;
+// gzip's overhead outweighs its savings for smaller values.
+const _minCompressedSize = 512
+
+var _gzipPool = sync.Pool{New: func() interface{} {
+	return gzip.NewWriter(nil)
+}}
+
+// AddCompressed adds a potentially large value (e.g., a serialized payload),
+// gzipping it and writing it base64-encoded with a "gz:" prefix so that
+// downstream tools can detect and decompress it. Values smaller than 512 bytes,
+// and values that don't shrink when compressed, are added as with AddBytes.
+func (enc *textEncoder) AddCompressed(key string, val []byte) {
+	if len(val) < _minCompressedSize {
+		enc.AddBytes(key, val)
+		return
+	}
+
+	var buf bytes.Buffer
+	zw := _gzipPool.Get().(*gzip.Writer)
+	zw.Reset(&buf)
+	_, err := zw.Write(val)
+	if err == nil {
+		err = zw.Close()
+	}
+	_gzipPool.Put(zw)
+	n := base64.StdEncoding.EncodedLen(buf.Len())
+	if err != nil || len("gz:")+n >= len(val) {
+		enc.AddBytes(key, val)
+		return
+	}
+
+	enc.addKey(key)
+	enc.bytes = append(enc.bytes, "gz:"...)
+	start := len(enc.bytes)
+	enc.bytes = append(enc.bytes, make([]byte, n)...)
+	base64.StdEncoding.Encode(enc.bytes[start:], buf.Bytes())
+}
@@ -0,0 +1,87 @@
+// Copyright (c) 2016 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zap
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/rand"
+	"encoding/base64"
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTextAddCompressed(t *testing.T) {
+	large := bytes.Repeat([]byte(`{"user":"jane","action":"login"}`), 100)
+
+	withTextEncoder(func(enc *textEncoder) {
+		enc.AddCompressed("payload", large)
+		out := string(enc.bytes)
+		require.True(t, strings.HasPrefix(out, "payload=gz:"), "Expected large values to be compressed.")
+		assert.True(t, len(out) < len(large), "Expected compression to shrink the value.")
+
+		compressed, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(out, "payload=gz:"))
+		require.NoError(t, err, "Expected valid base64.")
+		zr, err := gzip.NewReader(bytes.NewReader(compressed))
+		require.NoError(t, err, "Expected a valid gzip stream.")
+		decompressed, err := ioutil.ReadAll(zr)
+		require.NoError(t, err, "Unexpected error decompressing.")
+		assert.Equal(t, large, decompressed, "Expected the value to round-trip through decompression.")
+	})
+}
+
+func TestTextAddCompressedPlain(t *testing.T) {
+	random := make([]byte, 1024)
+	_, err := rand.Read(random)
+	require.NoError(t, err, "Unexpected error reading random bytes.")
+
+	tests := []struct {
+		desc string
+		val  []byte
+	}{
+		{"small", []byte("tiny")},
+		{"just below the threshold", bytes.Repeat([]byte{'a'}, _minCompressedSize-1)},
+		{"incompressible", random},
+	}
+
+	for _, tt := range tests {
+		withTextEncoder(func(enc *textEncoder) {
+			enc.AddCompressed("payload", tt.val)
+			plain := NewTextEncoder().(*textEncoder)
+			defer plain.Free()
+			plain.AddBytes("payload", tt.val)
+			assert.Equal(t, string(plain.bytes), string(enc.bytes), "Expected %s values to be added as plain bytes.", tt.desc)
+		})
+	}
+}
+
+func TestTextEncoderAddCompressed(t *testing.T) {
+	enc := NewTextEncoder(TextNoTime()).(TextEncoder)
+	defer enc.Free()
+	enc.AddCompressed("payload", bytes.Repeat([]byte("a"), 1024))
+	sink := &testBuffer{}
+	require.NoError(t, enc.WriteEntry(sink, "", "hello", InfoLevel, epoch), "Unexpected failure writing entry.")
+	assert.True(t, strings.HasPrefix(sink.Stripped(), "[I] hello payload=gz:"), "Expected the value to be compressed, got %q.", sink.Stripped())
+}
@@ -0,0 +1,90 @@
+// Copyright (c) 2016 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zap
+
+// A contextField records a top-level field added to a text encoder. Fields
+// added with methods that have a matching Field constructor are recorded
+// as-is; others are recovered from the rendered value as strings.
+type contextField struct {
+	field Field
+	typed bool
+	// Offsets of the rendered value in the encoder's buffer, used for untyped
+	// fields. An end of -1 means that the value extends to the end of the
+	// buffer.
+	start, end int
+}
+
+// beginContextField records the start of a top-level field. It's called by
+// addKey after the field's key has been written.
+func (enc *textEncoder) beginContextField(key string, dropped bool) {
+	if dropped {
+		return
+	}
+	enc.context = append(enc.context, contextField{
+		field: Field{key: key},
+		start: len(enc.bytes),
+		end:   -1,
+	})
+	enc.ctxOpen = true
+}
+
+// endContextField marks the end of the most recently added top-level field.
+func (enc *textEncoder) endContextField() {
+	if !enc.ctxOpen {
+		return
+	}
+	enc.context[len(enc.context)-1].end = len(enc.bytes)
+	enc.ctxOpen = false
+}
+
+// recordField replaces the most recently added top-level field with a typed
+// Field. Calls made while marshaling nested objects are ignored.
+func (enc *textEncoder) recordField(f Field) {
+	if enc.depth > 0 || len(enc.context) == 0 || !enc.ctxOpen {
+		return
+	}
+	last := &enc.context[len(enc.context)-1]
+	last.field = f
+	last.typed = true
+}
+
+// Context returns the fields accumulated by the encoder and all the encoders
+// it was cloned from, in the order they were added. It's intended for
+// debugging where a logger's fields came from. Fields that don't correspond
+// to a Field constructor, like those added with AddEnum, are returned as
+// strings containing their rendered values; fields added with AddLazy aren't
+// included, since they're only evaluated when entries are written.
+func (enc *textEncoder) Context() []Field {
+	fields := make([]Field, 0, len(enc.context))
+	kept := enc.keptFields()
+	for _, cf := range enc.context {
+		if cf.typed {
+			fields = append(fields, cf.field)
+			continue
+		}
+		end := cf.end
+		if end < 0 {
+			end = len(kept)
+		}
+		fields = append(fields, String(cf.field.key, string(kept[cf.start:end])))
+	}
+	return fields
+}
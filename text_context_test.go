@@ -0,0 +1,80 @@
+// Copyright (c) 2016 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zap
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTextEncoderContext(t *testing.T) {
+	root := NewTextEncoder()
+	defer root.Free()
+	root.AddString("service", "api")
+	root.AddMarshaler("obj", loggable{true})
+
+	child := root.Clone().(*textEncoder)
+	defer child.Free()
+	child.AddInt("attempt", 2)
+	child.AddEnum("state", 1, map[int]string{1: "Running"})
+
+	grandchild := child.Clone().(*textEncoder)
+	defer grandchild.Free()
+	grandchild.AddBool("retry", true)
+	grandchild.AddFloat64("ratio", 0.5)
+
+	assert.Equal(t, []Field{
+		String("service", "api"),
+		Marshaler("obj", loggable{true}),
+		Int("attempt", 2),
+		String("state", "Running(1)"),
+		Bool("retry", true),
+		Float64("ratio", 0.5),
+	}, grandchild.Context(), "Unexpected context after cloning twice.")
+
+	assert.Equal(t, []Field{
+		String("service", "api"),
+		Marshaler("obj", loggable{true}),
+		Int("attempt", 2),
+		String("state", "Running(1)"),
+	}, child.Context(), "Adding fields to a clone shouldn't change the parent's context.")
+
+	assert.Equal(t, []Field{String("service", "api"), Marshaler("obj", loggable{true})}, root.(*textEncoder).Context(), "Unexpected root context.")
+}
+
+func TestTextEncoderContextUntyped(t *testing.T) {
+	enc := NewTextEncoder().(*textEncoder)
+	defer enc.Free()
+	enc.AddEnum("first", 3, nil)
+	enc.AddEnum("second", 1, map[int]string{1: "One"})
+	assert.Equal(t, []Field{String("first", "3"), String("second", "One(1)")}, enc.Context(), "Expected untyped fields as rendered strings.")
+}
+
+func TestTextEncoderContextCollapsed(t *testing.T) {
+	enc := NewTextEncoder(TextCollapseRepeatedKeys(1)).(*textEncoder)
+	defer enc.Free()
+	enc.AddString("item", "a")
+	enc.AddString("item", "b")
+	enc.AddEnum("state", 1, nil)
+	enc.AddEnum("state", 2, nil)
+	assert.Equal(t, []Field{String("item", "a"), String("state", "1")}, enc.Context(), "Expected collapsed fields to be omitted.")
+}
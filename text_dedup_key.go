@@ -0,0 +1,135 @@
+// Copyright (c) 2016 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zap
+
+import (
+	"strconv"
+	"sync"
+	"time"
+)
+
+// _maxDedupKeys bounds the number of keys remembered by TextDedupKey.
+const _maxDedupKeys = 4096
+
+// A dedupWindow tracks the entries with one dedup key.
+type dedupWindow struct {
+	// When the first entry in the window was written.
+	start      time.Time
+	suppressed int64
+}
+
+// dedupCache is shared by an encoder and its clones.
+type dedupCache struct {
+	sync.Mutex
+	ttl     time.Duration
+	key     func(lvl Level, msg string, fields []Field) string
+	windows map[string]*dedupWindow
+}
+
+// admit reports whether an entry with the given key should be written. If it
+// should, it also returns the number of entries with the key that were
+// suppressed in the previous window.
+func (c *dedupCache) admit(key string) (suppressed int64, ok bool) {
+	now := _timeNow()
+	c.Lock()
+	defer c.Unlock()
+	if w, found := c.windows[key]; found {
+		if now.Sub(w.start) < c.ttl {
+			w.suppressed++
+			return 0, false
+		}
+		suppressed = w.suppressed
+		w.start, w.suppressed = now, 0
+		return suppressed, true
+	}
+	if len(c.windows) >= _maxDedupKeys {
+		c.evict(now)
+	}
+	c.windows[key] = &dedupWindow{start: now}
+	return 0, true
+}
+
+// evict removes expired windows or, if none have expired, the oldest window.
+// The caller must hold the lock.
+func (c *dedupCache) evict(now time.Time) {
+	var oldest string
+	for k, w := range c.windows {
+		if now.Sub(w.start) >= c.ttl {
+			delete(c.windows, k)
+			continue
+		}
+		if oldest == "" || w.start.Before(c.windows[oldest].start) {
+			oldest = k
+		}
+	}
+	if len(c.windows) >= _maxDedupKeys {
+		delete(c.windows, oldest)
+	}
+}
+
+// dedup applies the TextDedupKey option, if any.
+func (enc *textEncoder) dedup(lvl Level, msg string) (suppressed int64, ok bool) {
+	if enc.dedupCache == nil {
+		return 0, true
+	}
+	key := enc.dedupCache.key(lvl, msg, enc.Context())
+	if key == "" {
+		return 0, true
+	}
+	return enc.dedupCache.admit(key)
+}
+
+// addSuppressedSummary adds a warning-level line reporting how many duplicates
+// of the entry that follows it were suppressed, without a trailing newline.
+func (enc *textEncoder) addSuppressedSummary(final *textEncoder, suppressed int64, t time.Time) {
+	enc.addLevel(final, WarnLevel)
+	enc.addTime(final, t)
+	final.bytes = append(final.bytes, " suppressed "...)
+	final.bytes = strconv.AppendInt(final.bytes, suppressed, 10)
+	final.bytes = append(final.bytes, " duplicates"...)
+}
+
+// TextDedupKey suppresses duplicate entries, which is useful for logs that
+// feed alerting. The key function computes a deduplication key from each
+// entry's level, message, and fields (as returned by Context); entries with
+// the same key as an entry written less than ttl earlier are dropped. When
+// the key's window expires, the next entry with the key is written, preceded
+// by a warning-level summary line if any duplicates were suppressed (e.g.,
+// "suppressed 12 duplicates"). Entries with an empty key are always written.
+//
+// The encoder and its clones share a cache of up to 4096 keys; when it's
+// full, expired keys are evicted first and then the oldest, whose suppressed
+// counts are never reported. Since fields are collected for the key function,
+// this option allocates for every entry. A non-positive ttl or nil key
+// function disables deduplication.
+func TextDedupKey(ttl time.Duration, key func(lvl Level, msg string, fields []Field) string) TextOption {
+	return textOptionFunc(func(enc *textEncoder) {
+		if ttl <= 0 || key == nil {
+			enc.dedupCache = nil
+			return
+		}
+		enc.dedupCache = &dedupCache{
+			ttl:     ttl,
+			key:     key,
+			windows: make(map[string]*dedupWindow),
+		}
+	})
+}
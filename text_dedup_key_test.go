@@ -0,0 +1,158 @@
+// Copyright (c) 2016 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zap
+
+import (
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// alertKey deduplicates entries by message and the value of the host field.
+func alertKey(_ Level, msg string, fields []Field) string {
+	for _, f := range fields {
+		if f.key == "host" {
+			return msg + "@" + f.str
+		}
+	}
+	return msg
+}
+
+func TestTextDedupKey(t *testing.T) {
+	withFakeClock(func(advance func(time.Duration)) {
+		enc := NewTextEncoder(TextNoTime(), TextDedupKey(time.Minute, alertKey))
+		defer enc.Free()
+		sink := &testBuffer{}
+		write := func(host, msg string) {
+			clone := enc.Clone()
+			defer clone.Free()
+			clone.AddString("host", host)
+			require.NoError(t, clone.WriteEntry(sink, "", msg, ErrorLevel, epoch), "Unexpected failure writing entry.")
+		}
+
+		write("web-1", "disk full")
+		write("web-1", "disk full")
+		write("web-2", "disk full")
+		write("web-1", "cpu hot")
+		advance(30 * time.Second)
+		write("web-1", "disk full")
+		write("web-2", "disk full")
+		assert.Equal(t, []string{
+			"[E] disk full host=web-1",
+			"[E] disk full host=web-2",
+			"[E] cpu hot host=web-1",
+		}, sink.Lines(), "Expected duplicates within the TTL to be suppressed.")
+
+		sink.Reset()
+		advance(30 * time.Second)
+		write("web-1", "disk full")
+		write("web-2", "disk full")
+		write("web-1", "cpu hot")
+		write("web-1", "disk full")
+		assert.Equal(t, []string{
+			"[W] suppressed 2 duplicates",
+			"[E] disk full host=web-1",
+			"[W] suppressed 1 duplicates",
+			"[E] disk full host=web-2",
+			"[E] cpu hot host=web-1",
+		}, sink.Lines(), "Expected entries outside the TTL to be written with a summary.")
+	})
+}
+
+func TestTextDedupKeyEmptyKey(t *testing.T) {
+	enc := NewTextEncoder(TextNoTime(), TextDedupKey(time.Hour, func(Level, string, []Field) string { return "" }))
+	defer enc.Free()
+	sink := &testBuffer{}
+	for i := 0; i < 3; i++ {
+		require.NoError(t, enc.WriteEntry(sink, "", "hello", InfoLevel, epoch), "Unexpected failure writing entry.")
+	}
+	assert.Len(t, sink.Lines(), 3, "Expected entries with an empty key to be written.")
+}
+
+func TestTextDedupKeyBounded(t *testing.T) {
+	withFakeClock(func(advance func(time.Duration)) {
+		enc := NewTextEncoder(TextNoTime(), TextDedupKey(time.Minute, func(_ Level, msg string, _ []Field) string { return msg }))
+		defer enc.Free()
+		cache := enc.(*textEncoder).dedupCache
+		for i := 0; i < _maxDedupKeys+10; i++ {
+			require.NoError(t, enc.WriteEntry(Discard, "", strconv.Itoa(i), InfoLevel, epoch), "Unexpected failure writing entry.")
+			advance(time.Millisecond)
+		}
+		assert.Len(t, cache.windows, _maxDedupKeys, "Expected the cache to be bounded.")
+		assert.NotContains(t, cache.windows, "0", "Expected the oldest key to be evicted.")
+
+		advance(time.Minute)
+		require.NoError(t, enc.WriteEntry(Discard, "", "new", InfoLevel, epoch), "Unexpected failure writing entry.")
+		assert.Len(t, cache.windows, 1, "Expected expired keys to be evicted first.")
+	})
+}
+
+func TestTextDedupKeyConcurrency(t *testing.T) {
+	enc := NewTextEncoder(TextNoTime(), TextDedupKey(time.Hour, alertKey))
+	defer enc.Free()
+	sink := newLockedWriteSyncer(&testBuffer{})
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			clone := enc.Clone()
+			defer clone.Free()
+			for j := 0; j < 100; j++ {
+				clone.WriteEntry(sink, "", "msg"+strconv.Itoa(j%5), InfoLevel, epoch)
+			}
+		}()
+	}
+	wg.Wait()
+	lines := sink.(*lockedWriteSyncer).ws.(*testBuffer).Lines()
+	assert.Len(t, lines, 5, "Expected exactly one entry per key.")
+}
+
+func TestTextDedupKeyANSI(t *testing.T) {
+	withFakeClock(func(advance func(time.Duration)) {
+		enc := NewANSIEncoder(AnsiTextOption(TextNoTime()), AnsiTextOption(TextDedupKey(time.Second, alertKey)))
+		defer enc.Free()
+		sink := &testBuffer{}
+		for i := 0; i < 3; i++ {
+			require.NoError(t, enc.WriteEntry(sink, "", "hello", InfoLevel, epoch), "Unexpected failure writing entry.")
+		}
+		advance(time.Second)
+		require.NoError(t, enc.WriteEntry(sink, "", "hello", InfoLevel, epoch), "Unexpected failure writing entry.")
+		lines := sink.Lines()
+		require.Len(t, lines, 3, "Expected a summary before the entry after the TTL.")
+		assert.Equal(t, "[W] suppressed 2 duplicates", stripEscapes(lines[1]), "Unexpected summary.")
+	})
+}
+
+func TestTextDedupKeyDisabled(t *testing.T) {
+	for _, opt := range []TextOption{
+		TextDedupKey(0, alertKey),
+		TextDedupKey(time.Second, nil),
+	} {
+		enc := NewTextEncoder(opt)
+		assert.Nil(t, enc.(*textEncoder).dedupCache, "Expected deduplication to be disabled.")
+		enc.Free()
+	}
+}
@@ -0,0 +1,89 @@
+// Copyright (c) 2016 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zap
+
+// A DedupeStrategy controls which field TextDedupeKeys keeps when an entry
+// has several top-level fields with the same key.
+type DedupeStrategy int
+
+const (
+	// DedupeNone keeps every field. It's the default.
+	DedupeNone DedupeStrategy = iota
+	// DedupeKeepFirst keeps the first field with each key, so fields added to
+	// a logger's context take precedence over fields added later.
+	DedupeKeepFirst
+	// DedupeKeepLast keeps the last field with each key, so fields added
+	// later override fields added to a logger's context.
+	DedupeKeepLast
+)
+
+// dedupe removes fields from final whose keys are repeated, as directed by
+// the encoder's strategy. It returns the spans of the remaining fields,
+// updated to match the rewritten entry.
+func (enc *textEncoder) dedupe(final *textEncoder, spans []fieldSpan) []fieldSpan {
+	keep := make(map[string]int, len(spans))
+	for i, s := range spans {
+		if _, ok := keep[s.key]; !ok || enc.dedupeKeys == DedupeKeepLast {
+			keep[s.key] = i
+		}
+	}
+	if len(keep) == len(spans) {
+		return spans
+	}
+
+	kept := spans[:0]
+	out, last := 0, 0
+	for i, s := range spans {
+		// The size's value is filled in once the entry is complete, so it's
+		// never removed.
+		if keep[s.key] != i && s.key != "_size" {
+			// Every field is preceded by a single space, which is removed
+			// along with the key, the separator, and the value.
+			from := s.start - len(enc.keyValueSep()) - enc.keyLen(s.key) - 1
+			out += copy(final.bytes[out:], final.bytes[last:from])
+			last = s.end
+			continue
+		}
+		// Shift the field back by the number of bytes removed before it.
+		s.start -= last - out
+		s.end -= last - out
+		kept = append(kept, s)
+	}
+	out += copy(final.bytes[out:], final.bytes[last:])
+	final.bytes = final.bytes[:out]
+	return kept
+}
+
+// TextDedupeKeys removes fields whose keys are repeated in an entry, which
+// happens when a key is added both to a logger's context and at the log site
+// (e.g., "user=a ... user=b"). Downstream logfmt parsers would otherwise keep
+// one of them arbitrarily. Only top-level keys are deduplicated; keys in
+// nested objects are left alone.
+//
+// Since fields are rendered as they're added, duplicates can only be found
+// once an entry is complete. Deduplicating costs a map of the entry's keys,
+// and rewriting the entry's fields when it has duplicates, for every entry
+// written. The default, DedupeNone, has no cost.
+func TextDedupeKeys(strategy DedupeStrategy) TextOption {
+	return textOptionFunc(func(enc *textEncoder) {
+		enc.dedupeKeys = strategy
+	})
+}
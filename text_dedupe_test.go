@@ -0,0 +1,140 @@
+// Copyright (c) 2016 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zap
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTextDedupeKeys(t *testing.T) {
+	tests := []struct {
+		strategy DedupeStrategy
+		expected string
+	}{
+		{DedupeNone, "[I] hello user=a role=admin user=b count=1 user=c"},
+		{DedupeKeepFirst, "[I] hello user=a role=admin count=1"},
+		{DedupeKeepLast, "[I] hello role=admin count=1 user=c"},
+	}
+
+	for _, tt := range tests {
+		enc := NewTextEncoder(TextNoTime(), TextDedupeKeys(tt.strategy))
+		enc.AddString("user", "a")
+		enc.AddString("role", "admin")
+		clone := enc.Clone()
+		clone.AddString("user", "b")
+		clone.AddInt("count", 1)
+		clone.AddString("user", "c")
+		sink := &testBuffer{}
+		require.NoError(t, clone.WriteEntry(sink, "", "hello", InfoLevel, epoch), "Unexpected failure writing entry.")
+		assert.Equal(t, tt.expected, sink.Stripped(), "Unexpected output with strategy %v.", tt.strategy)
+		clone.Free()
+		enc.Free()
+	}
+}
+
+func TestTextDedupeKeysEdgeCases(t *testing.T) {
+	tests := []struct {
+		desc     string
+		opts     []TextOption
+		f        func(Encoder)
+		expected string
+	}{
+		{
+			desc: "no duplicates",
+			f: func(e Encoder) {
+				e.AddString("a", "1")
+				e.AddString("b", "2")
+			},
+			expected: "[I] msg a=1 b=2",
+		},
+		{
+			desc: "only field duplicated",
+			f: func(e Encoder) {
+				e.AddString("a", "1")
+				e.AddString("a", "2")
+			},
+			expected: "[I] msg a=2",
+		},
+		{
+			desc: "quoted keys and nested objects",
+			f: func(e Encoder) {
+				e.AddString("a key", "x y")
+				e.AddMarshaler("obj", LogMarshalerFunc(func(kv KeyValue) error {
+					kv.AddString("a key", "nested")
+					return nil
+				}))
+				e.AddString("a key", "z")
+			},
+			expected: `[I] msg obj={"a key"=nested} "a key"=z`,
+		},
+		{
+			desc: "header separator",
+			opts: []TextOption{TextHeaderFieldSeparator(" | ")},
+			f: func(e Encoder) {
+				e.AddString("a", "1")
+				e.AddString("a", "2")
+			},
+			expected: "[I] msg | a=2",
+		},
+		{
+			desc: "delta fields",
+			opts: []TextOption{TextDeltaFields()},
+			f: func(e Encoder) {
+				e.AddString("a", "1")
+				e.AddString("b", "2")
+				e.AddString("a", "3")
+			},
+			expected: "[I] msg b=2 a=3",
+		},
+		{
+			desc: "escaped keys",
+			opts: []TextOption{TextQuoteStrings(false), TextEscapeNewlines()},
+			f: func(e Encoder) {
+				e.AddString("a\nb", "a")
+				e.AddString("a\nb", "b")
+			},
+			expected: `[I] msg a\nb=b`,
+		},
+	}
+
+	for _, tt := range tests {
+		opts := append([]TextOption{TextNoTime(), TextDedupeKeys(DedupeKeepLast)}, tt.opts...)
+		enc := NewTextEncoder(opts...)
+		tt.f(enc)
+		sink := &testBuffer{}
+		require.NoError(t, enc.WriteEntry(sink, "", "msg", InfoLevel, epoch), "Unexpected failure writing entry.")
+		assert.Equal(t, tt.expected, sink.Stripped(), "Unexpected output for %s.", tt.desc)
+		enc.Free()
+	}
+}
+
+func TestTextDedupeKeysWithSize(t *testing.T) {
+	enc := NewTextEncoder(TextNoTime(), TextEmitSize(), TextDedupeKeys(DedupeKeepFirst))
+	defer enc.Free()
+	enc.AddString("a", "1")
+	enc.AddString("a", "2")
+	sink := &testBuffer{}
+	require.NoError(t, enc.WriteEntry(sink, "", "msg", InfoLevel, epoch), "Unexpected failure writing entry.")
+	assert.Equal(t, "[I] msg a=1 _size=21\n", sink.String(), "Expected the size to match the deduplicated entry.")
+}
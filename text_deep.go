@@ -0,0 +1,240 @@
+// Copyright (c) 2016 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zap
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// _defaultTruncationMarker is written in place of content that the encoder
+// cuts short.
+const _defaultTruncationMarker = "…"
+
+// _debugOnlyPlaceholder is written in place of AddDeep's value in release
+// builds.
+const _debugOnlyPlaceholder = "<debug-only>"
+
+// AddDeep serializes an arbitrary value by walking structs, maps, slices, and
+// pointers with reflection, descending at most maxDepth levels; deeper values
+// are elided and replaced with the truncation marker (by default, "…").
+// Pointers and maps that refer back to a value that's already being
+// serialized are rendered as "<cycle>". Durations, including those in
+// unexported fields, are rendered in Go's human-readable form. Strings,
+// including the results of Error and String methods, are quoted and escaped
+// under the same rules as AddString.
+//
+// AddDeep is intended as a debugging aid: it's even slower and more
+// allocation-heavy than AddObject. Unless zap is built with the zapdebug tag,
+// the value isn't serialized: the field is written with the placeholder
+// "<debug-only>", so it's clear that something was left out.
+func (enc *textEncoder) AddDeep(key string, val interface{}, maxDepth int) {
+	enc.addKey(key)
+	if !_debugHelpers {
+		enc.bytes = append(enc.bytes, _debugOnlyPlaceholder...)
+		return
+	}
+	w := deepWalker{
+		enc:      enc,
+		maxDepth: maxDepth,
+		visiting: make(map[uintptr]struct{}),
+	}
+	enc.bytes = w.appendValue(enc.bytes, reflect.ValueOf(val), 0)
+}
+
+type deepWalker struct {
+	// The encoder supplies the truncation marker and string quoting rules.
+	enc      *textEncoder
+	maxDepth int
+	// Addresses of the pointers, maps, and slices on the current path.
+	visiting map[uintptr]struct{}
+}
+
+func (w deepWalker) appendValue(buf []byte, v reflect.Value, depth int) []byte {
+	if !v.IsValid() {
+		return append(buf, "<nil>"...)
+	}
+	if v.Type() == _durationType {
+		// Handled before the Stringer check so that unexported fields, which
+		// can't be converted to interfaces, are human-readable too.
+		return appendDuration(buf, time.Duration(v.Int()))
+	}
+	if v.CanInterface() {
+		switch x := v.Interface().(type) {
+		case error:
+			if !isNilValue(v) {
+				return w.enc.appendString(buf, x.Error())
+			}
+		case fmt.Stringer:
+			if !isNilValue(v) {
+				return w.enc.appendString(buf, x.String())
+			}
+		}
+	}
+
+	switch v.Kind() {
+	case reflect.Bool:
+		return strconv.AppendBool(buf, v.Bool())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.AppendInt(buf, v.Int(), 10)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return strconv.AppendUint(buf, v.Uint(), 10)
+	case reflect.Float32:
+		return strconv.AppendFloat(buf, v.Float(), 'g', -1, 32)
+	case reflect.Float64:
+		return strconv.AppendFloat(buf, v.Float(), 'g', -1, 64)
+	case reflect.String:
+		return w.enc.appendString(buf, v.String())
+	case reflect.Interface:
+		if v.IsNil() {
+			return append(buf, "<nil>"...)
+		}
+		return w.appendValue(buf, v.Elem(), depth)
+	case reflect.Ptr:
+		if v.IsNil() {
+			return append(buf, "<nil>"...)
+		}
+		if !w.enter(v.Pointer()) {
+			return append(buf, "<cycle>"...)
+		}
+		buf = append(buf, '&')
+		buf = w.appendValue(buf, v.Elem(), depth)
+		w.leave(v.Pointer())
+		return buf
+	case reflect.Struct:
+		if depth >= w.maxDepth {
+			return append(buf, w.enc.truncMarker...)
+		}
+		buf = append(buf, '{')
+		t := v.Type()
+		for i := 0; i < v.NumField(); i++ {
+			if i > 0 {
+				buf = append(buf, ' ')
+			}
+			buf = append(buf, t.Field(i).Name...)
+			buf = append(buf, ':')
+			buf = w.appendValue(buf, v.Field(i), depth+1)
+		}
+		return append(buf, '}')
+	case reflect.Map:
+		if v.IsNil() {
+			return append(buf, "map[]"...)
+		}
+		if depth >= w.maxDepth {
+			return append(buf, w.enc.truncMarker...)
+		}
+		if !w.enter(v.Pointer()) {
+			return append(buf, "<cycle>"...)
+		}
+		buf = append(buf, "map["...)
+		buf = w.appendMapEntries(buf, v, depth)
+		w.leave(v.Pointer())
+		return append(buf, ']')
+	case reflect.Slice:
+		if depth >= w.maxDepth && v.Len() > 0 {
+			return append(buf, w.enc.truncMarker...)
+		}
+		if v.Len() > 0 && !w.enter(v.Pointer()) {
+			return append(buf, "<cycle>"...)
+		}
+		buf = w.appendElems(buf, v, depth)
+		if v.Len() > 0 {
+			w.leave(v.Pointer())
+		}
+		return buf
+	case reflect.Array:
+		if depth >= w.maxDepth && v.Len() > 0 {
+			return append(buf, w.enc.truncMarker...)
+		}
+		return w.appendElems(buf, v, depth)
+	default:
+		// Channels, functions, and unsafe pointers have no structure to walk.
+		return append(buf, fmt.Sprint(v)...)
+	}
+}
+
+func (w deepWalker) appendElems(buf []byte, v reflect.Value, depth int) []byte {
+	buf = append(buf, '[')
+	for i := 0; i < v.Len(); i++ {
+		if i > 0 {
+			buf = append(buf, ' ')
+		}
+		buf = w.appendValue(buf, v.Index(i), depth+1)
+	}
+	return append(buf, ']')
+}
+
+func (w deepWalker) appendMapEntries(buf []byte, v reflect.Value, depth int) []byte {
+	// Sort the entries by their rendered keys so that output is deterministic.
+	entries := make(deepMapEntries, 0, v.Len())
+	for _, k := range v.MapKeys() {
+		entries = append(entries, deepMapEntry{
+			key: string(w.appendValue(nil, k, depth+1)),
+			val: v.MapIndex(k),
+		})
+	}
+	sort.Sort(entries)
+	for i, e := range entries {
+		if i > 0 {
+			buf = append(buf, ' ')
+		}
+		buf = append(buf, e.key...)
+		buf = append(buf, ':')
+		buf = w.appendValue(buf, e.val, depth+1)
+	}
+	return buf
+}
+
+// enter marks an address as being on the current path, returning false if it
+// already was.
+func (w deepWalker) enter(addr uintptr) bool {
+	if _, ok := w.visiting[addr]; ok {
+		return false
+	}
+	w.visiting[addr] = struct{}{}
+	return true
+}
+
+func (w deepWalker) leave(addr uintptr) {
+	delete(w.visiting, addr)
+}
+
+type deepMapEntry struct {
+	key string
+	val reflect.Value
+}
+
+type deepMapEntries []deepMapEntry
+
+func (es deepMapEntries) Len() int           { return len(es) }
+func (es deepMapEntries) Less(i, j int) bool { return es[i].key < es[j].key }
+func (es deepMapEntries) Swap(i, j int)      { es[i], es[j] = es[j], es[i] }
+
+func isNilValue(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface, reflect.Map, reflect.Slice, reflect.Func, reflect.Chan:
+		return v.IsNil()
+	}
+	return false
+}
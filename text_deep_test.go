@@ -0,0 +1,121 @@
+// Copyright (c) 2016 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+//go:build zapdebug
+// +build zapdebug
+
+package zap
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type deepNode struct {
+	Name string
+	Next *deepNode
+}
+
+type deepTimeout struct {
+	Op      string
+	Timeout time.Duration
+	elapsed time.Duration
+}
+
+func TestTextAddDeep(t *testing.T) {
+	cyclic := &deepNode{Name: "a"}
+	cyclic.Next = &deepNode{Name: "b", Next: cyclic}
+
+	shared := &deepNode{Name: "leaf"}
+	nested := map[string]interface{}{
+		"ints":   []int{1, 2},
+		"nested": map[string][]string{"x": {"y", "z"}},
+		"nil":    nil,
+	}
+
+	tests := []struct {
+		desc     string
+		val      interface{}
+		depth    int
+		expected string
+	}{
+		{"nil", nil, 3, "k=<nil>"},
+		{"scalar", 42, 0, "k=42"},
+		{"cyclic struct", cyclic, 5, "k=&{Name:a Next:&{Name:b Next:<cycle>}}"},
+		{"shared pointers", []*deepNode{shared, shared}, 3, "k=[&{Name:leaf Next:<nil>} &{Name:leaf Next:<nil>}]"},
+		{"nested map and slice", nested, 5, "k=map[ints:[1 2] nested:map[x:[y z]] nil:<nil>]"},
+		{"depth-limited", nested, 1, "k=map[ints:… nested:… nil:<nil>]"},
+		{"depth zero", []int{1}, 0, "k=…"},
+		{"error", errors.New("fail"), 1, "k=fail"},
+		{"error with spaces", errors.New("dial failed"), 1, `k="dial failed"`},
+		{"strings with spaces", deepNode{Name: "a b"}, 1, `k={Name:"a b" Next:<nil>}`},
+		{"durations", deepTimeout{"read", 1500 * time.Millisecond, time.Millisecond}, 1, "k={Op:read Timeout:1.5s elapsed:1ms}"},
+	}
+
+	for _, tt := range tests {
+		withTextEncoder(func(enc *textEncoder) {
+			enc.AddDeep("k", tt.val, tt.depth)
+			assert.Equal(t, tt.expected, string(enc.bytes), "Unexpected output serializing %s.", tt.desc)
+		})
+	}
+}
+
+func TestTextAddDeepTruncationMarker(t *testing.T) {
+	nested := map[string]interface{}{
+		"ints": []int{1, 2},
+		"node": deepNode{Name: "a"},
+	}
+
+	for _, marker := range []string{"[TRUNCATED]", "..."} {
+		withTextEncoder(func(enc *textEncoder) {
+			TextTruncationMarker(marker).apply(enc)
+			enc.AddDeep("k", nested, 1)
+			assert.Equal(t, "k=map[ints:"+marker+" node:"+marker+"]", string(enc.bytes), "Expected elided values to use the custom marker.")
+		})
+	}
+}
+
+func TestTextAddDeepMultiline(t *testing.T) {
+	val := map[string]interface{}{
+		"err":  errors.New("line one\nline two"),
+		"node": deepNode{Name: "a\nb"},
+	}
+
+	withTextEncoder(func(enc *textEncoder) {
+		enc.AddDeep("k", val, 3)
+		assert.Equal(t, `k=map[err:"line one\nline two" node:{Name:"a\nb" Next:<nil>}]`, string(enc.bytes), "Expected multi-line strings to be quoted.")
+	})
+
+	withTextEncoder(func(enc *textEncoder) {
+		TextQuoteStrings(false).apply(enc)
+		TextEscapeNewlines().apply(enc)
+		enc.AddDeep("k", val, 3)
+		assert.Equal(t, `k=map[err:line one\nline two node:{Name:a\nb Next:<nil>}]`, string(enc.bytes), "Expected newlines to be escaped.")
+	})
+}
+
+func TestTextEncoderAddDeep(t *testing.T) {
+	assertTextEncoderOutput(t, "AddDeep", "[I] hello k=[1 2]", func(enc TextEncoder) {
+		enc.AddDeep("k", []int{1, 2}, 3)
+	})
+}
@@ -0,0 +1,124 @@
+// Copyright (c) 2016 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zap
+
+import "sync"
+
+// _dittoMarker replaces field values that are unchanged from the previous
+// entry.
+const _dittoMarker = "^"
+
+// deltaFields remembers the fields of the last entry written by an encoder and
+// its clones.
+type deltaFields struct {
+	sync.Mutex
+	prev map[string]string
+	cur  map[string]string
+}
+
+// A fieldSpan locates a top-level field's value in an entry.
+type fieldSpan struct {
+	key        string
+	start, end int
+}
+
+// fieldSpans returns the locations of the top-level fields in final, whose
+// accumulated fields were copied from the encoder's buffer at offset base.
+func (enc *textEncoder) fieldSpans(final *textEncoder, base int) []fieldSpan {
+	spans := make([]fieldSpan, 0, len(enc.context)+len(final.context))
+	kept := len(enc.keptFields())
+	for _, cf := range enc.context {
+		end := cf.end
+		if end < 0 {
+			end = kept
+		}
+		spans = append(spans, fieldSpan{cf.field.key, base + cf.start, base + end})
+	}
+	for _, cf := range final.context {
+		end := cf.end
+		if end < 0 {
+			end = len(final.bytes)
+		}
+		spans = append(spans, fieldSpan{cf.field.key, cf.start, end})
+	}
+	return spans
+}
+
+// compact replaces the values in final that match the previous entry's with
+// the ditto marker, and remembers the entry's values for the next entry. It
+// updates the spans in place to match the compacted entry and returns them.
+func (d *deltaFields) compact(final *textEncoder, spans []fieldSpan) []fieldSpan {
+	d.Lock()
+	defer d.Unlock()
+	defer d.swap()
+	if len(spans) == 0 {
+		return spans
+	}
+
+	scratch := textPool.Get().(*textEncoder)
+	out := scratch.bytes[:0]
+	first, last := spans[0].start, spans[0].start
+	for i, s := range spans {
+		val := final.bytes[s.start:s.end]
+		out = append(out, final.bytes[last:s.start]...)
+		spans[i].start = first + len(out)
+		if prev, ok := d.prev[s.key]; ok && prev == string(val) && s.key != "_size" {
+			// The size is filled in after the fields are compacted, so it
+			// always looks unchanged.
+			out = append(out, _dittoMarker...)
+		} else {
+			out = append(out, val...)
+		}
+		spans[i].end = first + len(out)
+		d.cur[s.key] = string(val)
+		last = s.end
+	}
+	out = append(out, final.bytes[last:]...)
+	final.bytes = append(final.bytes[:first], out...)
+	scratch.bytes = out
+	scratch.Free()
+	return spans
+}
+
+// swap makes the current entry's values the previous entry's, reusing the
+// previous entry's map for the next entry.
+func (d *deltaFields) swap() {
+	d.prev, d.cur = d.cur, d.prev
+	for k := range d.cur {
+		delete(d.cur, k)
+	}
+}
+
+// TextDeltaFields replaces the values of fields that are unchanged since the
+// previous entry with "^" (e.g., "host=^ status=^ latency=12ms"), which makes
+// dense, repetitive logs from polling loops much more compact. The previous
+// entry is the last one written by the encoder or any of its clones, so this
+// is best suited to encoders that write to a single sink from one goroutine
+// at a time; otherwise, entries may refer to an entry other than the one
+// before them in the sink.
+func TextDeltaFields() TextOption {
+	return textOptionFunc(func(enc *textEncoder) {
+		enc.delta = &deltaFields{
+			prev: make(map[string]string),
+			cur:  make(map[string]string),
+		}
+	})
+}
@@ -0,0 +1,111 @@
+// Copyright (c) 2016 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zap
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTextDeltaFields(t *testing.T) {
+	enc := NewTextEncoder(TextNoTime(), TextDeltaFields())
+	defer enc.Free()
+	enc.AddString("host", "web-1")
+	sink := &testBuffer{}
+
+	for _, tt := range []struct {
+		status int
+		path   string
+	}{
+		{200, "/a"},
+		{200, "/b"},
+		{503, "/b"},
+		{503, "/b"},
+	} {
+		clone := enc.Clone()
+		clone.AddInt("status", tt.status)
+		clone.AddString("path", tt.path)
+		require.NoError(t, clone.WriteEntry(sink, "", "poll", InfoLevel, epoch), "Unexpected failure writing entry.")
+		clone.Free()
+	}
+
+	assert.Equal(t, []string{
+		"[I] poll host=web-1 status=200 path=/a",
+		"[I] poll host=^ status=^ path=/b",
+		"[I] poll host=^ status=503 path=^",
+		"[I] poll host=^ status=^ path=^",
+	}, sink.Lines(), "Expected unchanged values to be replaced with a ditto marker.")
+}
+
+func TestTextDeltaFieldsChangingKeys(t *testing.T) {
+	enc := NewTextEncoder(TextNoTime(), TextDeltaFields(), TextHeaderFieldSeparator(" | "))
+	defer enc.Free()
+	sink := &testBuffer{}
+
+	first := enc.Clone()
+	defer first.Free()
+	first.AddString("a", "x")
+	first.AddString("b", "two words")
+	require.NoError(t, first.WriteEntry(sink, "", "one", InfoLevel, epoch), "Unexpected failure writing entry.")
+
+	// Keys missing from the previous entry are written in full, as are values
+	// that appear under a different key.
+	second := enc.Clone()
+	defer second.Free()
+	second.AddString("c", "x")
+	second.AddString("b", "two words")
+	require.NoError(t, second.WriteEntry(sink, "", "two", InfoLevel, epoch), "Unexpected failure writing entry.")
+
+	// Only the previous entry is compared, not earlier ones.
+	third := enc.Clone()
+	defer third.Free()
+	third.AddString("a", "x")
+	require.NoError(t, third.WriteEntry(sink, "", "three", InfoLevel, epoch), "Unexpected failure writing entry.")
+
+	// Entries without fields don't confuse later entries.
+	require.NoError(t, enc.WriteEntry(sink, "", "four", InfoLevel, epoch), "Unexpected failure writing entry.")
+	require.NoError(t, third.WriteEntry(sink, "", "five", InfoLevel, epoch), "Unexpected failure writing entry.")
+
+	assert.Equal(t, []string{
+		`[I] one | a=x b="two words"`,
+		`[I] two | c=x b=^`,
+		`[I] three | a=x`,
+		`[I] four`,
+		`[I] five | a=x`,
+	}, sink.Lines(), "Unexpected delta encoding.")
+}
+
+func TestTextDeltaFieldsGenerated(t *testing.T) {
+	enc := NewTextEncoder(TextNoTime(), TextDeltaFields(), TextComponent("poller"), TextEmitSize())
+	defer enc.Free()
+	enc.(*textEncoder).AddLazy("n", func() interface{} { return 1 })
+	sink := &testBuffer{}
+
+	for i := 0; i < 2; i++ {
+		require.NoError(t, enc.WriteEntry(sink, "", "poll", InfoLevel, epoch), "Unexpected failure writing entry.")
+	}
+	assert.Equal(t, []string{
+		"[I] poll n=1 component=poller _size=39",
+		"[I] poll n=^ component=^ _size=34",
+	}, sink.Lines(), "Expected generated fields to be compacted, except for the size.")
+}
@@ -0,0 +1,142 @@
+// Copyright (c) 2016 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zap
+
+import (
+	"strconv"
+	"time"
+)
+
+// A DurationFormat controls how the text encoder renders durations.
+type DurationFormat int
+
+const (
+	// DurationString renders durations in Go's human-readable form (e.g.,
+	// "1.5s"). It's the default.
+	DurationString DurationFormat = iota
+	// DurationNanos renders durations as an integer number of nanoseconds
+	// (e.g., "1500000000").
+	DurationNanos
+	// DurationSeconds renders durations as a floating-point number of seconds
+	// (e.g., "1.5").
+	DurationSeconds
+)
+
+// AddDuration adds a duration in the format chosen by the TextDurationFormat
+// option, which defaults to Go's human-readable form (e.g., "1.5s"). With the
+// TextDurationDual option, the number of milliseconds follows in parentheses.
+func (enc *textEncoder) AddDuration(key string, val time.Duration) {
+	enc.addKey(key)
+	enc.recordField(Duration(key, val))
+	switch enc.durationFmt {
+	case DurationNanos:
+		enc.bytes = strconv.AppendInt(enc.bytes, int64(val), 10)
+	case DurationSeconds:
+		enc.bytes = strconv.AppendFloat(enc.bytes, val.Seconds(), 'f', -1, 64)
+	default:
+		enc.bytes = appendDuration(enc.bytes, val)
+	}
+	if enc.durationDual {
+		enc.bytes = append(enc.bytes, '(')
+		enc.bytes = strconv.AppendFloat(enc.bytes, float64(val)/float64(time.Millisecond), 'f', -1, 64)
+		enc.bytes = append(enc.bytes, "ms)"...)
+	}
+}
+
+// TextDurationFormat sets the format AddDuration uses.
+func TextDurationFormat(f DurationFormat) TextOption {
+	return textOptionFunc(func(enc *textEncoder) {
+		enc.durationFmt = f
+	})
+}
+
+// TextDurationDual makes AddDuration follow the human-readable form of each
+// duration with its value in milliseconds (e.g., "latency=1.5s(1500ms)"), so
+// that entries are easy to read and dashboards can still parse a number. The
+// first form follows TextDurationFormat.
+func TextDurationDual() TextOption {
+	return textOptionFunc(func(enc *textEncoder) {
+		enc.durationDual = true
+	})
+}
+
+// appendDuration appends d in the same form as d.String(), without
+// allocating.
+func appendDuration(buf []byte, d time.Duration) []byte {
+	if d == 0 {
+		return append(buf, "0s"...)
+	}
+	u := uint64(d)
+	if d < 0 {
+		u = -u
+		buf = append(buf, '-')
+	}
+
+	if u < uint64(time.Second) {
+		// Use the largest unit smaller than the duration.
+		switch {
+		case u < uint64(time.Microsecond):
+			buf = strconv.AppendUint(buf, u, 10)
+			return append(buf, "ns"...)
+		case u < uint64(time.Millisecond):
+			buf = appendFraction(buf, u, 3)
+			return append(buf, "µs"...)
+		default:
+			buf = appendFraction(buf, u, 6)
+			return append(buf, "ms"...)
+		}
+	}
+
+	h := u / uint64(time.Hour)
+	u -= h * uint64(time.Hour)
+	m := u / uint64(time.Minute)
+	u -= m * uint64(time.Minute)
+	if h > 0 {
+		buf = strconv.AppendUint(buf, h, 10)
+		buf = append(buf, 'h')
+	}
+	if h > 0 || m > 0 {
+		buf = strconv.AppendUint(buf, m, 10)
+		buf = append(buf, 'm')
+	}
+	buf = appendFraction(buf, u, 9)
+	return append(buf, 's')
+}
+
+// appendFraction appends v divided by 10^prec, omitting trailing zeros in
+// the fractional part.
+func appendFraction(buf []byte, v uint64, prec int) []byte {
+	pow := uint64(1)
+	for i := 0; i < prec; i++ {
+		pow *= 10
+	}
+	buf = strconv.AppendUint(buf, v/pow, 10)
+	frac := v % pow
+	if frac == 0 {
+		return buf
+	}
+	buf = append(buf, '.')
+	for p := pow / 10; frac > 0; p /= 10 {
+		buf = append(buf, byte('0'+frac/p))
+		frac %= p
+	}
+	return buf
+}
@@ -0,0 +1,78 @@
+// Copyright (c) 2016 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zap
+
+import (
+	"sort"
+	"strconv"
+	"time"
+)
+
+// AddDurationStats summarizes a batch of durations, adding the count, minimum,
+// maximum, mean, median, and 99th percentile as sub-fields of the key (e.g.,
+// "latency.p99=12ms"). The supplied slice isn't modified.
+func (enc *textEncoder) AddDurationStats(key string, durations []time.Duration) {
+	enc.addSubKey(key, "count")
+	enc.bytes = strconv.AppendInt(enc.bytes, int64(len(durations)), 10)
+	if len(durations) == 0 {
+		return
+	}
+
+	sorted := make(durationSlice, len(durations))
+	copy(sorted, durations)
+	sort.Sort(sorted)
+
+	var sum time.Duration
+	for _, d := range sorted {
+		sum += d
+	}
+
+	enc.addDurationStat(key, "min", sorted[0])
+	enc.addDurationStat(key, "max", sorted[len(sorted)-1])
+	enc.addDurationStat(key, "mean", sum/time.Duration(len(sorted)))
+	enc.addDurationStat(key, "p50", sorted.percentile(50))
+	enc.addDurationStat(key, "p99", sorted.percentile(99))
+}
+
+func (enc *textEncoder) addDurationStat(key, stat string, d time.Duration) {
+	enc.addSubKey(key, stat)
+	enc.bytes = append(enc.bytes, d.String()...)
+}
+
+// addSubKey adds a key of the form "key.sub".
+func (enc *textEncoder) addSubKey(key, sub string) {
+	enc.addKey(key + "." + sub)
+}
+
+type durationSlice []time.Duration
+
+func (ds durationSlice) Len() int           { return len(ds) }
+func (ds durationSlice) Less(i, j int) bool { return ds[i] < ds[j] }
+func (ds durationSlice) Swap(i, j int)      { ds[i], ds[j] = ds[j], ds[i] }
+
+// percentile returns the nearest-rank percentile of a sorted, non-empty slice.
+func (ds durationSlice) percentile(p int) time.Duration {
+	rank := (p*len(ds) + 99) / 100
+	if rank < 1 {
+		rank = 1
+	}
+	return ds[rank-1]
+}
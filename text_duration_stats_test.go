@@ -0,0 +1,68 @@
+// Copyright (c) 2016 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zap
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTextAddDurationStats(t *testing.T) {
+	// 1ms through 100ms, shuffled.
+	durations := make([]time.Duration, 0, 100)
+	for i := 100; i > 50; i-- {
+		durations = append(durations, time.Duration(i)*time.Millisecond)
+	}
+	for i := 1; i <= 50; i++ {
+		durations = append(durations, time.Duration(i)*time.Millisecond)
+	}
+	original := append([]time.Duration(nil), durations...)
+
+	tests := []struct {
+		desc      string
+		durations []time.Duration
+		expected  string
+	}{
+		{"nil", nil, "lat.count=0"},
+		{"single", []time.Duration{time.Second}, "lat.count=1 lat.min=1s lat.max=1s lat.mean=1s lat.p50=1s lat.p99=1s"},
+		{
+			"uniform distribution",
+			durations,
+			"lat.count=100 lat.min=1ms lat.max=100ms lat.mean=50.5ms lat.p50=50ms lat.p99=99ms",
+		},
+	}
+
+	for _, tt := range tests {
+		withTextEncoder(func(enc *textEncoder) {
+			enc.AddDurationStats("lat", tt.durations)
+			assert.Equal(t, tt.expected, string(enc.bytes), "Unexpected duration stats for %s.", tt.desc)
+		})
+	}
+	assert.Equal(t, original, durations, "AddDurationStats shouldn't modify its input.")
+}
+
+func TestTextEncoderAddDurationStats(t *testing.T) {
+	assertTextEncoderOutput(t, "duration stats", "[I] hello lat.count=1 lat.min=1s lat.max=1s lat.mean=1s lat.p50=1s lat.p99=1s", func(enc TextEncoder) {
+		enc.AddDurationStats("lat", []time.Duration{time.Second})
+	})
+}
@@ -0,0 +1,120 @@
+// Copyright (c) 2016 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zap
+
+import (
+	"math"
+	"testing"
+	"testing/quick"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTextAddDuration(t *testing.T) {
+	tests := []struct {
+		val   time.Duration
+		plain string
+		dual  string
+	}{
+		{1500 * time.Millisecond, "d=1.5s", "d=1.5s(1500ms)"},
+		{250 * time.Microsecond, "d=250µs", "d=250µs(0.25ms)"},
+		{2*time.Minute + 3*time.Second, "d=2m3s", "d=2m3s(123000ms)"},
+		{0, "d=0s", "d=0s(0ms)"},
+		{-time.Millisecond, "d=-1ms", "d=-1ms(-1ms)"},
+	}
+
+	for _, tt := range tests {
+		assertTextOutput(t, "duration", tt.plain, func(e Encoder) {
+			e.(*textEncoder).AddDuration("d", tt.val)
+		})
+		assertTextOutput(t, "dual duration", tt.dual, func(e Encoder) {
+			TextDurationDual().apply(e.(*textEncoder))
+			e.(*textEncoder).AddDuration("d", tt.val)
+		})
+	}
+}
+
+func TestTextDurationFormat(t *testing.T) {
+	tests := []struct {
+		format   DurationFormat
+		val      time.Duration
+		expected string
+	}{
+		{DurationString, 1500 * time.Millisecond, "d=1.5s"},
+		{DurationString, -1500 * time.Millisecond, "d=-1.5s"},
+		{DurationString, 0, "d=0s"},
+		{DurationNanos, 1500 * time.Millisecond, "d=1500000000"},
+		{DurationNanos, -time.Microsecond, "d=-1000"},
+		{DurationNanos, 0, "d=0"},
+		{DurationSeconds, 1500 * time.Millisecond, "d=1.5"},
+		{DurationSeconds, -250 * time.Millisecond, "d=-0.25"},
+		{DurationSeconds, 0, "d=0"},
+	}
+
+	for _, tt := range tests {
+		assertTextOutput(t, "formatted duration", tt.expected, func(e Encoder) {
+			TextDurationFormat(tt.format).apply(e.(*textEncoder))
+			e.AddDuration("d", tt.val)
+		})
+	}
+
+	assertTextOutput(t, "dual formatted duration", "d=1.5(1500ms)", func(e Encoder) {
+		TextDurationFormat(DurationSeconds).apply(e.(*textEncoder))
+		TextDurationDual().apply(e.(*textEncoder))
+		e.AddDuration("d", 1500*time.Millisecond)
+	})
+}
+
+func TestAppendDurationMatchesString(t *testing.T) {
+	edges := []time.Duration{
+		0, 1, -1, 999, 1000, 1001, time.Millisecond - 1, time.Millisecond, time.Second - 1,
+		time.Second, time.Minute, time.Hour, time.Hour + time.Nanosecond, 100*time.Hour + 59*time.Second,
+		math.MaxInt64, math.MinInt64,
+	}
+	for _, d := range edges {
+		assert.Equal(t, d.String(), string(appendDuration(nil, d)), "Unexpected formatting for %d.", int64(d))
+	}
+
+	matches := func(d time.Duration) bool {
+		return d.String() == string(appendDuration(nil, d))
+	}
+	assert.NoError(t, quick.Check(matches, &quick.Config{MaxCountScale: 100.0}), "Expected appendDuration to match Duration.String.")
+}
+
+func TestOtherEncodersAddDuration(t *testing.T) {
+	json := NewJSONEncoder().(*jsonEncoder)
+	defer json.Free()
+	json.AddDuration("d", 1500*time.Millisecond)
+	assert.Equal(t, `"d":1500000000`, string(json.bytes), "Expected JSON durations in nanoseconds.")
+
+	qs := NewQueryStringEncoder().(*queryStringEncoder)
+	defer qs.Free()
+	qs.AddDuration("d", 250*time.Microsecond)
+	assert.Equal(t, "d=250%C2%B5s", string(qs.bytes), "Expected query string durations to be percent-encoded.")
+
+	yaml := NewYAMLFlowEncoder().(*yamlFlowEncoder)
+	defer yaml.Free()
+	yaml.AddDuration("d", -time.Minute)
+	assert.Equal(t, `d: "-1m0s"`, string(yaml.bytes), "Unexpected YAML duration.")
+
+	NullEncoder().AddDuration("d", time.Second)
+}
@@ -21,12 +21,19 @@
 package zap
 
 import (
+	"database/sql"
+	"encoding/base64"
 	"fmt"
 	"io"
 	"math"
+	"net/http"
+	"runtime"
 	"strconv"
+	"strings"
 	"sync"
 	"time"
+	"unicode"
+	"unsafe"
 )
 
 var textPool = sync.Pool{New: func() interface{} {
@@ -35,19 +42,204 @@ var textPool = sync.Pool{New: func() interface{} {
 	}
 }}
 
+// _utf8BOM is the UTF-8 encoding of the byte order mark, U+FEFF.
+const _utf8BOM = "\xef\xbb\xbf"
+
 type textEncoder struct {
-	bytes   []byte
-	timeFmt string
-	noName  bool
+	bytes      []byte
+	timeFmt    string
+	noName     bool
+	nameDepth  int
+	skipEmpty  bool
+	statsHook  func(used, cap int)
+	fatalHook  func()
+	smartBytes bool
+	nullToken  string
+	sqlArgs    SQLArgsFormat
+	levelIcons map[Level]string
+	iconsOnly  bool
+	// With TextLevelDelimiters, levelOpen and levelClose replace the brackets
+	// around the level.
+	levelDelims           bool
+	levelOpen, levelClose string
+	// Labels that replace the single-letter level labels.
+	levelLabels map[Level]string
+	// Sinks that have already received the format header.
+	headerSinks *sinkSet
+	// Sinks that have already received a byte order mark.
+	bomSinks *sinkSet
+	// Sinks that have already received a file boundary marker.
+	boundarySinks *sinkSet
+	writeTimeout  time.Duration
+	entryIDs      bool
+	runID         string
+	component     string
+	// If non-empty, AddComponent joins components with this separator rather
+	// than replacing the current component.
+	componentSep string
+	dedupeKeys   DedupeStrategy
+	delta        *deltaFields
+	cardinality  *cardinality
+	quietHours   *quietHours
+	laps         map[string][]time.Time
+	lazy         []lazyField
+	minLevel     *Level
+	k8s          *k8sMetadata
+	repeats      *keyRepeats
+	splitWidth   int
+	selfTiming   func(time.Duration)
+	durationDual bool
+	durationFmt  DurationFormat
+	headerSep    string
+	// The key-value separator, if it's not the default "=".
+	kvSep        string
+	limiter      *rateLimiter
+	dedupCache   *dedupCache
+	timestamps   *timestampCache
+	thousandsSep byte
+	floatVerb    byte
+	floatPrec    int
+	quoteStrings bool
+	emitSize     bool
+	truncMarker  string
+	// With TextEscapeNewlines, control characters in strings written without
+	// quotes are escaped.
+	escapeNewlines bool
+	// Labels added with AddLabel, rendered separately from the fields.
+	labels []byte
+	// With TextDualClock, entries include the monotonic time elapsed since
+	// monoBase.
+	dualClock bool
+	monoBase  time.Time
+	// With TextEpochTime, timestamps are written as seconds since epoch with
+	// epochPrecision fractional digits.
+	epochTime      bool
+	epochPrecision int
+	// With TextOpenMetricsExemplars, the trace context of an entry is found
+	// by addFields, before its fields are rewritten, and recorded in the
+	// final encoder.
+	exemplars       bool
+	traceID, spanID string
+	// With TextFieldsOnly, entries are written without a header.
+	fieldsOnly     bool
+	omitEmptyLines bool
+	// With TextSeverityScore, score is the entry's score, computed before the
+	// final encoder's fields are added.
+	scoreFn func(lvl Level, msg string, fields []Field) int
+	score   int
+	// With TextSecretScan, secretFound records whether a secret was masked
+	// in the encoder's fields.
+	secretScan  bool
+	secretFound bool
+	// Nesting depth of AddMarshaler calls.
+	depth int
+	// Top-level fields, recorded for Context.
+	context []contextField
+	ctxOpen bool
+	// When wrapped by NewKafkaFrameEncoder, each write is prefixed with its
+	// length.
+	lengthPrefix bool
+}
+
+// TextEncoder is an Encoder with additional helpers for the text format. The
+// encoders created by NewTextEncoder and NewANSIEncoder implement it, as do
+// their clones, so callers can use the helpers with a type assertion:
+//
+//	enc := zap.NewTextEncoder().(zap.TextEncoder)
+//	enc.AddHeaders("req", r.Header)
+type TextEncoder interface {
+	Encoder
+
+	// AddArray adds an array, rendering its elements separated by spaces.
+	AddArray(key string, arr ArrayMarshaler) error
+	// AddEnum adds an enum-like integer, rendering it as "Name(val)" if the
+	// value has a name and as a plain integer otherwise.
+	AddEnum(key string, val int, names map[int]string)
+	// AddErrorGroup adds a group of errors under indexed sub-keys.
+	AddErrorGroup(key string, errs []error)
+	// AddHeaders adds HTTP headers as a nested object with sorted keys,
+	// redacting the values of headers that typically carry credentials.
+	AddHeaders(key string, h http.Header)
+	// AddLabel adds a low-cardinality label, rendered in its own block before
+	// the message.
+	AddLabel(key, val string)
+	// AddLazy adds a field whose value is computed only when an entry is
+	// written.
+	AddLazy(key string, fn func() interface{})
+	// AddRate adds a throughput, scaled so that it's easy to read.
+	AddRate(key string, count int64, per time.Duration)
+	// AddSQL adds a query and the number of its arguments, omitting their
+	// values unless the TextSQLArgs option says otherwise.
+	AddSQL(key, query string, args ...interface{})
+	// AddDeep serializes an arbitrary value by walking it with reflection, at
+	// most maxDepth levels deep. Unless zap is built with the zapdebug tag,
+	// it writes the placeholder "<debug-only>" instead.
+	AddDeep(key string, val interface{}, maxDepth int)
+	// AddNullString, AddNullInt64, AddNullFloat64, and AddNullBool add
+	// nullable SQL values, writing the encoder's null token for invalid ones.
+	AddNullString(key string, val sql.NullString)
+	AddNullInt64(key string, val sql.NullInt64)
+	AddNullFloat64(key string, val sql.NullFloat64)
+	AddNullBool(key string, val sql.NullBool)
+	// AddDurationStats summarizes a batch of durations as sub-fields of the
+	// key, like "latency.p99=12ms".
+	AddDurationStats(key string, durations []time.Duration)
+	// AddChanStats adds a channel's length and capacity as "len/cap".
+	AddChanStats(key string, length, capacity int)
+	// AddGoroutineCount adds the number of running goroutines under the
+	// "goroutines" key.
+	AddGoroutineCount()
+	// AddMemStats reads the runtime's memory statistics and adds a summary of
+	// them, like AddMemStatsSnapshot. Reading the statistics stops the world.
+	AddMemStats(key string)
+	// AddMemStatsSnapshot adds a summary of previously-read memory statistics.
+	AddMemStatsSnapshot(key string, ms *runtime.MemStats)
+	// AddStruct adds the exported fields of a struct that have a `log:"name"`
+	// tag as fields of a nested object.
+	AddStruct(key string, v interface{})
+	// MarkLap records the current time as a lap of the named operation, and
+	// AddLaps adds the durations between the laps recorded under the key.
+	MarkLap(name string)
+	AddLaps(key string)
+	// AddCompressed adds a potentially large value, gzipped and base64-encoded
+	// with a "gz:" prefix. Small values are added as with AddBytes.
+	AddCompressed(key string, val []byte)
+	// AddLocation adds the name of a time zone, treating a nil location as
+	// UTC.
+	AddLocation(key string, loc *time.Location)
+	// AddZoneOffset adds the offset from UTC of the time's zone, in ISO 8601
+	// form (e.g., "-05:00").
+	AddZoneOffset(key string, t time.Time)
+	// AddFlags adds the names of the true flags as a sorted, comma-separated
+	// list.
+	AddFlags(key string, flags map[string]bool)
+	// AddFlagEval adds the result of evaluating a feature flag as a nested
+	// block with the flag's name, variant, and reason.
+	AddFlagEval(key, flag, variant, reason string)
+	// AddProgress adds the progress of a long-running job as a fraction
+	// followed by the whole percentage completed (e.g., "rows=42/100(42%)").
+	AddProgress(key string, current, total int64)
+	// AddComponent sets the component stamped on every entry.
+	AddComponent(name string)
+	// Context returns the fields accumulated by the encoder and all the
+	// encoders it was cloned from.
+	Context() []Field
+	// WriteEntryf is like WriteEntry, but formats the message with
+	// fmt.Sprintf only if the entry is written.
+	WriteEntryf(sink io.Writer, name string, lvl Level, t time.Time, format string, args ...interface{}) error
+	// WriteEntryKV is like WriteEntry, but it also adds loosely-typed
+	// key-value pairs to the entry; see EntryKVWriter.
+	WriteEntryKV(sink io.Writer, name string, lvl Level, t time.Time, msg string, keysAndValues ...interface{}) error
+	// WriteTrailer writes an entry summarizing a session; see TrailerWriter.
+	WriteTrailer(sink io.Writer, summary map[string]interface{}) error
 }
 
 // NewTextEncoder creates a line-oriented text encoder whose output is optimized
 // for human, rather than machine, consumption. By default, the encoder uses
-// RFC3339-formatted timestamps.
+// RFC3339-formatted timestamps. The returned Encoder is also a TextEncoder.
 func NewTextEncoder(options ...TextOption) Encoder {
 	enc := textPool.Get().(*textEncoder)
-	enc.truncate()
-	enc.timeFmt = time.RFC3339
+	enc.reset()
 	for _, opt := range options {
 		opt.apply(enc)
 	}
@@ -59,12 +251,15 @@ func (enc *textEncoder) Free() {
 }
 
 func (enc *textEncoder) AddString(key, val string) {
+	val = enc.scanSecrets(val)
 	enc.addKey(key)
-	enc.bytes = append(enc.bytes, val...)
+	enc.bytes = enc.appendString(enc.bytes, val)
+	enc.recordField(String(key, val))
 }
 
 func (enc *textEncoder) AddBool(key string, val bool) {
 	enc.addKey(key)
+	enc.recordField(Bool(key, val))
 	if val {
 		enc.bytes = append(enc.bytes, "true"...)
 		return
@@ -74,6 +269,7 @@ func (enc *textEncoder) AddBool(key string, val bool) {
 
 func (enc *textEncoder) AddByte(key string, val byte) {
 	enc.addKey(key)
+	enc.recordField(Byte(key, val))
 	enc.bytes = append(enc.bytes, "0x"...)
 	enc.bytes = append(enc.bytes, hextable[val>>4])
 	enc.bytes = append(enc.bytes, hextable[val&0x0F])
@@ -81,115 +277,645 @@ func (enc *textEncoder) AddByte(key string, val byte) {
 
 func (enc *textEncoder) AddBytes(key string, val []byte) {
 	enc.addKey(key)
+	enc.recordField(Bytes(key, val))
+	if enc.smartBytes && isPrintable(val) {
+		enc.bytes = strconv.AppendQuote(enc.bytes, string(val))
+		return
+	}
 	enc.bytes = hexEncode(enc.bytes, val)
 }
 
+// AddBase64Bytes adds binary data encoded with standard, padded base64 (e.g.,
+// "blob=3q2+7w=="), which is a third the size of AddBytes's hex encoding for
+// large payloads.
+func (enc *textEncoder) AddBase64Bytes(key string, val []byte) {
+	enc.addKey(key)
+	enc.bytes = appendBase64(enc.bytes, val)
+}
+
+// appendBase64 appends val encoded with standard base64, encoding directly
+// into the buffer's spare capacity.
+func appendBase64(buf []byte, val []byte) []byte {
+	n := base64.StdEncoding.EncodedLen(len(val))
+	start := len(buf)
+	if cap(buf)-start < n {
+		grown := make([]byte, start, 2*cap(buf)+n)
+		copy(grown, buf)
+		buf = grown
+	}
+	buf = buf[:start+n]
+	base64.StdEncoding.Encode(buf[start:], val)
+	return buf
+}
+
+// AddRune adds a single character, quoted and escaped like a Go rune literal
+// (e.g., "ch='✓'" or "ch='\n'"). Invalid code points are rendered as the
+// replacement character, U+FFFD.
+func (enc *textEncoder) AddRune(key string, val rune) {
+	enc.addKey(key)
+	enc.bytes = strconv.AppendQuoteRune(enc.bytes, val)
+}
+
+// AddUintptr adds an address in hex (e.g., "addr=0xC000012345").
+func (enc *textEncoder) AddUintptr(key string, val uintptr) {
+	enc.addKey(key)
+	enc.bytes = appendAddress(enc.bytes, val)
+}
+
+// AddPointer adds the address a pointer holds, like AddUintptr. A nil pointer
+// is rendered as "0x0".
+func (enc *textEncoder) AddPointer(key string, val unsafe.Pointer) {
+	enc.AddUintptr(key, uintptr(val))
+}
+
 func (enc *textEncoder) AddInt(key string, val int) {
 	enc.AddInt64(key, int64(val))
+	enc.recordField(Int(key, val))
 }
 
 func (enc *textEncoder) AddInt64(key string, val int64) {
 	enc.addKey(key)
+	enc.recordField(Int64(key, val))
+	start := len(enc.bytes)
 	enc.bytes = strconv.AppendInt(enc.bytes, val, 10)
+	enc.groupDigits(start)
 }
 
 func (enc *textEncoder) AddUint(key string, val uint) {
 	enc.AddUint64(key, uint64(val))
+	enc.recordField(Uint(key, val))
 }
 
 func (enc *textEncoder) AddUint64(key string, val uint64) {
 	enc.addKey(key)
+	enc.recordField(Uint64(key, val))
+	start := len(enc.bytes)
 	enc.bytes = strconv.AppendUint(enc.bytes, val, 10)
+	enc.groupDigits(start)
+}
+
+func (enc *textEncoder) AddInt32(key string, val int32) {
+	enc.AddInt64(key, int64(val))
+	enc.recordField(Int32(key, val))
+}
+
+func (enc *textEncoder) AddInt16(key string, val int16) {
+	enc.AddInt64(key, int64(val))
+	enc.recordField(Int16(key, val))
+}
+
+func (enc *textEncoder) AddInt8(key string, val int8) {
+	enc.AddInt64(key, int64(val))
+	enc.recordField(Int8(key, val))
+}
+
+func (enc *textEncoder) AddUint32(key string, val uint32) {
+	enc.AddUint64(key, uint64(val))
+	enc.recordField(Uint32(key, val))
+}
+
+func (enc *textEncoder) AddUint16(key string, val uint16) {
+	enc.AddUint64(key, uint64(val))
+	enc.recordField(Uint16(key, val))
+}
+
+func (enc *textEncoder) AddUint8(key string, val uint8) {
+	enc.AddUint64(key, uint64(val))
+	enc.recordField(Uint8(key, val))
+}
+
+// groupDigits inserts the TextThousandsSeparator, if any, into the integer
+// that starts at the given offset and runs to the end of the buffer.
+func (enc *textEncoder) groupDigits(start int) {
+	if enc.thousandsSep == 0 {
+		return
+	}
+	if enc.bytes[start] == '-' {
+		start++
+	}
+	digits := len(enc.bytes) - start
+	seps := (digits - 1) / 3
+	if seps == 0 {
+		return
+	}
+	enc.bytes = append(enc.bytes, make([]byte, seps)...)
+	// Shift the digits right, starting from the end, inserting a separator
+	// before every group of three.
+	src, dst := start+digits-1, len(enc.bytes)-1
+	for i := 0; src >= start; i++ {
+		if i > 0 && i%3 == 0 {
+			enc.bytes[dst] = enc.thousandsSep
+			dst--
+		}
+		enc.bytes[dst] = enc.bytes[src]
+		src--
+		dst--
+	}
+}
+
+// AddEnum adds an enum-like integer, rendering it as "Name(val)" if the value
+// has a name and as a plain integer otherwise. Callers typically keep the
+// names for each enum type in a package-level map.
+func (enc *textEncoder) AddEnum(key string, val int, names map[int]string) {
+	enc.addKey(key)
+	name, ok := names[val]
+	if ok {
+		enc.bytes = append(enc.bytes, name...)
+		enc.bytes = append(enc.bytes, '(')
+	}
+	enc.bytes = strconv.AppendInt(enc.bytes, int64(val), 10)
+	if ok {
+		enc.bytes = append(enc.bytes, ')')
+	}
 }
 
 func (enc *textEncoder) AddFloat32(key string, val float32) {
 	enc.addFloat(key, float64(val), 32)
+	enc.recordField(Float32(key, val))
 }
 
 func (enc *textEncoder) AddFloat64(key string, val float64) {
 	enc.addFloat(key, val, 64)
+	enc.recordField(Float64(key, val))
 }
 
 func (enc *textEncoder) addFloat(key string, val float64, bitSize int) {
 	enc.addKey(key)
+	if enc.floatVerb == 0 {
+		enc.bytes = appendTextFloat(enc.bytes, val, 'f', -1, bitSize)
+		return
+	}
+	enc.bytes = appendTextFloat(enc.bytes, val, enc.floatVerb, enc.floatPrec, bitSize)
+}
+
+// appendTextFloat appends a float formatted like strconv.AppendFloat, but
+// writing infinities and NaN as "+Inf", "-Inf", and "NaN" regardless of the
+// format.
+func appendTextFloat(buf []byte, val float64, verb byte, prec, bitSize int) []byte {
 	switch {
 	case math.IsNaN(val):
-		enc.bytes = append(enc.bytes, "NaN"...)
+		return append(buf, "NaN"...)
 	case math.IsInf(val, 1):
-		enc.bytes = append(enc.bytes, "+Inf"...)
+		return append(buf, "+Inf"...)
 	case math.IsInf(val, -1):
-		enc.bytes = append(enc.bytes, "-Inf"...)
+		return append(buf, "-Inf"...)
 	default:
-		enc.bytes = strconv.AppendFloat(enc.bytes, val, 'f', -1, bitSize)
+		return strconv.AppendFloat(buf, val, verb, prec, bitSize)
 	}
 }
 
 func (enc *textEncoder) AddMarshaler(key string, obj LogMarshaler) error {
 	enc.addKey(key)
 	enc.bytes = append(enc.bytes, '{')
+	enc.depth++
 	err := obj.MarshalLog(enc)
+	enc.depth--
 	enc.bytes = append(enc.bytes, '}')
+	enc.recordField(Marshaler(key, obj))
 	return err
 }
 
+// AddObject serializes the object with its registered formatter (see
+// RegisterObjectFormatter) or, failing that, with fmt's %+v verb.
 func (enc *textEncoder) AddObject(key string, obj interface{}) error {
+	if ok, err := addFormattedObject(enc, key, obj); ok {
+		return err
+	}
 	enc.AddString(key, fmt.Sprintf("%+v", obj))
+	if !enc.secretScan {
+		// Otherwise, Context would return the unmasked object.
+		enc.recordField(Object(key, obj))
+	}
 	return nil
 }
 
 func (enc *textEncoder) Clone() Encoder {
 	clone := textPool.Get().(*textEncoder)
-	clone.truncate()
-	clone.bytes = append(clone.bytes, enc.bytes...)
-	clone.timeFmt = enc.timeFmt
+	enc.cloneTo(clone)
 	return clone
 }
 
+// cloneTo copies the encoder's options and accumulated fields into clone,
+// re-using clone's buffer.
+func (enc *textEncoder) cloneTo(clone *textEncoder) {
+	buf, ctx, labels := clone.bytes[:0], clone.context[:0], clone.labels[:0]
+	*clone = *enc
+	clone.bytes = append(buf, enc.keptFields()...)
+	clone.context = append(ctx, enc.context...)
+	clone.labels = append(labels, enc.labels...)
+	clone.laps = copyLaps(enc.laps)
+	clone.lazy = append([]lazyField(nil), enc.lazy...)
+	clone.repeats = enc.repeats.copy()
+}
+
+// newFinal returns a pooled encoder with the same options as the receiver but
+// no fields, which is used to assemble a complete entry. Fields generated
+// per-entry are added to the final encoder, so they're rendered consistently
+// with the accumulated fields.
+func (enc *textEncoder) newFinal() *textEncoder {
+	final := textPool.Get().(*textEncoder)
+	buf, ctx, labels := final.bytes[:0], final.context[:0], final.labels[:0]
+	*final = *enc
+	final.bytes = buf
+	final.context = ctx
+	final.labels = labels
+	final.ctxOpen = false
+	final.repeats = enc.repeats.copy()
+	return final
+}
+
 func (enc *textEncoder) WriteEntry(sink io.Writer, name string, msg string, lvl Level, t time.Time) error {
 	if sink == nil {
 		return errNilSink
 	}
+	if enc.skipEntry(name, msg, lvl, t) {
+		return nil
+	}
+	suppressed, ok := enc.dedup(lvl, msg)
+	if !ok {
+		return nil
+	}
+	dropped, ok := enc.admit()
+	if !ok {
+		return nil
+	}
 
-	final := textPool.Get().(*textEncoder)
-	final.truncate()
-	enc.addLevel(final, lvl)
-	enc.addTime(final, t)
-	enc.addName(final, name)
-	enc.addMessage(final, msg)
-
-	if len(enc.bytes) > 0 {
-		final.bytes = append(final.bytes, ' ')
-		final.bytes = append(final.bytes, enc.bytes...)
+	start := enc.startTiming()
+	final := enc.newFinal()
+	enc.scoreEntry(final, lvl, msg)
+	enc.addPreamble(final, sink, t)
+	if dropped > 0 {
+		enc.addDropSummary(final, dropped, t)
+		final.bytes = append(final.bytes, '\n')
 	}
+	if suppressed > 0 {
+		enc.addSuppressedSummary(final, suppressed, t)
+		final.bytes = append(final.bytes, '\n')
+	}
+	lineStart := len(final.bytes)
+	if !enc.fieldsOnly {
+		enc.addLevel(final, lvl)
+		enc.addTime(final, t)
+		enc.addName(final, name)
+		enc.addLabels(final)
+		enc.addMessage(final, msg)
+	}
+	fieldsStart := len(final.bytes)
+	enc.addFields(final, nil)
+	if enc.omitEmptyFields(final, lineStart, fieldsStart) {
+		enc.stopTiming(start)
+		return enc.writeFinal(sink, final, lvl)
+	}
+	sizeAt := len(final.bytes)
+	enc.addExemplar(final, t)
 	final.bytes = append(final.bytes, '\n')
+	enc.addSize(final, lineStart, sizeAt)
+	enc.stopTiming(start)
+	return enc.writeFinal(sink, final, lvl)
+}
 
+// startTiming returns the time that assembling an entry started, if the
+// TextSelfTiming option is set.
+func (enc *textEncoder) startTiming() time.Time {
+	if enc.selfTiming == nil {
+		return time.Time{}
+	}
+	return _timeNow()
+}
+
+// stopTiming reports the time spent assembling an entry to the TextSelfTiming
+// hook, if any.
+func (enc *textEncoder) stopTiming(start time.Time) {
+	if enc.selfTiming != nil {
+		enc.selfTiming(_timeNow().Sub(start))
+	}
+}
+
+// WriteEntryf is like WriteEntry, but it formats the message with
+// fmt.Sprintf. The message is only formatted if the entry isn't filtered out
+// by the encoder's level options (e.g., TextMinLevel), so callers don't pay
+// for formatting entries that are dropped.
+func (enc *textEncoder) WriteEntryf(sink io.Writer, name string, lvl Level, t time.Time, format string, args ...interface{}) error {
+	if sink == nil {
+		return errNilSink
+	}
+	if enc.filtered(lvl, t) {
+		return nil
+	}
+	return enc.WriteEntry(sink, name, fmt.Sprintf(format, args...), lvl, t)
+}
+
+// writeFinal writes a fully-assembled entry to the sink and returns the final
+// buffer to the pool.
+func (enc *textEncoder) writeFinal(sink io.Writer, final *textEncoder, lvl Level) error {
+	if err := enc.setWriteDeadline(sink); err != nil {
+		final.Free()
+		return err
+	}
+	if enc.lengthPrefix {
+		final.bytes = appendLengthPrefix(final.bytes)
+	}
 	expectedBytes := len(final.bytes)
-	n, err := sink.Write(final.bytes)
+	n, err := writeFully(sink, final.bytes)
+	if enc.statsHook != nil {
+		enc.statsHook(expectedBytes, cap(final.bytes))
+	}
 	final.Free()
 	if err != nil {
 		return err
 	}
 	if n != expectedBytes {
-		return fmt.Errorf("incomplete write: only wrote %v of %v bytes", n, expectedBytes)
+		return &ShortWriteError{Wrote: n, Expected: expectedBytes}
+	}
+	if lvl == FatalLevel && enc.fatalHook != nil {
+		if ws, ok := sink.(WriteSyncer); ok {
+			ws.Sync()
+		}
+		enc.fatalHook()
+	}
+	return nil
+}
+
+// _maxStalledWrites is the number of consecutive writes that make no progress
+// before writeFully gives up.
+const _maxStalledWrites = 3
+
+// writeFully writes buf to the sink, retrying short writes (which are normal
+// for sinks like network connections) until everything is written or the sink
+// returns an error other than io.ErrShortWrite. To avoid spinning forever on
+// a sink that's stopped accepting data, it gives up after several
+// consecutive writes that make no progress.
+func writeFully(sink io.Writer, buf []byte) (int, error) {
+	written, stalled := 0, 0
+	for written < len(buf) && stalled < _maxStalledWrites {
+		n, err := sink.Write(buf[written:])
+		written += n
+		if err != nil && err != io.ErrShortWrite {
+			return written, err
+		}
+		if n > 0 {
+			stalled = 0
+		} else {
+			stalled++
+		}
+	}
+	return written, nil
+}
+
+// A deadlineWriter is a sink (e.g., a net.Conn) that supports write deadlines.
+type deadlineWriter interface {
+	SetWriteDeadline(time.Time) error
+}
+
+func (enc *textEncoder) setWriteDeadline(sink io.Writer) error {
+	if enc.writeTimeout <= 0 {
+		return nil
+	}
+	if dw, ok := sink.(deadlineWriter); ok {
+		return dw.SetWriteDeadline(_timeNow().Add(enc.writeTimeout))
 	}
 	return nil
 }
 
 func (enc *textEncoder) truncate() {
 	enc.bytes = enc.bytes[:0]
+	enc.laps = nil
+	enc.lazy = nil
+	enc.context = enc.context[:0]
+	enc.ctxOpen = false
+	if enc.repeats != nil {
+		enc.repeats = &keyRepeats{
+			max:      enc.repeats.max,
+			counts:   make(map[string]int),
+			dropFrom: -1,
+		}
+	}
+}
+
+// reset truncates the encoder and restores the default options, since pooled
+// encoders may have been configured differently by their previous user.
+func (enc *textEncoder) reset() {
+	*enc = textEncoder{
+		bytes:        enc.bytes[:0],
+		context:      enc.context[:0],
+		labels:       enc.labels[:0],
+		timeFmt:      time.RFC3339,
+		nullToken:    _defaultNullToken,
+		headerSep:    " ",
+		quoteStrings: true,
+		truncMarker:  _defaultTruncationMarker,
+	}
+}
+
+// skipEntry reports whether the encoder's options filter out an entry.
+func (enc *textEncoder) skipEntry(name, msg string, lvl Level, t time.Time) bool {
+	return enc.filtered(lvl, t) || enc.isEmptyEntry(name, msg)
+}
+
+// filtered reports whether the encoder's level options filter out entries at
+// the given level and time, regardless of their content.
+func (enc *textEncoder) filtered(lvl Level, t time.Time) bool {
+	if enc.minLevel != nil && lvl < *enc.minLevel {
+		return true
+	}
+	return enc.quietHours != nil && enc.quietHours.suppresses(lvl, t)
+}
+
+// isEmptyEntry reports whether an entry should be skipped because it carries
+// no content. The level and timestamp alone don't count as content.
+func (enc *textEncoder) isEmptyEntry(name, msg string) bool {
+	if !enc.skipEmpty {
+		return false
+	}
+	return msg == "" && (name == "" || enc.noName) && len(enc.bytes) == 0 && len(enc.lazy) == 0 && len(enc.labels) == 0
+}
+
+// addFields adds the accumulated fields, followed by any fields that the
+// encoder's options generate for each entry. If colors is non-nil, keys and
+// values are colored.
+func (enc *textEncoder) addFields(final *textEncoder, colors *fieldColors) {
+	headerEnd := len(final.bytes)
+	if fields := enc.keptFields(); len(fields) > 0 {
+		final.bytes = append(final.bytes, ' ')
+		final.bytes = append(final.bytes, fields...)
+	}
+	for _, f := range enc.lazy {
+		addValue(final, f.key, f.fn())
+	}
+	final.addOmittedCounts()
+	if enc.k8s != nil {
+		final.AddMarshaler("k8s", enc.k8s)
+	}
+	if enc.component != "" {
+		final.AddString("component", enc.component)
+	}
+	if enc.runID != "" {
+		final.AddString("run", enc.runID)
+	}
+	if enc.entryIDs {
+		final.addKey("id")
+		final.bytes = appendUUID(final.bytes)
+	}
+	if final.secretFound {
+		// Set by the accumulated fields or the lazy fields.
+		final.AddBool("_secret_detected", true)
+	}
+	if enc.scoreFn != nil {
+		final.AddInt("_score", final.score)
+	}
+	if enc.emitSize {
+		// The value is filled in by addSize once the entry is complete.
+		final.addKey("_size")
+	}
+	if enc.exemplars {
+		final.traceID, final.spanID = enc.traceContext(final)
+	}
+	if enc.dedupeKeys != DedupeNone || enc.delta != nil || enc.cardinality != nil || colors != nil {
+		base := headerEnd
+		if len(enc.keptFields()) > 0 {
+			// Accumulated fields are preceded by a space.
+			base++
+		}
+		spans := enc.fieldSpans(final, base)
+		if enc.dedupeKeys != DedupeNone {
+			spans = enc.dedupe(final, spans)
+		}
+		if enc.cardinality != nil {
+			enc.observeCardinality(final, spans)
+		}
+		if enc.delta != nil {
+			spans = enc.delta.compact(final, spans)
+		}
+		if colors != nil {
+			enc.colorFields(final, spans, colors)
+		}
+	}
+	if enc.fieldsOnly && len(final.bytes) > headerEnd && final.bytes[headerEnd] == ' ' {
+		// Without a header, there's nothing to separate the fields from.
+		final.bytes = replaceByte(final.bytes, headerEnd, "")
+	} else if enc.headerSep != " " && len(final.bytes) > headerEnd {
+		// Fields are always preceded by a single space.
+		final.bytes = replaceByte(final.bytes, headerEnd, enc.headerSep)
+	}
+}
+
+// replaceByte replaces the byte at index i with s, shifting the rest of the
+// buffer as necessary.
+func replaceByte(buf []byte, i int, s string) []byte {
+	tail := len(buf) - i - 1
+	if len(s) > 1 {
+		buf = append(buf, make([]byte, len(s)-1)...)
+	}
+	copy(buf[i+len(s):], buf[i+1:i+1+tail])
+	copy(buf[i:], s)
+	return buf[:i+len(s)+tail]
+}
+
+// addPreamble adds any once-per-sink lines that must precede the first entry
+// written to the sink.
+func (enc *textEncoder) addPreamble(final *textEncoder, sink io.Writer, t time.Time) {
+	if enc.bomSinks != nil && enc.bomSinks.add(sink) {
+		final.bytes = append(final.bytes, _utf8BOM...)
+	}
+	if enc.headerSinks != nil && enc.headerSinks.add(sink) {
+		final.bytes = append(final.bytes, "#zap-format: text v1 fields=level"...)
+		if enc.timeFmt != "" || enc.epochTime {
+			final.bytes = append(final.bytes, ",time"...)
+		}
+		if enc.dualClock {
+			final.bytes = append(final.bytes, ",mono"...)
+		}
+		if !enc.noName {
+			final.bytes = append(final.bytes, ",name"...)
+		}
+		final.bytes = append(final.bytes, ",msg\n"...)
+	}
+	if enc.boundarySinks != nil && enc.boundarySinks.add(sink) {
+		final.bytes = append(final.bytes, "--- rotated at "...)
+		if enc.epochTime {
+			final.bytes = appendEpoch(final.bytes, t, enc.epochPrecision)
+		} else if enc.timeFmt != "" {
+			final.bytes = t.AppendFormat(final.bytes, enc.timeFmt)
+		} else {
+			final.bytes = t.AppendFormat(final.bytes, time.RFC3339)
+		}
+		final.bytes = append(final.bytes, " ---\n"...)
+	}
 }
 
 func (enc *textEncoder) addKey(key string) {
+	if enc.depth == 0 {
+		enc.endContextField()
+	}
+	dropped := enc.trackRepeat(key)
 	lastIdx := len(enc.bytes) - 1
 	if lastIdx >= 0 && enc.bytes[lastIdx] != '{' {
 		enc.bytes = append(enc.bytes, ' ')
 	}
-	enc.bytes = append(enc.bytes, key...)
-	enc.bytes = append(enc.bytes, '=')
+	enc.bytes = enc.appendString(enc.bytes, key)
+	enc.bytes = append(enc.bytes, enc.keyValueSep()...)
+	if enc.depth == 0 {
+		enc.beginContextField(key, dropped)
+	}
+}
+
+// appendString appends s, quoting and escaping it if the TextQuoteStrings
+// option is enabled and s would otherwise be ambiguous.
+func (enc *textEncoder) appendString(buf []byte, s string) []byte {
+	if enc.shouldQuote(s) {
+		return strconv.AppendQuote(buf, s)
+	}
+	return enc.appendRaw(buf, s)
+}
+
+// shouldQuote reports whether appendString quotes s. Strings containing a
+// custom key-value separator are quoted along with those that needsQuoting
+// reports.
+func (enc *textEncoder) shouldQuote(s string) bool {
+	if !enc.quoteStrings {
+		return false
+	}
+	return needsQuoting(s) || (enc.kvSep != "" && strings.Contains(s, enc.kvSep))
+}
+
+// keyValueSep returns the separator written between keys and values.
+func (enc *textEncoder) keyValueSep() string {
+	if enc.kvSep == "" {
+		return "="
+	}
+	return enc.kvSep
+}
+
+// needsQuoting reports whether a string must be quoted to be parsed back from
+// a key=value line: it's empty, or it contains spaces, equals signs, double
+// quotes, or non-printable characters.
+func needsQuoting(s string) bool {
+	if s == "" {
+		return true
+	}
+	for _, r := range s {
+		if r == ' ' || r == '=' || r == '"' || !unicode.IsPrint(r) {
+			return true
+		}
+	}
+	return false
 }
 
 func (enc *textEncoder) addLevel(final *textEncoder, lvl Level) {
-	final.bytes = append(final.bytes, '[')
+	if icon, ok := enc.levelIcons[lvl]; ok {
+		final.bytes = append(final.bytes, icon...)
+		if enc.iconsOnly {
+			return
+		}
+		final.bytes = append(final.bytes, ' ')
+	}
+	open, close := enc.levelDelimiters()
+	final.bytes = append(final.bytes, open...)
+	if label, ok := enc.levelLabels[lvl]; ok {
+		final.bytes = append(final.bytes, label...)
+		final.bytes = append(final.bytes, close...)
+		return
+	}
 	switch lvl {
 	case DebugLevel:
 		final.bytes = append(final.bytes, 'D')
@@ -206,15 +932,32 @@ func (enc *textEncoder) addLevel(final *textEncoder, lvl Level) {
 	default:
 		final.bytes = strconv.AppendInt(final.bytes, int64(lvl), 10)
 	}
-	final.bytes = append(final.bytes, ']')
+	final.bytes = append(final.bytes, close...)
+}
+
+// levelDelimiters returns the strings written before and after the level.
+func (enc *textEncoder) levelDelimiters() (open, close string) {
+	if !enc.levelDelims {
+		return "[", "]"
+	}
+	return enc.levelOpen, enc.levelClose
 }
 
 func (enc *textEncoder) addTime(final *textEncoder, t time.Time) {
-	if enc.timeFmt == "" {
-		return
+	if enc.epochTime {
+		final.bytes = append(final.bytes, ' ')
+		final.bytes = appendEpoch(final.bytes, t, enc.epochPrecision)
+	} else if enc.timeFmt != "" && enc.timestamps != nil {
+		final.bytes = append(final.bytes, ' ')
+		final.bytes = enc.timestamps.append(final.bytes, t, enc.timeFmt)
+	} else if enc.timeFmt != "" {
+		final.bytes = append(final.bytes, ' ')
+		final.bytes = t.AppendFormat(final.bytes, enc.timeFmt)
+	}
+	if enc.dualClock {
+		final.bytes = append(final.bytes, " mono="...)
+		final.bytes = strconv.AppendInt(final.bytes, int64(_timeNow().Sub(enc.monoBase)), 10)
 	}
-	final.bytes = append(final.bytes, ' ')
-	final.bytes = t.AppendFormat(final.bytes, enc.timeFmt)
 }
 
 func (enc *textEncoder) addName(final *textEncoder, name string) {
@@ -222,12 +965,36 @@ func (enc *textEncoder) addName(final *textEncoder, name string) {
 		return
 	}
 	final.bytes = append(final.bytes, ' ')
-	final.bytes = append(final.bytes, name...)
+	if i := nameSuffix(name, enc.nameDepth); i > 0 {
+		final.bytes = enc.appendRaw(final.bytes, enc.truncMarker)
+		name = name[i:]
+	}
+	final.bytes = enc.appendRaw(final.bytes, name)
+}
+
+// nameSuffix returns the index at which the last depth dot-separated segments
+// of a logger name start, or 0 if the name has no more than depth segments or
+// depth isn't positive.
+func nameSuffix(name string, depth int) int {
+	if depth <= 0 {
+		return 0
+	}
+	for i := len(name) - 1; i >= 0; i-- {
+		if name[i] != '.' {
+			continue
+		}
+		if depth--; depth == 0 {
+			return i + 1
+		}
+	}
+	return 0
 }
 
 func (enc *textEncoder) addMessage(final *textEncoder, msg string) {
+	first, rest := enc.splitMessage(msg)
 	final.bytes = append(final.bytes, ' ')
-	final.bytes = append(final.bytes, msg...)
+	final.bytes = enc.appendRaw(final.bytes, first)
+	enc.addContinuations(final, rest)
 }
 
 // A TextOption is used to set options for a text encoder.
@@ -249,6 +1016,39 @@ func TextTimeFormat(layout string) TextOption {
 	})
 }
 
+// TextDualClock adds a monotonic timestamp after each entry's wall-clock
+// timestamp (e.g., "[I] 2016-07-01T12:00:00Z mono=1500000000 hello"), which
+// makes it easy to align entries with traces and profiles that use monotonic
+// clocks. The monotonic timestamp is the number of nanoseconds since the option
+// was applied, measured with Go's monotonic clock reading, so it's unaffected
+// by changes to the wall clock. Clones share the same base.
+func TextDualClock() TextOption {
+	return textOptionFunc(func(enc *textEncoder) {
+		enc.dualClock = true
+		enc.monoBase = _timeNow()
+	})
+}
+
+// TextEpochTime writes timestamps as the number of seconds since the Unix
+// epoch, with precision fractional digits (e.g., "1467374400.123" with a
+// precision of 3). The precision is clamped to between 0 (whole seconds) and 9
+// (nanoseconds), and extra digits are truncated rather than rounded. Epoch
+// timestamps take precedence over TextTimeFormat and TextNoTime, regardless
+// of the order the options are applied in, and they're also used for times
+// added with AddTime.
+func TextEpochTime(precision int) TextOption {
+	return textOptionFunc(func(enc *textEncoder) {
+		if precision < 0 {
+			precision = 0
+		}
+		if precision > 9 {
+			precision = 9
+		}
+		enc.epochTime = true
+		enc.epochPrecision = precision
+	})
+}
+
 // TextNoTime omits timestamps from the serialized log entries.
 func TextNoTime() TextOption {
 	return TextTimeFormat("")
@@ -260,3 +1060,332 @@ func TextNoName() TextOption {
 		enc.noName = true
 	})
 }
+
+// TextNameDepth shortens hierarchical logger names (e.g., "a.b.c.d.e") to
+// their last n dot-separated segments, which are the most specific, marking
+// the omitted segments with the truncation marker (e.g., "…d.e"; see
+// TextTruncationMarker). Names with no more than n segments are written in
+// full. Zero, the default, writes every name in full.
+func TextNameDepth(n int) TextOption {
+	return textOptionFunc(func(enc *textEncoder) {
+		enc.nameDepth = n
+	})
+}
+
+// TextSkipEmptyEntries drops entries that have no message, no name, and no
+// fields, rather than writing a line containing only the level and timestamp.
+func TextSkipEmptyEntries() TextOption {
+	return textOptionFunc(func(enc *textEncoder) {
+		enc.skipEmpty = true
+	})
+}
+
+// TextBufferStatsHook registers a function that's called after each entry is
+// written with the length and capacity of the buffer used to assemble it.
+// Aggregating these values shows whether the encoders' initial buffer size is
+// appropriate for an application's entries.
+func TextBufferStatsHook(hook func(used, cap int)) TextOption {
+	return textOptionFunc(func(enc *textEncoder) {
+		enc.statsHook = hook
+	})
+}
+
+// FatalHook registers a function that's called after an entry at FatalLevel
+// is successfully written (and, if the sink is a WriteSyncer, synced). Since
+// encoders never exit the process themselves, this lets wrappers enforce their
+// own exit policy once the entry has landed.
+func FatalHook(hook func()) TextOption {
+	return textOptionFunc(func(enc *textEncoder) {
+		enc.fatalHook = hook
+	})
+}
+
+// TextSmartBytes renders byte slices that contain only printable UTF-8 text as
+// quoted strings. Other slices are still hex-encoded.
+func TextSmartBytes() TextOption {
+	return textOptionFunc(func(enc *textEncoder) {
+		enc.smartBytes = true
+	})
+}
+
+// TextLevelIcons prefixes each entry's level label with an emoji, which makes
+// local development logs easier to scan in terminals that render emoji. Use
+// TextLevelIcon to customize the icon for a level.
+func TextLevelIcons() TextOption {
+	return textOptionFunc(func(enc *textEncoder) {
+		if enc.levelIcons == nil {
+			enc.levelIcons = copyLevelStrings(_defaultLevelIcons)
+		}
+	})
+}
+
+// TextLevelIconsOnly is like TextLevelIcons, but the icons replace the level
+// labels rather than prefixing them.
+func TextLevelIconsOnly() TextOption {
+	return textOptionFunc(func(enc *textEncoder) {
+		TextLevelIcons().apply(enc)
+		enc.iconsOnly = true
+	})
+}
+
+// TextLevelIcon sets the icon used for a single level, enabling level icons
+// if they aren't enabled already.
+func TextLevelIcon(lvl Level, icon string) TextOption {
+	return textOptionFunc(func(enc *textEncoder) {
+		// Copy the icons so that we don't modify the defaults or the icons of
+		// another encoder.
+		enc.levelIcons = copyLevelStrings(enc.levelIcons)
+		enc.levelIcons[lvl] = icon
+	})
+}
+
+// TextLevelLabels replaces the single-letter labels (e.g., "[I]") of the
+// given levels. Levels without a label keep the default: a single letter for
+// zap's levels, and a number for others.
+func TextLevelLabels(labels map[Level]string) TextOption {
+	return textOptionFunc(func(enc *textEncoder) {
+		// Copy the labels so that later changes to the caller's map don't
+		// affect the encoder.
+		enc.levelLabels = copyLevelStrings(labels)
+	})
+}
+
+// TextLevelDelimiters replaces the brackets around levels (e.g., "[I]") with
+// open and close, which may be empty. With the ANSI encoder, level colors
+// cover the delimiters as well as the level.
+func TextLevelDelimiters(open, close string) TextOption {
+	return textOptionFunc(func(enc *textEncoder) {
+		enc.levelDelims = true
+		enc.levelOpen, enc.levelClose = open, close
+	})
+}
+
+// TextNoLevelDelimiters writes levels without brackets (e.g., "I" rather
+// than "[I]").
+func TextNoLevelDelimiters() TextOption {
+	return TextLevelDelimiters("", "")
+}
+
+// TextFullLevelNames labels levels with their full, upper-case names (e.g.,
+// "[INFO]") rather than single letters.
+func TextFullLevelNames() TextOption {
+	return TextLevelLabels(_fullLevelNames)
+}
+
+// TextFormatHeader writes a line describing the output format (e.g.,
+// "#zap-format: text v1 fields=level,time,name,msg") before the first entry
+// written to each sink, which helps tools that read arbitrary log files detect
+// the format.
+//
+// To avoid keeping every sink alive, the encoder and its clones remember only
+// the 1024 most recently written sinks. If more sinks are in use at once, a
+// sink that's forgotten and then written to again receives a second header.
+func TextFormatHeader() TextOption {
+	return textOptionFunc(func(enc *textEncoder) {
+		enc.headerSinks = newSinkSet()
+	})
+}
+
+// TextWriteBOM writes a UTF-8 byte order mark before the first entry written
+// to each sink, which helps tools (particularly on Windows) that sniff a file's
+// encoding. Like TextFormatHeader, it tracks sinks across the encoder and its
+// clones, so each sink receives the mark exactly once, and it's subject to the
+// same limit on the number of sinks remembered.
+func TextWriteBOM() TextOption {
+	return textOptionFunc(func(enc *textEncoder) {
+		enc.bomSinks = newSinkSet()
+	})
+}
+
+// TextFileBoundaryMarker writes a "--- rotated at <time> ---" line, using the
+// entry's timestamp, before the first entry written to each new sink. When a
+// rotating writer hands the logger a fresh sink for each file, the marker shows
+// where one file ends and the next begins, even after the files are
+// concatenated. Unlike TextFormatHeader, which describes the format, the marker
+// records when output moved to the sink. Sinks are remembered under the same
+// limit as TextFormatHeader's.
+func TextFileBoundaryMarker() TextOption {
+	return textOptionFunc(func(enc *textEncoder) {
+		enc.boundarySinks = newSinkSet()
+	})
+}
+
+// TextSelfTiming registers a function that's called with the time spent
+// assembling each entry, excluding the time spent writing it to the sink. This
+// helps distinguish the encoder's cost from the sink's when profiling logging
+// on hot paths.
+func TextSelfTiming(hook func(encodeDuration time.Duration)) TextOption {
+	return textOptionFunc(func(enc *textEncoder) {
+		enc.selfTiming = hook
+	})
+}
+
+// TextHeaderFieldSeparator sets the separator between an entry's free-text
+// header (its level, time, name, and message) and its structured fields, which
+// defaults to a single space. A distinctive separator, like " | ", lets tools
+// reliably split the message from the key=value data. Entries without fields
+// don't include the separator.
+func TextHeaderFieldSeparator(sep string) TextOption {
+	return textOptionFunc(func(enc *textEncoder) {
+		enc.headerSep = sep
+	})
+}
+
+// TextThousandsSeparator groups the digits of integers added with AddInt,
+// AddInt64, AddUint, and AddUint64 into thousands (e.g., "1,000,000"), which
+// makes large numbers easier for humans to read. Since grouped numbers are
+// harder for machines to parse, grouping is off by default.
+func TextThousandsSeparator(sep byte) TextOption {
+	return textOptionFunc(func(enc *textEncoder) {
+		enc.thousandsSep = sep
+	})
+}
+
+// TextFloatFormat sets the format of floats added with AddFloat32 and
+// AddFloat64, using the verbs and precisions supported by strconv.FormatFloat.
+// For example, TextFloatFormat('g', 6) writes 1e20 as "1e+20" rather than
+// "100000000000000000000". Infinities and NaN are always written as "+Inf",
+// "-Inf", and "NaN". By default, floats use the 'f' verb with the smallest
+// precision that represents them exactly; unsupported verbs also restore the
+// default.
+func TextFloatFormat(verb byte, precision int) TextOption {
+	return textOptionFunc(func(enc *textEncoder) {
+		switch verb {
+		case 'b', 'e', 'E', 'f', 'g', 'G', 'x', 'X':
+			enc.floatVerb, enc.floatPrec = verb, precision
+		default:
+			enc.floatVerb, enc.floatPrec = 0, 0
+		}
+	})
+}
+
+// TextKeyValueSeparator sets the separator written between each field's key
+// and value, which defaults to "=" (e.g., TextKeyValueSeparator(": ") renders
+// "user: jane"). It's also used for labels. When TextQuoteStrings is enabled,
+// keys and values that contain a custom separator are quoted, so lines can
+// still be split unambiguously. An empty separator restores the default.
+func TextKeyValueSeparator(sep string) TextOption {
+	return textOptionFunc(func(enc *textEncoder) {
+		if sep == "=" {
+			sep = ""
+		}
+		enc.kvSep = sep
+	})
+}
+
+// TextQuoteStrings controls whether string keys and values that would make
+// a line ambiguous are quoted. When enabled, which is the default, strings that
+// are empty or contain spaces, equals signs, double quotes, or non-printable
+// characters are double-quoted and escaped like Go string literals (e.g.,
+// `msg="hello world"`), so lines can be parsed back by logfmt readers.
+// Disabling quoting restores the raw output that earlier versions wrote.
+func TextQuoteStrings(quote bool) TextOption {
+	return textOptionFunc(func(enc *textEncoder) {
+		enc.quoteStrings = quote
+	})
+}
+
+// TextTruncationMarker sets the string written in place of content that the
+// encoder cuts short, like values nested more deeply than AddDeep's limit. It
+// defaults to "…" (U+2026, a single horizontal ellipsis).
+func TextTruncationMarker(marker string) TextOption {
+	return textOptionFunc(func(enc *textEncoder) {
+		enc.truncMarker = marker
+	})
+}
+
+// TextWriteTimeout sets a deadline for each write to sinks that support write
+// deadlines, like network connections, so that a stuck connection can't block
+// the logger forever. If the deadline is exceeded, WriteEntry returns the
+// sink's error. Sinks that don't implement SetWriteDeadline are unaffected.
+func TextWriteTimeout(d time.Duration) TextOption {
+	return textOptionFunc(func(enc *textEncoder) {
+		enc.writeTimeout = d
+	})
+}
+
+// TextEntryUUID adds a random (version 4) UUID to each entry under the "id"
+// key, which lets downstream systems deduplicate re-delivered entries.
+func TextEntryUUID() TextOption {
+	return textOptionFunc(func(enc *textEncoder) {
+		enc.entryIDs = true
+	})
+}
+
+// TextRunID generates a random ID when the option is applied and adds it to
+// every entry under the "run" key. Unlike TextEntryUUID, the ID is the same for
+// all entries written by the encoder and its clones, so configuring it once at
+// startup makes it easy to isolate a single run's entries in a shared log file.
+func TextRunID() TextOption {
+	return textOptionFunc(func(enc *textEncoder) {
+		enc.runID = string(strconv.AppendUint(nil, fastRandom(), 16))
+	})
+}
+
+// TextMinLevel makes the encoder drop entries below the given level. Loggers
+// already filter entries by level, so this is only useful when calling
+// WriteEntry directly.
+func TextMinLevel(lvl Level) TextOption {
+	return textOptionFunc(func(enc *textEncoder) {
+		enc.minLevel = &lvl
+	})
+}
+
+// TextQuietHours suppresses entries below minLevel whose timestamps fall within
+// a daily window, which keeps overnight logs (and pages) limited to important
+// entries. The start and end of the window are offsets from midnight in the
+// entry timestamp's location, and the window may wrap past midnight (e.g., a
+// start of 22 hours and an end of 6 hours). Outside the window, entries are
+// written normally.
+func TextQuietHours(start, end time.Duration, minLevel Level) TextOption {
+	return textOptionFunc(func(enc *textEncoder) {
+		enc.quietHours = &quietHours{start: start, end: end, minLevel: minLevel}
+	})
+}
+
+type quietHours struct {
+	start, end time.Duration
+	minLevel   Level
+}
+
+func (q *quietHours) suppresses(lvl Level, t time.Time) bool {
+	if lvl >= q.minLevel {
+		return false
+	}
+	hour, min, sec := t.Clock()
+	offset := time.Duration(hour)*time.Hour +
+		time.Duration(min)*time.Minute +
+		time.Duration(sec)*time.Second +
+		time.Duration(t.Nanosecond())
+	if q.start <= q.end {
+		return offset >= q.start && offset < q.end
+	}
+	// The window wraps past midnight.
+	return offset >= q.start || offset < q.end
+}
+
+var _defaultLevelIcons = map[Level]string{
+	DebugLevel: "🐛",
+	InfoLevel:  "✅",
+	WarnLevel:  "⚠️",
+	ErrorLevel: "❌",
+	PanicLevel: "🔥",
+	FatalLevel: "💀",
+}
+
+var _fullLevelNames = map[Level]string{
+	DebugLevel: "DEBUG",
+	InfoLevel:  "INFO",
+	WarnLevel:  "WARN",
+	ErrorLevel: "ERROR",
+	PanicLevel: "PANIC",
+	FatalLevel: "FATAL",
+}
+
+func copyLevelStrings(icons map[Level]string) map[Level]string {
+	copied := make(map[Level]string, len(icons))
+	for lvl, icon := range icons {
+		copied[lvl] = icon
+	}
+	return copied
+}
@@ -0,0 +1,99 @@
+// Copyright (c) 2016 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zap
+
+import (
+	"io/ioutil"
+	"testing"
+	"time"
+)
+
+func BenchmarkTextAddDuration(b *testing.B) {
+	d := 1500 * time.Millisecond
+	enc := NewTextEncoder().(*textEncoder)
+	defer enc.Free()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		enc.truncate()
+		enc.AddDuration("latency", d)
+	}
+}
+
+func BenchmarkTextAddDurationString(b *testing.B) {
+	d := 1500 * time.Millisecond
+	enc := NewTextEncoder().(*textEncoder)
+	defer enc.Free()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		enc.truncate()
+		enc.AddString("latency", d.String())
+	}
+}
+
+func BenchmarkTextAddBytes4KB(b *testing.B) {
+	payload := make([]byte, 4096)
+	enc := NewTextEncoder().(*textEncoder)
+	defer enc.Free()
+	b.ReportAllocs()
+	b.SetBytes(int64(len(payload)))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		enc.truncate()
+		enc.AddBytes("blob", payload)
+	}
+}
+
+func BenchmarkTextAddBase64Bytes4KB(b *testing.B) {
+	payload := make([]byte, 4096)
+	enc := NewTextEncoder().(*textEncoder)
+	defer enc.Free()
+	b.ReportAllocs()
+	b.SetBytes(int64(len(payload)))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		enc.truncate()
+		enc.AddBase64Bytes("blob", payload)
+	}
+}
+
+func benchmarkTextTimestamps(b *testing.B, opts ...TextOption) {
+	enc := NewTextEncoder(opts...)
+	defer enc.Free()
+	ts := time.Date(2016, time.July, 1, 12, 0, 0, 0, time.UTC)
+	b.ReportAllocs()
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for i := 0; pb.Next(); i++ {
+			// Many entries per second, as in a busy logger.
+			enc.WriteEntry(ioutil.Discard, "", "hi", InfoLevel, ts.Add(time.Duration(i)*time.Microsecond))
+		}
+	})
+}
+
+func BenchmarkTextTimestamps(b *testing.B) {
+	benchmarkTextTimestamps(b)
+}
+
+func BenchmarkTextCachedTimestamps(b *testing.B) {
+	benchmarkTextTimestamps(b, TextCachedTimestamps())
+}
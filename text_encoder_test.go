@@ -21,13 +21,21 @@
 package zap
 
 import (
+	"encoding/base64"
+	"errors"
 	"fmt"
 	"io"
 	"math"
+	"net"
+	"regexp"
+	"strconv"
+	"strings"
 	"testing"
 	"time"
+	"unsafe"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"github.com/uber-go/zap/spywrite"
 )
 
@@ -37,6 +45,17 @@ func withTextEncoder(f func(*textEncoder)) {
 	enc.Free()
 }
 
+// assertTextEncoderOutput adds fields to a new encoder through the exported
+// TextEncoder interface, then checks the entry it writes.
+func assertTextEncoderOutput(t testing.TB, desc string, expected string, f func(TextEncoder)) {
+	enc := NewTextEncoder(TextNoTime()).(TextEncoder)
+	defer enc.Free()
+	f(enc)
+	sink := &testBuffer{}
+	require.NoError(t, enc.WriteEntry(sink, "", "hello", InfoLevel, epoch), "Unexpected failure writing entry.")
+	assert.Equal(t, expected, sink.Stripped(), "Unexpected output for %s.", desc)
+}
+
 func assertTextOutput(t testing.TB, desc string, expected string, f func(Encoder)) {
 	withTextEncoder(func(enc *textEncoder) {
 		f(enc)
@@ -62,7 +81,15 @@ func TestTextEncoderFields(t *testing.T) {
 		f        func(Encoder)
 	}{
 		{"string", "k=v", func(e Encoder) { e.AddString("k", "v") }},
-		{"string", "k=", func(e Encoder) { e.AddString("k", "") }},
+		{"string", `k=""`, func(e Encoder) { e.AddString("k", "") }},
+		{"string", `k="hello world"`, func(e Encoder) { e.AddString("k", "hello world") }},
+		{"string", `k="a=b"`, func(e Encoder) { e.AddString("k", "a=b") }},
+		{"string", `k="say \"hi\""`, func(e Encoder) { e.AddString("k", `say "hi"`) }},
+		{"string", `k="line1\nline2\ttab"`, func(e Encoder) { e.AddString("k", "line1\nline2\ttab") }},
+		{"string", `k=C:\path`, func(e Encoder) { e.AddString("k", `C:\path`) }},
+		{"string", `k="C:\\my path"`, func(e Encoder) { e.AddString("k", `C:\my path`) }},
+		{"string", "k=héllo", func(e Encoder) { e.AddString("k", "héllo") }},
+		{"string", `"my key"=v`, func(e Encoder) { e.AddString("my key", "v") }},
 		{"bool", "k=true", func(e Encoder) { e.AddBool("k", true) }},
 		{"bool", "k=false", func(e Encoder) { e.AddBool("k", false) }},
 		{"byte", "k=0x2A", func(e Encoder) { e.AddByte("k", 0x2a) }},
@@ -143,6 +170,116 @@ func TestTextWriteEntry(t *testing.T) {
 	}
 }
 
+func TestTextFloatFormat(t *testing.T) {
+	tests := []struct {
+		desc     string
+		opts     []TextOption
+		f        func(Encoder)
+		expected string
+	}{
+		{"default large", nil, func(e Encoder) { e.AddFloat64("k", 1e20) }, "k=100000000000000000000"},
+		{"g large", []TextOption{TextFloatFormat('g', -1)}, func(e Encoder) { e.AddFloat64("k", 1e20) }, "k=1e+20"},
+		{"default small", nil, func(e Encoder) { e.AddFloat64("k", 1.5e-7) }, "k=0.00000015"},
+		{"e with precision", []TextOption{TextFloatFormat('e', 2)}, func(e Encoder) { e.AddFloat64("k", 1.5e-7) }, "k=1.50e-07"},
+		{"f with precision", []TextOption{TextFloatFormat('f', 2)}, func(e Encoder) { e.AddFloat64("k", 3.14159) }, "k=3.14"},
+		{"float32", []TextOption{TextFloatFormat('g', 3)}, func(e Encoder) { e.AddFloat32("k", 1234.5) }, "k=1.23e+03"},
+		{"NaN", []TextOption{TextFloatFormat('e', 3)}, func(e Encoder) { e.AddFloat64("k", math.NaN()) }, "k=NaN"},
+		{"+Inf", []TextOption{TextFloatFormat('g', 3)}, func(e Encoder) { e.AddFloat64("k", math.Inf(1)) }, "k=+Inf"},
+		{"-Inf", []TextOption{TextFloatFormat('g', 3)}, func(e Encoder) { e.AddFloat64("k", math.Inf(-1)) }, "k=-Inf"},
+		{"unsupported verb", []TextOption{TextFloatFormat('q', 3)}, func(e Encoder) { e.AddFloat64("k", 1e20) }, "k=100000000000000000000"},
+		{"complex unaffected", []TextOption{TextFloatFormat('e', 1)}, func(e Encoder) { e.AddComplex128("k", complex(1, 2)) }, "k=(1+2i)"},
+	}
+
+	for _, tt := range tests {
+		enc := NewTextEncoder(tt.opts...).(*textEncoder)
+		tt.f(enc)
+		assert.Equal(t, tt.expected, string(enc.bytes), "Unexpected output (%s).", tt.desc)
+		clone := enc.Clone().(*textEncoder)
+		clone.truncate()
+		tt.f(clone)
+		assert.Equal(t, tt.expected, string(clone.bytes), "Expected clones to keep the format (%s).", tt.desc)
+		clone.Free()
+		enc.Free()
+	}
+}
+
+func TestTextNameDepth(t *testing.T) {
+	tests := []struct {
+		depth    int
+		name     string
+		expected string
+	}{
+		{0, "a.b.c.d.e", "[I] a.b.c.d.e hi"},
+		{-1, "a.b.c.d.e", "[I] a.b.c.d.e hi"},
+		{2, "a.b.c.d.e", "[I] …d.e hi"},
+		{1, "a.b.c.d.e", "[I] …e hi"},
+		{4, "a.b.c.d.e", "[I] …b.c.d.e hi"},
+		{5, "a.b.c.d.e", "[I] a.b.c.d.e hi"},
+		{2, "a.b", "[I] a.b hi"},
+		{2, "svc", "[I] svc hi"},
+		{2, "", "[I] hi"},
+	}
+
+	sink := &testBuffer{}
+	for _, tt := range tests {
+		enc := NewTextEncoder(TextNoTime(), TextNameDepth(tt.depth))
+		require.NoError(t, enc.WriteEntry(sink, tt.name, "hi", InfoLevel, epoch), "Unexpected failure writing entry.")
+		assert.Equal(t, tt.expected, sink.Stripped(), "Unexpected output for name %q with depth %d.", tt.name, tt.depth)
+		sink.Reset()
+		enc.Free()
+	}
+
+	ansi := NewANSIEncoder(AnsiTextOption(TextNoTime()), AnsiTextOption(TextNameDepth(2)))
+	defer ansi.Free()
+	require.NoError(t, ansi.WriteEntry(sink, "a.b.c", "hi", InfoLevel, epoch), "Unexpected failure writing entry.")
+	assert.Equal(t, "[I] …b.c hi", stripEscapes(sink.Stripped()), "Unexpected ANSI output.")
+}
+
+func TestTextNameDepthTruncationMarker(t *testing.T) {
+	sink := &testBuffer{}
+	enc := NewTextEncoder(TextNoTime(), TextNameDepth(2), TextTruncationMarker("[...]"))
+	defer enc.Free()
+	require.NoError(t, enc.WriteEntry(sink, "a.b.c.d.e", "hi", InfoLevel, epoch), "Unexpected failure writing entry.")
+	assert.Equal(t, "[I] [...]d.e hi", sink.Stripped(), "Expected shortened names to use the custom marker.")
+
+	sink.Reset()
+	clone := enc.Clone()
+	defer clone.Free()
+	require.NoError(t, clone.WriteEntry(sink, "a.b.c", "hi", InfoLevel, epoch), "Unexpected failure writing entry.")
+	assert.Equal(t, "[I] [...]b.c hi", sink.Stripped(), "Expected clones to keep the custom marker.")
+}
+
+func TestTextLevelDelimiters(t *testing.T) {
+	tests := []struct {
+		desc     string
+		opts     []TextOption
+		lvl      Level
+		expected string
+	}{
+		{"default", nil, InfoLevel, "[I] hi"},
+		{"no delimiters", []TextOption{TextNoLevelDelimiters()}, InfoLevel, "I hi"},
+		{"custom", []TextOption{TextLevelDelimiters("<", ">")}, WarnLevel, "<W> hi"},
+		{"custom multi-byte", []TextOption{TextLevelDelimiters("level=", ":")}, ErrorLevel, "level=E: hi"},
+		{"custom with labels", []TextOption{TextLevelDelimiters("|", "|"), TextFullLevelNames()}, InfoLevel, "|INFO| hi"},
+		{"custom with unknown level", []TextOption{TextLevelDelimiters("(", ")")}, Level(42), "(42) hi"},
+	}
+
+	sink := &testBuffer{}
+	for _, tt := range tests {
+		enc := NewTextEncoder(append([]TextOption{TextNoTime()}, tt.opts...)...)
+		require.NoError(t, enc.WriteEntry(sink, "", "hi", tt.lvl, epoch), "Unexpected failure writing entry (%s).", tt.desc)
+		assert.Equal(t, tt.expected, sink.Stripped(), "Unexpected output (%s).", tt.desc)
+		sink.Reset()
+		enc.Free()
+	}
+
+	ansi := NewANSIEncoder(AnsiTextOption(TextNoTime()), AnsiTextOption(TextLevelDelimiters("<", ">")))
+	defer ansi.Free()
+	require.NoError(t, ansi.WriteEntry(sink, "", "hi", WarnLevel, epoch), "Unexpected failure writing entry.")
+	warn := ansi.(*ansiEncoder).levelColor(WarnLevel)
+	assert.Equal(t, warn+"<W> hi"+resetColor+"\n", sink.String(), "Expected the level color to cover the delimiters.")
+}
+
 func TestTextWriteEntryLevels(t *testing.T) {
 	tests := []struct {
 		level    Level
@@ -200,6 +337,84 @@ func TestTextWriteEntryFailure(t *testing.T) {
 	})
 }
 
+// A trickleWriter accepts at most one byte per call, reporting
+// io.ErrShortWrite if it's configured to.
+type trickleWriter struct {
+	testBuffer
+	shortErr bool
+}
+
+func (w *trickleWriter) Write(b []byte) (int, error) {
+	if len(b) == 0 {
+		return 0, nil
+	}
+	w.testBuffer.Write(b[:1])
+	if w.shortErr && len(b) > 1 {
+		return 1, io.ErrShortWrite
+	}
+	return 1, nil
+}
+
+// A stalledWriter never accepts any bytes.
+type stalledWriter struct {
+	calls int
+}
+
+func (w *stalledWriter) Write(b []byte) (int, error) {
+	w.calls++
+	return 0, io.ErrShortWrite
+}
+
+func TestTextWriteEntryRetriesShortWrites(t *testing.T) {
+	for _, shortErr := range []bool{false, true} {
+		enc := NewTextEncoder(TextNoTime())
+		enc.AddString("user", "jane")
+		sink := &trickleWriter{shortErr: shortErr}
+		assert.NoError(t, enc.WriteEntry(sink, "", "hello", InfoLevel, epoch), "Unexpected error writing to a slow sink.")
+		assert.Equal(t, "[I] hello user=jane\n", sink.String(), "Expected the full entry to reach a sink that writes one byte at a time.")
+		enc.Free()
+	}
+
+	ansi := NewANSIEncoder(AnsiTextOption(TextNoTime()))
+	defer ansi.Free()
+	sink := &trickleWriter{}
+	assert.NoError(t, ansi.WriteEntry(sink, "", "hello", InfoLevel, epoch), "Unexpected error writing to a slow sink.")
+	assert.Equal(t, defaultInfoColor+"[I] hello"+resetColor+"\n", sink.String(), "Expected the full entry to reach a sink that writes one byte at a time.")
+}
+
+func TestTextWriteEntryStalledSink(t *testing.T) {
+	withTextEncoder(func(enc *textEncoder) {
+		sink := &stalledWriter{}
+		err := enc.WriteEntry(sink, "", "hello", InfoLevel, epoch)
+		assert.Error(t, err, "Expected an error when the sink stops accepting data.")
+		assert.Contains(t, err.Error(), "incomplete write: only wrote 0 of", "Unexpected error message.")
+		assert.Equal(t, _maxStalledWrites, sink.calls, "Expected writes to stop after repeatedly making no progress.")
+	})
+}
+
+func TestShortWriteError(t *testing.T) {
+	tests := []struct {
+		desc string
+		enc  Encoder
+		sink io.Writer
+		want ShortWriteError
+	}{
+		{"text", NewTextEncoder(TextNoTime()), &stalledWriter{}, ShortWriteError{Wrote: 0, Expected: 10}},
+		{"ANSI", NewANSIEncoder(AnsiTextOption(TextNoTime())), &stalledWriter{}, ShortWriteError{Wrote: 0, Expected: 21}},
+		{"JSON", NewJSONEncoder(NoTime()), spywrite.ShortWriter{}, ShortWriteError{Wrote: 30, Expected: 31}},
+	}
+
+	for _, tt := range tests {
+		err := tt.enc.WriteEntry(tt.sink, "", "hello", InfoLevel, epoch)
+		var swe *ShortWriteError
+		if assert.True(t, errors.As(err, &swe), "Expected a ShortWriteError from the %s encoder, got %v.", tt.desc, err) {
+			assert.Equal(t, tt.want, *swe, "Unexpected byte counts from the %s encoder.", tt.desc)
+			assert.Equal(t, fmt.Sprintf("incomplete write: only wrote %v of %v bytes", tt.want.Wrote, tt.want.Expected), err.Error(), "Expected the message to be unchanged.")
+		}
+		tt.enc.Free()
+	}
+}
+
 func TestTextTimeOptions(t *testing.T) {
 	epoch := time.Date(1970, time.January, 1, 0, 0, 0, 0, time.UTC)
 	entry := &Entry{Level: InfoLevel, Message: "Something happened.", Time: epoch}
@@ -217,3 +432,949 @@ func TestTextTimeOptions(t *testing.T) {
 	)
 
 }
+
+func TestTextSkipEmptyEntries(t *testing.T) {
+	tests := []struct {
+		desc     string
+		enc      Encoder
+		name     string
+		msg      string
+		expected string
+	}{
+		{"fully empty", NewTextEncoder(TextNoTime(), TextSkipEmptyEntries()), "", "", ""},
+		{"level and time only", NewTextEncoder(TextSkipEmptyEntries()), "", "", ""},
+		{"suppressed name", NewTextEncoder(TextNoName(), TextSkipEmptyEntries()), "logger", "", ""},
+		{"message", NewTextEncoder(TextNoTime(), TextSkipEmptyEntries()), "", "hello", "[I] hello\n"},
+		{"name", NewTextEncoder(TextNoTime(), TextSkipEmptyEntries()), "logger", "", "[I] logger \n"},
+		{"empty without option", NewTextEncoder(), "", "", "[I] 1970-01-01T00:00:00Z \n"},
+	}
+
+	for _, tt := range tests {
+		sink := &testBuffer{}
+		assert.NoError(t, tt.enc.WriteEntry(sink, tt.name, tt.msg, InfoLevel, epoch), "Unexpected failure writing %s entry.", tt.desc)
+		assert.Equal(t, tt.expected, sink.String(), "Unexpected output writing %s entry.", tt.desc)
+		tt.enc.Free()
+	}
+
+	enc := NewTextEncoder(TextNoTime(), TextSkipEmptyEntries())
+	enc.AddString("foo", "bar")
+	sink := &testBuffer{}
+	assert.NoError(t, enc.WriteEntry(sink, "", "", InfoLevel, epoch), "Unexpected failure writing entry with only fields.")
+	assert.Equal(t, "[I]  foo=bar\n", sink.String(), "Expected entries with fields to be written.")
+}
+
+func TestTextCloneKeepsOptions(t *testing.T) {
+	enc := NewTextEncoder(TextNoName())
+	clone := enc.Clone()
+	sink := &testBuffer{}
+	assert.NoError(t, clone.WriteEntry(sink, "logger", "hello", InfoLevel, epoch), "Unexpected failure writing entry.")
+	assert.Equal(t, "[I] 1970-01-01T00:00:00Z hello", sink.Stripped(), "Expected clones to inherit options.")
+	enc.Free()
+	clone.Free()
+}
+
+func TestTextBufferStatsHook(t *testing.T) {
+	var used, capacity, calls int
+	enc := NewTextEncoder(TextNoTime(), TextBufferStatsHook(func(u, c int) {
+		used, capacity = u, c
+		calls++
+	}))
+	defer enc.Free()
+
+	sink := &testBuffer{}
+	assert.NoError(t, enc.WriteEntry(sink, "", "hello", InfoLevel, epoch), "Unexpected failure writing entry.")
+	assert.Equal(t, 1, calls, "Expected the stats hook to be called once per entry.")
+	assert.Equal(t, sink.Len(), used, "Expected used bytes to match the written entry.")
+	assert.True(t, capacity >= used, "Expected capacity to be at least the used length.")
+
+	enc.AddString("big", string(make([]byte, 2*_initialBufSize)))
+	sink.Reset()
+	assert.NoError(t, enc.WriteEntry(sink, "", "hello", InfoLevel, epoch), "Unexpected failure writing entry.")
+	assert.Equal(t, 2, calls, "Expected the stats hook to be called once per entry.")
+	assert.Equal(t, sink.Len(), used, "Expected used bytes to match the written entry.")
+	assert.True(t, capacity > _initialBufSize, "Expected the buffer to grow for large entries.")
+}
+
+func TestTextFatalHook(t *testing.T) {
+	sink := &testBuffer{}
+	var written []string
+	enc := NewTextEncoder(TextNoTime(), FatalHook(func() {
+		written = append(written, sink.Stripped())
+	}))
+	defer enc.Free()
+
+	for _, lvl := range []Level{DebugLevel, InfoLevel, WarnLevel, ErrorLevel, PanicLevel} {
+		assert.NoError(t, enc.WriteEntry(sink, "", "not fatal", lvl, epoch), "Unexpected failure writing entry.")
+	}
+	assert.Empty(t, written, "Expected the fatal hook to fire only for fatal entries.")
+
+	sink.Reset()
+	assert.NoError(t, enc.WriteEntry(sink, "", "fatal", FatalLevel, epoch), "Unexpected failure writing entry.")
+	assert.Equal(t, []string{"[F] fatal"}, written, "Expected the fatal hook to fire after the entry was written.")
+
+	written = nil
+	assert.Error(t, enc.WriteEntry(spywrite.FailWriter{}, "", "fatal", FatalLevel, epoch), "Expected an error writing to a failing sink.")
+	assert.Empty(t, written, "Expected the fatal hook not to fire when the write fails.")
+}
+
+func TestTextSmartBytes(t *testing.T) {
+	tests := []struct {
+		desc     string
+		val      []byte
+		expected string
+	}{
+		{"text", []byte("hello, world"), `k="hello, world"`},
+		{"unicode text", []byte("héllo ✓"), `k="héllo ✓"`},
+		{"binary", []byte{0xde, 0xad, 0xbe, 0xef}, "k=0xDEADBEEF"},
+		{"mixed", []byte{'h', 'i', 0x00, 0xff}, "k=0x686900FF"},
+		{"control characters", []byte("line\nbreak"), "k=0x6C696E650A627265616B"},
+		{"empty", []byte{}, `k=""`},
+	}
+
+	for _, tt := range tests {
+		enc := NewTextEncoder(TextSmartBytes()).(*textEncoder)
+		enc.AddBytes("k", tt.val)
+		assert.Equal(t, tt.expected, string(enc.bytes), "Unexpected output adding %s bytes.", tt.desc)
+		enc.Free()
+	}
+}
+
+func TestTextLevelIcons(t *testing.T) {
+	tests := []struct {
+		level    Level
+		expected string
+	}{
+		{DebugLevel, "🐛 [D]"},
+		{InfoLevel, "✅ [I]"},
+		{WarnLevel, "⚠️ [W]"},
+		{ErrorLevel, "❌ [E]"},
+		{PanicLevel, "🔥 [P]"},
+		{FatalLevel, "💀 [F]"},
+		{Level(42), "[42]"},
+	}
+
+	sink := &testBuffer{}
+	enc := NewTextEncoder(TextNoTime(), TextLevelIcons())
+	defer enc.Free()
+	for _, tt := range tests {
+		assert.NoError(t, enc.WriteEntry(sink, "", "msg", tt.level, epoch), "Unexpected failure writing entry.")
+		assert.Equal(t, tt.expected+" msg", sink.Stripped(), "Unexpected icon for level %s.", tt.level)
+		sink.Reset()
+	}
+}
+
+func TestTextLevelLabels(t *testing.T) {
+	custom := map[Level]string{InfoLevel: "info", ErrorLevel: "err"}
+	tests := []struct {
+		desc     string
+		enc      Encoder
+		expected []string
+	}{
+		{
+			"default labels",
+			NewTextEncoder(TextNoTime()),
+			[]string{"[D] msg", "[I] msg", "[W] msg", "[E] msg", "[P] msg", "[F] msg", "[42] msg"},
+		},
+		{
+			"full level names",
+			NewTextEncoder(TextNoTime(), TextFullLevelNames()),
+			[]string{"[DEBUG] msg", "[INFO] msg", "[WARN] msg", "[ERROR] msg", "[PANIC] msg", "[FATAL] msg", "[42] msg"},
+		},
+		{
+			"custom labels",
+			NewTextEncoder(TextNoTime(), TextLevelLabels(custom)),
+			[]string{"[D] msg", "[info] msg", "[W] msg", "[err] msg", "[P] msg", "[F] msg", "[42] msg"},
+		},
+		{
+			"ANSI full level names",
+			NewANSIEncoder(AnsiTextOption(TextNoTime()), AnsiTextOption(TextFullLevelNames())),
+			[]string{"[DEBUG] msg", "[INFO] msg", "[WARN] msg", "[ERROR] msg", "[PANIC] msg", "[FATAL] msg", "[42] msg"},
+		},
+	}
+	// Changing the caller's map after configuring the encoder has no effect.
+	custom[InfoLevel] = "changed"
+
+	for _, tt := range tests {
+		sink := &testBuffer{}
+		for _, lvl := range []Level{DebugLevel, InfoLevel, WarnLevel, ErrorLevel, PanicLevel, FatalLevel, Level(42)} {
+			assert.NoError(t, tt.enc.WriteEntry(sink, "", "msg", lvl, epoch), "Unexpected failure writing entry.")
+		}
+		lines := sink.Lines()
+		for i := range lines {
+			lines[i] = stripEscapes(lines[i])
+		}
+		assert.Equal(t, tt.expected, lines, "Unexpected level labels with %s.", tt.desc)
+		tt.enc.Free()
+	}
+}
+
+func TestTextCustomLevelIcons(t *testing.T) {
+	sink := &testBuffer{}
+	enc := NewTextEncoder(TextNoTime(), TextLevelIconsOnly(), TextLevelIcon(InfoLevel, "i"))
+	defer enc.Free()
+
+	assert.NoError(t, enc.WriteEntry(sink, "", "msg", InfoLevel, epoch), "Unexpected failure writing entry.")
+	assert.NoError(t, enc.WriteEntry(sink, "", "msg", WarnLevel, epoch), "Unexpected failure writing entry.")
+	assert.Equal(t, []string{"i msg", "⚠️ msg"}, sink.Lines(), "Expected custom icons to replace level labels.")
+	assert.Equal(t, "✅", _defaultLevelIcons[InfoLevel], "Customizing icons shouldn't modify the defaults.")
+}
+
+func TestTextFormatHeader(t *testing.T) {
+	enc := NewTextEncoder(TextFormatHeader())
+	defer enc.Free()
+	clone := enc.Clone()
+	defer clone.Free()
+
+	first, second := &testBuffer{}, &testBuffer{}
+	for _, e := range []Encoder{enc, clone} {
+		assert.NoError(t, e.WriteEntry(first, "", "hello", InfoLevel, epoch), "Unexpected failure writing entry.")
+	}
+	assert.Equal(t, []string{
+		"#zap-format: text v1 fields=level,time,name,msg",
+		"[I] 1970-01-01T00:00:00Z hello",
+		"[I] 1970-01-01T00:00:00Z hello",
+	}, first.Lines(), "Expected the format header exactly once per sink.")
+
+	noTime := NewTextEncoder(TextFormatHeader(), TextNoTime(), TextNoName())
+	defer noTime.Free()
+	assert.NoError(t, noTime.WriteEntry(second, "", "hello", InfoLevel, epoch), "Unexpected failure writing entry.")
+	assert.Equal(t, []string{
+		"#zap-format: text v1 fields=level,msg",
+		"[I] hello",
+	}, second.Lines(), "Expected the format header to reflect the encoder's options.")
+}
+
+func TestTextFormatHeaderBounded(t *testing.T) {
+	enc := NewTextEncoder(TextFormatHeader(), TextNoTime())
+	defer enc.Free()
+	set := enc.(*textEncoder).headerSinks
+
+	sinks := make([]*testBuffer, _maxTrackedSinks+10)
+	for i := range sinks {
+		sinks[i] = &testBuffer{}
+		require.NoError(t, enc.WriteEntry(sinks[i], "", "hello", InfoLevel, epoch), "Unexpected failure writing entry.")
+		if i == 0 {
+			continue
+		}
+		// Keep the first sink in use so that it isn't evicted.
+		require.NoError(t, enc.WriteEntry(sinks[0], "", "hello", InfoLevel, epoch), "Unexpected failure writing entry.")
+	}
+	assert.Len(t, set.seen, _maxTrackedSinks, "Expected the set of sinks to be bounded.")
+	_, ok := set.seen[sinks[0]]
+	assert.True(t, ok, "Expected recently written sinks to be remembered.")
+	_, ok = set.seen[sinks[1]]
+	assert.False(t, ok, "Expected the least recently written sink to be forgotten.")
+
+	headers := 0
+	for _, line := range sinks[0].Lines() {
+		if strings.HasPrefix(line, "#zap-format:") {
+			headers++
+		}
+	}
+	assert.Equal(t, 1, headers, "Expected a sink in constant use to receive the header once.")
+
+	require.NoError(t, enc.WriteEntry(sinks[1], "", "again", InfoLevel, epoch), "Unexpected failure writing entry.")
+	assert.Equal(t, []string{
+		"#zap-format: text v1 fields=level,name,msg",
+		"[I] hello",
+		"#zap-format: text v1 fields=level,name,msg",
+		"[I] again",
+	}, sinks[1].Lines(), "Expected forgotten sinks to receive the header again.")
+}
+
+func TestTextDualClock(t *testing.T) {
+	withFakeClock(func(advance func(time.Duration)) {
+		enc := NewTextEncoder(TextDualClock(), TextFormatHeader())
+		defer enc.Free()
+		clone := enc.Clone()
+		defer clone.Free()
+
+		sink := &testBuffer{}
+		advance(1500 * time.Millisecond)
+		assert.NoError(t, enc.WriteEntry(sink, "", "first", InfoLevel, epoch), "Unexpected failure writing entry.")
+		advance(time.Microsecond)
+		assert.NoError(t, clone.WriteEntry(sink, "", "second", InfoLevel, epoch), "Unexpected failure writing entry.")
+		assert.Equal(t, []string{
+			"#zap-format: text v1 fields=level,time,mono,name,msg",
+			"[I] 1970-01-01T00:00:00Z mono=1500000000 first",
+			"[I] 1970-01-01T00:00:00Z mono=1500001000 second",
+		}, sink.Lines(), "Expected wall-clock and monotonic timestamps in the header.")
+	})
+
+	noTime := NewTextEncoder(TextDualClock(), TextNoTime())
+	defer noTime.Free()
+	sink := &testBuffer{}
+	assert.NoError(t, noTime.WriteEntry(sink, "", "hello", InfoLevel, epoch), "Unexpected failure writing entry.")
+	assert.Regexp(t, `^\[I\] mono=\d+ hello$`, sink.Stripped(), "Expected only the monotonic timestamp without wall-clock times.")
+}
+
+func TestTextDualClockIncreases(t *testing.T) {
+	enc := NewTextEncoder(TextDualClock(), TextNoTime())
+	defer enc.Free()
+	sink := &testBuffer{}
+
+	monoRE := regexp.MustCompile(`mono=(\d+)`)
+	last := int64(-1)
+	for i := 0; i < 3; i++ {
+		time.Sleep(time.Millisecond)
+		require.NoError(t, enc.WriteEntry(sink, "", "tick", InfoLevel, time.Now()), "Unexpected failure writing entry.")
+		match := monoRE.FindStringSubmatch(sink.Stripped())
+		require.Len(t, match, 2, "Expected a monotonic timestamp in %q.", sink.Stripped())
+		mono, err := strconv.ParseInt(match[1], 10, 64)
+		require.NoError(t, err, "Failed to parse monotonic timestamp.")
+		assert.True(t, mono > last, "Expected monotonic timestamps to increase, got %d after %d.", mono, last)
+		last = mono
+		sink.Reset()
+	}
+}
+
+func TestTextWriteBOM(t *testing.T) {
+	enc := NewTextEncoder(TextWriteBOM(), TextNoTime())
+	defer enc.Free()
+	clone := enc.Clone()
+	defer clone.Free()
+
+	first, second := &testBuffer{}, &testBuffer{}
+	for _, e := range []Encoder{enc, clone, enc} {
+		assert.NoError(t, e.WriteEntry(first, "", "hello", InfoLevel, epoch), "Unexpected failure writing entry.")
+	}
+	assert.Equal(t, "\xef\xbb\xbf[I] hello\n[I] hello\n[I] hello\n", first.String(), "Expected the BOM exactly once, at the start of the sink.")
+
+	withHeader := NewTextEncoder(TextWriteBOM(), TextFormatHeader(), TextNoTime(), TextNoName())
+	defer withHeader.Free()
+	assert.NoError(t, withHeader.WriteEntry(second, "", "hello", InfoLevel, epoch), "Unexpected failure writing entry.")
+	assert.Equal(t, []string{
+		"\xef\xbb\xbf#zap-format: text v1 fields=level,msg",
+		"[I] hello",
+	}, second.Lines(), "Expected the BOM to precede the format header.")
+}
+
+func TestTextFileBoundaryMarker(t *testing.T) {
+	enc := NewTextEncoder(TextFileBoundaryMarker(), TextNoName())
+	defer enc.Free()
+	clone := enc.Clone()
+	defer clone.Free()
+
+	later := epoch.Add(time.Hour)
+	first, second := &testBuffer{}, &testBuffer{}
+	assert.NoError(t, enc.WriteEntry(first, "", "hello", InfoLevel, epoch), "Unexpected failure writing entry.")
+	assert.NoError(t, clone.WriteEntry(first, "", "hello", InfoLevel, epoch), "Unexpected failure writing entry.")
+	assert.NoError(t, clone.WriteEntry(second, "", "rotated", InfoLevel, later), "Unexpected failure writing entry.")
+	assert.NoError(t, enc.WriteEntry(second, "", "rotated", InfoLevel, later), "Unexpected failure writing entry.")
+	assert.Equal(t, []string{
+		"--- rotated at 1970-01-01T00:00:00Z ---",
+		"[I] 1970-01-01T00:00:00Z hello",
+		"[I] 1970-01-01T00:00:00Z hello",
+	}, first.Lines(), "Expected the boundary marker exactly once per sink.")
+	assert.Equal(t, []string{
+		"--- rotated at 1970-01-01T01:00:00Z ---",
+		"[I] 1970-01-01T01:00:00Z rotated",
+		"[I] 1970-01-01T01:00:00Z rotated",
+	}, second.Lines(), "Expected a new sink to receive its own boundary marker.")
+
+	withHeader := NewTextEncoder(TextFileBoundaryMarker(), TextFormatHeader(), TextNoTime(), TextNoName())
+	defer withHeader.Free()
+	third := &testBuffer{}
+	assert.NoError(t, withHeader.WriteEntry(third, "", "hello", InfoLevel, epoch), "Unexpected failure writing entry.")
+	assert.Equal(t, []string{
+		"#zap-format: text v1 fields=level,msg",
+		"--- rotated at 1970-01-01T00:00:00Z ---",
+		"[I] hello",
+	}, third.Lines(), "Expected the boundary marker to follow the format header.")
+}
+
+type countingStringer struct{ calls int }
+
+func (c *countingStringer) String() string {
+	c.calls++
+	return "formatted"
+}
+
+func TestTextWriteEntryf(t *testing.T) {
+	enc := NewTextEncoder(TextNoTime(), TextMinLevel(WarnLevel)).(*textEncoder)
+	defer enc.Free()
+	arg := &countingStringer{}
+	sink := &testBuffer{}
+
+	assert.NoError(t, enc.WriteEntryf(sink, "", InfoLevel, epoch, "value: %v", arg), "Unexpected failure writing a filtered entry.")
+	assert.Equal(t, 0, arg.calls, "Expected filtered entries not to be formatted.")
+	assert.Equal(t, "", sink.String(), "Expected filtered entries not to be written.")
+
+	assert.NoError(t, enc.WriteEntryf(sink, "", WarnLevel, epoch, "value: %v (%d)", arg, 42), "Unexpected failure writing entry.")
+	assert.Equal(t, 1, arg.calls, "Expected the message to be formatted once.")
+	assert.Equal(t, "[W] value: formatted (42)", sink.Stripped(), "Unexpected formatted output.")
+
+	assert.Equal(t, errNilSink, enc.WriteEntryf(nil, "", WarnLevel, epoch, "%v", arg), "Expected an error writing to a nil sink.")
+	assert.Equal(t, 1, arg.calls, "Expected nothing to be formatted for a nil sink.")
+}
+
+func TestTextSplitMessage(t *testing.T) {
+	tests := []struct {
+		desc     string
+		msg      string
+		expected string
+	}{
+		{"fits", "hello", "[I] hello foo=bar"},
+		{"exact", "0123456789", "[I] 0123456789 foo=bar"},
+		{"three chunks", "0123456789abcdefghijKLMNO", "[I] 0123456789 msg_1=abcdefghij msg_2=KLMNO foo=bar"},
+		{"multi-byte runes", "日本語日本語日本語日本語", "[I] 日本語日本語日本語日 msg_1=本語 foo=bar"},
+		{"leading space", "0123456789 abc", `[I] 0123456789 msg_1=" abc" foo=bar`},
+		{"inner spaces", "0123456789a b c d", `[I] 0123456789 msg_1="a b c d" foo=bar`},
+	}
+
+	for _, tt := range tests {
+		enc := NewTextEncoder(TextNoTime(), TextSplitMessage(10))
+		enc.AddString("foo", "bar")
+		sink := &testBuffer{}
+		assert.NoError(t, enc.WriteEntry(sink, "", tt.msg, InfoLevel, epoch), "Unexpected failure writing entry (%s).", tt.desc)
+		assert.Equal(t, tt.expected, sink.Stripped(), "Unexpected output (%s).", tt.desc)
+		enc.Free()
+	}
+}
+
+type clockAdvancingWriter struct {
+	testBuffer
+	advance func(time.Duration)
+}
+
+func (w *clockAdvancingWriter) Write(p []byte) (int, error) {
+	w.advance(time.Second)
+	return w.testBuffer.Write(p)
+}
+
+func TestTextSelfTiming(t *testing.T) {
+	var durations []time.Duration
+	hook := func(d time.Duration) { durations = append(durations, d) }
+
+	enc := NewTextEncoder(TextSelfTiming(hook))
+	defer enc.Free()
+	assert.NoError(t, enc.WriteEntry(&testBuffer{}, "", "hello", InfoLevel, epoch), "Unexpected failure writing entry.")
+	require.Len(t, durations, 1, "Expected the hook to be called once per entry.")
+	assert.True(t, durations[0] > 0 && durations[0] < time.Second, "Expected a plausible encoding duration, got %v.", durations[0])
+
+	withFakeClock(func(advance func(time.Duration)) {
+		durations = nil
+		enc := NewTextEncoder(TextSelfTiming(hook)).(*textEncoder)
+		defer enc.Free()
+		enc.AddLazy("slow", func() interface{} {
+			advance(5 * time.Millisecond)
+			return "done"
+		})
+		sink := &clockAdvancingWriter{advance: advance}
+		assert.NoError(t, enc.WriteEntry(sink, "", "hello", InfoLevel, epoch), "Unexpected failure writing entry.")
+		assert.Equal(t, []time.Duration{5 * time.Millisecond}, durations, "Expected the timing to exclude the sink write.")
+	})
+}
+
+func TestTextRunID(t *testing.T) {
+	runPattern := regexp.MustCompile(`^\[I\] hello foo=bar run=([0-9a-f]+)$`)
+	runID := func(enc Encoder) string {
+		sink := &testBuffer{}
+		require.NoError(t, enc.WriteEntry(sink, "", "hello", InfoLevel, epoch), "Unexpected failure writing entry.")
+		match := runPattern.FindStringSubmatch(sink.Stripped())
+		require.Len(t, match, 2, "Unexpected output: %s", sink.Stripped())
+		return match[1]
+	}
+
+	enc := NewTextEncoder(TextNoTime(), TextRunID())
+	defer enc.Free()
+	enc.AddString("foo", "bar")
+	clone := enc.Clone()
+	defer clone.Free()
+	first := runID(enc)
+	assert.Equal(t, first, runID(enc), "Expected the same run ID on every entry.")
+	assert.Equal(t, first, runID(clone), "Expected clones to share the run ID.")
+
+	other := NewTextEncoder(TextNoTime(), TextRunID())
+	defer other.Free()
+	other.AddString("foo", "bar")
+	assert.NotEqual(t, first, runID(other), "Expected different encoders to generate different run IDs.")
+}
+
+func TestTextHeaderFieldSeparator(t *testing.T) {
+	tests := []struct {
+		desc     string
+		sep      string
+		fields   bool
+		expected string
+	}{
+		{"longer separator", " | ", true, "[I] a b c | foo=bar baz=1 lazy=x id=z"},
+		{"single byte", "\t", true, "[I] a b c\tfoo=bar baz=1 lazy=x id=z"},
+		{"empty", "", true, "[I] a b cfoo=bar baz=1 lazy=x id=z"},
+		{"only generated fields", " | ", false, "[I] a b c | lazy=x id=z"},
+	}
+
+	for _, tt := range tests {
+		enc := NewTextEncoder(TextNoTime(), TextHeaderFieldSeparator(tt.sep)).(*textEncoder)
+		if tt.fields {
+			enc.AddString("foo", "bar")
+			enc.AddInt("baz", 1)
+		}
+		enc.AddLazy("lazy", func() interface{} { return "x" })
+		enc.AddLazy("id", func() interface{} { return "z" })
+		sink := &testBuffer{}
+		assert.NoError(t, enc.WriteEntry(sink, "", "a b c", InfoLevel, epoch), "Unexpected failure writing entry (%s).", tt.desc)
+		assert.Equal(t, tt.expected, sink.Stripped(), "Unexpected output (%s).", tt.desc)
+		enc.Free()
+	}
+
+	enc := NewTextEncoder(TextNoTime(), TextHeaderFieldSeparator(" | "))
+	defer enc.Free()
+	sink := &testBuffer{}
+	assert.NoError(t, enc.WriteEntry(sink, "", "no fields", InfoLevel, epoch), "Unexpected failure writing entry.")
+	assert.Equal(t, "[I] no fields", sink.Stripped(), "Expected no separator without fields.")
+}
+
+func TestTextThousandsSeparator(t *testing.T) {
+	tests := []struct {
+		sep      byte
+		add      func(*textEncoder)
+		expected string
+	}{
+		{',', func(enc *textEncoder) { enc.AddInt("n", 1000000) }, "n=1,000,000"},
+		{',', func(enc *textEncoder) { enc.AddInt64("n", -1234567) }, "n=-1,234,567"},
+		{',', func(enc *textEncoder) { enc.AddInt64("n", math.MinInt64) }, "n=-9,223,372,036,854,775,808"},
+		{',', func(enc *textEncoder) { enc.AddUint64("n", math.MaxUint64) }, "n=18,446,744,073,709,551,615"},
+		{',', func(enc *textEncoder) { enc.AddUint("n", 100000) }, "n=100,000"},
+		{',', func(enc *textEncoder) { enc.AddInt("n", 999) }, "n=999"},
+		{',', func(enc *textEncoder) { enc.AddInt("n", -999) }, "n=-999"},
+		{',', func(enc *textEncoder) { enc.AddInt("n", 0) }, "n=0"},
+		{'_', func(enc *textEncoder) { enc.AddInt("n", 1000) }, "n=1_000"},
+		{'_', func(enc *textEncoder) { enc.AddInt("n", -12345) }, "n=-12_345"},
+	}
+
+	for _, tt := range tests {
+		assertTextOutput(t, "grouped integer", tt.expected, func(e Encoder) {
+			enc := e.(*textEncoder)
+			TextThousandsSeparator(tt.sep).apply(enc)
+			tt.add(enc)
+		})
+	}
+}
+
+func TestTextQuoteStringsDisabled(t *testing.T) {
+	enc := NewTextEncoder(TextNoTime(), TextQuoteStrings(false))
+	defer enc.Free()
+	enc.AddString("my key", "hello world")
+	enc.AddString("empty", "")
+	sink := &testBuffer{}
+	assert.NoError(t, enc.WriteEntry(sink, "", "hello", InfoLevel, epoch), "Unexpected failure writing entry.")
+	assert.Equal(t, "[I] hello my key=hello world empty=", sink.Stripped(), "Expected raw strings with quoting disabled.")
+}
+
+type deadlineRecorder struct {
+	testBuffer
+	deadlines []time.Time
+	err       error
+}
+
+func (d *deadlineRecorder) SetWriteDeadline(t time.Time) error {
+	d.deadlines = append(d.deadlines, t)
+	return d.err
+}
+
+func TestTextWriteTimeout(t *testing.T) {
+	defer func() { _timeNow = time.Now }()
+	_timeNow = func() time.Time { return epoch }
+
+	enc := NewTextEncoder(TextNoTime(), TextWriteTimeout(time.Second))
+	defer enc.Free()
+
+	sink := &deadlineRecorder{}
+	assert.NoError(t, enc.WriteEntry(sink, "", "hello", InfoLevel, epoch), "Unexpected failure writing entry.")
+	assert.Equal(t, []time.Time{epoch.Add(time.Second)}, sink.deadlines, "Expected a write deadline before each write.")
+	assert.Equal(t, "[I] hello", sink.Stripped(), "Unexpected output.")
+
+	sink = &deadlineRecorder{err: errors.New("can't set deadline")}
+	assert.Error(t, enc.WriteEntry(sink, "", "hello", InfoLevel, epoch), "Expected deadline errors to be returned.")
+	assert.Equal(t, 0, sink.Len(), "Expected no write if the deadline can't be set.")
+
+	plain := NewTextEncoder(TextNoTime())
+	defer plain.Free()
+	sink = &deadlineRecorder{}
+	assert.NoError(t, plain.WriteEntry(sink, "", "hello", InfoLevel, epoch), "Unexpected failure writing entry.")
+	assert.Empty(t, sink.deadlines, "Expected no deadlines without a timeout.")
+}
+
+func TestTextWriteTimeoutExceeded(t *testing.T) {
+	// Nothing ever reads from the pipe, so writes block until the deadline.
+	conn, other := net.Pipe()
+	defer conn.Close()
+	defer other.Close()
+
+	enc := NewTextEncoder(TextWriteTimeout(10 * time.Millisecond))
+	defer enc.Free()
+
+	done := make(chan error, 1)
+	go func() { done <- enc.WriteEntry(conn, "", "hello", InfoLevel, epoch) }()
+	select {
+	case err := <-done:
+		netErr, ok := err.(net.Error)
+		require.True(t, ok, "Expected a net.Error, got %v.", err)
+		assert.True(t, netErr.Timeout(), "Expected a timeout error.")
+	case <-time.After(time.Second):
+		t.Fatal("WriteEntry didn't respect the write timeout.")
+	}
+}
+
+func TestTextEntryUUID(t *testing.T) {
+	uuidPattern := regexp.MustCompile(`^\[I\] hello foo=bar id=([0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12})$`)
+
+	enc := NewTextEncoder(TextNoTime(), TextEntryUUID())
+	defer enc.Free()
+	enc.AddString("foo", "bar")
+
+	sink := &testBuffer{}
+	const n = 1000
+	for i := 0; i < n; i++ {
+		require.NoError(t, enc.WriteEntry(sink, "", "hello", InfoLevel, epoch), "Unexpected failure writing entry.")
+	}
+
+	seen := make(map[string]struct{}, n)
+	for _, line := range sink.Lines() {
+		match := uuidPattern.FindStringSubmatch(line)
+		require.NotNil(t, match, "Expected a version 4 UUID in %q.", line)
+		seen[match[1]] = struct{}{}
+	}
+	assert.Len(t, seen, n, "Expected every entry to have a unique ID.")
+}
+
+func TestTextQuietHours(t *testing.T) {
+	at := func(hour, min int) time.Time {
+		return time.Date(2016, time.October, 1, hour, min, 0, 0, time.UTC)
+	}
+	tests := []struct {
+		desc    string
+		enc     Encoder
+		lvl     Level
+		t       time.Time
+		written bool
+	}{
+		{"info inside window", NewTextEncoder(TextQuietHours(1*time.Hour, 5*time.Hour, ErrorLevel)), InfoLevel, at(3, 0), false},
+		{"warn inside window", NewTextEncoder(TextQuietHours(1*time.Hour, 5*time.Hour, ErrorLevel)), WarnLevel, at(1, 0), false},
+		{"error inside window", NewTextEncoder(TextQuietHours(1*time.Hour, 5*time.Hour, ErrorLevel)), ErrorLevel, at(3, 0), true},
+		{"info after window", NewTextEncoder(TextQuietHours(1*time.Hour, 5*time.Hour, ErrorLevel)), InfoLevel, at(5, 0), true},
+		{"info before window", NewTextEncoder(TextQuietHours(1*time.Hour, 5*time.Hour, ErrorLevel)), InfoLevel, at(0, 59), true},
+		{"info in wrapped window", NewTextEncoder(TextQuietHours(22*time.Hour, 6*time.Hour, ErrorLevel)), InfoLevel, at(23, 30), false},
+		{"info in wrapped window after midnight", NewTextEncoder(TextQuietHours(22*time.Hour, 6*time.Hour, ErrorLevel)), InfoLevel, at(2, 0), false},
+		{"info outside wrapped window", NewTextEncoder(TextQuietHours(22*time.Hour, 6*time.Hour, ErrorLevel)), InfoLevel, at(12, 0), true},
+		{"fatal in wrapped window", NewTextEncoder(TextQuietHours(22*time.Hour, 6*time.Hour, ErrorLevel)), FatalLevel, at(2, 0), true},
+	}
+
+	for _, tt := range tests {
+		sink := &testBuffer{}
+		assert.NoError(t, tt.enc.WriteEntry(sink, "", "hello", tt.lvl, tt.t), "Unexpected failure writing entry %s.", tt.desc)
+		assert.Equal(t, tt.written, sink.Len() > 0, "Unexpected quiet-hours filtering for %s.", tt.desc)
+		tt.enc.Free()
+	}
+}
+
+func TestTextAddEnum(t *testing.T) {
+	statuses := map[int]string{0: "Pending", 1: "Active", -1: "Failed"}
+	tests := []struct {
+		val      int
+		names    map[int]string
+		expected string
+	}{
+		{0, statuses, "k=Pending(0)"},
+		{1, statuses, "k=Active(1)"},
+		{-1, statuses, "k=Failed(-1)"},
+		{42, statuses, "k=42"},
+		{1, nil, "k=1"},
+	}
+
+	for _, tt := range tests {
+		withTextEncoder(func(enc *textEncoder) {
+			enc.AddEnum("k", tt.val, tt.names)
+			assert.Equal(t, tt.expected, string(enc.bytes), "Unexpected output for enum value %d.", tt.val)
+		})
+	}
+}
+
+func TestTextSizedInts(t *testing.T) {
+	tests := []struct {
+		desc     string
+		f        func(Encoder)
+		expected string
+	}{
+		{"min int32", func(e Encoder) { e.AddInt32("k", math.MinInt32) }, "k=-2147483648"},
+		{"max int32", func(e Encoder) { e.AddInt32("k", math.MaxInt32) }, "k=2147483647"},
+		{"min int16", func(e Encoder) { e.AddInt16("k", math.MinInt16) }, "k=-32768"},
+		{"max int16", func(e Encoder) { e.AddInt16("k", math.MaxInt16) }, "k=32767"},
+		{"min int8", func(e Encoder) { e.AddInt8("k", math.MinInt8) }, "k=-128"},
+		{"max int8", func(e Encoder) { e.AddInt8("k", math.MaxInt8) }, "k=127"},
+		{"max uint32", func(e Encoder) { e.AddUint32("k", math.MaxUint32) }, "k=4294967295"},
+		{"max uint16", func(e Encoder) { e.AddUint16("k", math.MaxUint16) }, "k=65535"},
+		{"max uint8", func(e Encoder) { e.AddUint8("k", math.MaxUint8) }, "k=255"},
+		{"zero uint8", func(e Encoder) { e.AddUint8("k", 0) }, "k=0"},
+	}
+
+	for _, tt := range tests {
+		assertTextOutput(t, tt.desc, tt.expected, tt.f)
+	}
+}
+
+func TestTextSizedIntsContext(t *testing.T) {
+	withTextEncoder(func(enc *textEncoder) {
+		enc.AddInt32("i32", -1)
+		enc.AddInt16("i16", -2)
+		enc.AddInt8("i8", -3)
+		enc.AddUint32("u32", 1)
+		enc.AddUint16("u16", 2)
+		enc.AddUint8("u8", 3)
+		assert.Equal(t, []Field{
+			Int32("i32", -1),
+			Int16("i16", -2),
+			Int8("i8", -3),
+			Uint32("u32", 1),
+			Uint16("u16", 2),
+			Uint8("u8", 3),
+		}, enc.Context(), "Expected sized integers to be recorded with their widths.")
+	})
+}
+
+func TestOtherEncodersSizedInts(t *testing.T) {
+	add := func(e Encoder) {
+		e.AddInt32("a", math.MinInt32)
+		e.AddInt16("b", math.MinInt16)
+		e.AddInt8("c", math.MinInt8)
+		e.AddUint32("d", math.MaxUint32)
+		e.AddUint16("e", math.MaxUint16)
+		e.AddUint8("f", math.MaxUint8)
+	}
+
+	json := NewJSONEncoder().(*jsonEncoder)
+	defer json.Free()
+	add(json)
+	assert.Equal(t, `"a":-2147483648,"b":-32768,"c":-128,"d":4294967295,"e":65535,"f":255`, string(json.bytes), "Unexpected JSON integers.")
+
+	qs := NewQueryStringEncoder().(*queryStringEncoder)
+	defer qs.Free()
+	add(qs)
+	assert.Equal(t, "a=-2147483648&b=-32768&c=-128&d=4294967295&e=65535&f=255", string(qs.bytes), "Unexpected query string integers.")
+
+	yaml := NewYAMLFlowEncoder().(*yamlFlowEncoder)
+	defer yaml.Free()
+	add(yaml)
+	assert.Equal(t, "a: -2147483648, b: -32768, c: -128, d: 4294967295, e: 65535, f: 255", string(yaml.bytes), "Unexpected YAML integers.")
+
+	add(NullEncoder())
+}
+
+func TestTextEncoderAddRune(t *testing.T) {
+	tests := []struct {
+		desc     string
+		val      rune
+		expected string
+	}{
+		{"ascii", 'a', "ch='a'"},
+		{"two bytes", 'é', "ch='é'"},
+		{"three bytes", '✓', "ch='✓'"},
+		{"four bytes", '\U0001F600', "ch='😀'"},
+		{"newline", '\n', `ch='\n'`},
+		{"quote", '\'', `ch='\''`},
+		{"control", '\x00', `ch='\x00'`},
+		{"negative", -1, "ch='�'"},
+		{"surrogate", 0xD800, "ch='�'"},
+		{"out of range", 0x110000, "ch='�'"},
+	}
+
+	for _, tt := range tests {
+		assertTextOutput(t, tt.desc, tt.expected, func(e Encoder) {
+			e.AddRune("ch", tt.val)
+		})
+	}
+}
+
+func TestOtherEncodersAddRune(t *testing.T) {
+	json := NewJSONEncoder().(*jsonEncoder)
+	defer json.Free()
+	json.AddRune("a", '✓')
+	json.AddRune("b", '\n')
+	json.AddRune("c", -1)
+	assert.Equal(t, `"a":"✓","b":"\n","c":"`+"�"+`"`, string(json.bytes), "Unexpected JSON runes.")
+
+	yaml := NewYAMLFlowEncoder().(*yamlFlowEncoder)
+	defer yaml.Free()
+	yaml.AddRune("a", '✓')
+	yaml.AddRune("b", ':')
+	assert.Equal(t, `a: ✓, b: ":"`, string(yaml.bytes), "Unexpected YAML runes.")
+
+	qs := NewQueryStringEncoder().(*queryStringEncoder)
+	defer qs.Free()
+	qs.AddRune("a", ' ')
+	assert.Equal(t, "a=+", string(qs.bytes), "Unexpected query string rune.")
+
+	NullEncoder().AddRune("a", 'a')
+}
+
+func TestTextEncoderAddBase64Bytes(t *testing.T) {
+	assertTextOutput(t, "base64", "blob=3q2+7w==", func(e Encoder) {
+		e.AddBase64Bytes("blob", []byte{0xde, 0xad, 0xbe, 0xef})
+	})
+	assertTextOutput(t, "empty base64", "blob=", func(e Encoder) {
+		e.AddBase64Bytes("blob", nil)
+	})
+
+	payload := make([]byte, 4096)
+	for i := range payload {
+		payload[i] = byte(i * 7)
+	}
+	withTextEncoder(func(enc *textEncoder) {
+		enc.AddBase64Bytes("blob", payload)
+		encoded := strings.TrimPrefix(string(enc.bytes), "blob=")
+		decoded, err := base64.StdEncoding.DecodeString(encoded)
+		require.NoError(t, err, "Expected valid base64.")
+		assert.Equal(t, payload, decoded, "Expected base64 to round-trip to the original bytes.")
+	})
+}
+
+func TestAppendBase64(t *testing.T) {
+	for _, size := range []int{0, 1, 2, 3, 100, _initialBufSize} {
+		val := make([]byte, size)
+		for i := range val {
+			val[i] = byte(i)
+		}
+		prefix := []byte("prefix")
+		buf := appendBase64(prefix[:len(prefix):len(prefix)], val)
+		assert.Equal(t, "prefix"+base64.StdEncoding.EncodeToString(val), string(buf), "Unexpected base64 for %d bytes.", size)
+	}
+}
+
+func TestOtherEncodersAddBase64Bytes(t *testing.T) {
+	val := []byte{0xfb, 0xff}
+
+	json := NewJSONEncoder().(*jsonEncoder)
+	defer json.Free()
+	json.AddBase64Bytes("b", val)
+	assert.Equal(t, `"b":"+/8="`, string(json.bytes), "Unexpected JSON base64.")
+
+	yaml := NewYAMLFlowEncoder().(*yamlFlowEncoder)
+	defer yaml.Free()
+	yaml.AddBase64Bytes("b", val)
+	assert.Equal(t, `b: "+/8="`, string(yaml.bytes), "Unexpected YAML base64.")
+
+	qs := NewQueryStringEncoder().(*queryStringEncoder)
+	defer qs.Free()
+	qs.AddBase64Bytes("b", val)
+	assert.Equal(t, "b=%2B%2F8%3D", string(qs.bytes), "Unexpected query string base64.")
+
+	NullEncoder().AddBase64Bytes("b", val)
+}
+
+func TestTextKeyValueSeparator(t *testing.T) {
+	addFields := func(e Encoder) {
+		e.AddString("user", "jane")
+		e.AddInt("n", 1)
+		e.AddMarshaler("obj", loggable{true})
+		e.(*textEncoder).AddLabel("svc", "api")
+	}
+	tests := []struct {
+		desc     string
+		opts     []TextOption
+		expected string
+	}{
+		{"default", nil, "[I] {svc=api} hello user=jane n=1 obj={loggable=yes}"},
+		{"explicit default", []TextOption{TextKeyValueSeparator("=")}, "[I] {svc=api} hello user=jane n=1 obj={loggable=yes}"},
+		{"empty", []TextOption{TextKeyValueSeparator("")}, "[I] {svc=api} hello user=jane n=1 obj={loggable=yes}"},
+		{"colon", []TextOption{TextKeyValueSeparator(":")}, "[I] {svc:api} hello user:jane n:1 obj:{loggable:yes}"},
+		{"colon space", []TextOption{TextKeyValueSeparator(": ")}, "[I] {svc: api} hello user: jane n: 1 obj: {loggable: yes}"},
+		{"spaced equals", []TextOption{TextKeyValueSeparator(" = ")}, "[I] {svc = api} hello user = jane n = 1 obj = {loggable = yes}"},
+	}
+
+	for _, tt := range tests {
+		enc := NewTextEncoder(append([]TextOption{TextNoTime()}, tt.opts...)...)
+		addFields(enc)
+		sink := &testBuffer{}
+		require.NoError(t, enc.WriteEntry(sink, "", "hello", InfoLevel, epoch), "Unexpected failure writing entry.")
+		assert.Equal(t, tt.expected, sink.Stripped(), "Unexpected output with %s separator.", tt.desc)
+		enc.Free()
+	}
+}
+
+func TestTextKeyValueSeparatorQuoting(t *testing.T) {
+	enc := NewTextEncoder(TextNoTime(), TextKeyValueSeparator(":"))
+	defer enc.Free()
+	enc.AddString("a:b", "c")
+	enc.AddString("url", "http://example.com")
+	enc.AddString("k", "a=b")
+	assert.Equal(t, `"a:b":c url:"http://example.com" k:"a=b"`, string(enc.(*textEncoder).bytes), "Expected strings containing the separator to be quoted.")
+
+	unquoted := NewTextEncoder(TextNoTime(), TextKeyValueSeparator(":"), TextQuoteStrings(false))
+	defer unquoted.Free()
+	unquoted.AddString("a:b", "c")
+	assert.Equal(t, "a:b:c", string(unquoted.(*textEncoder).bytes), "Expected no quoting when it's disabled.")
+}
+
+func TestTextKeyValueSeparatorWithRewrites(t *testing.T) {
+	enc := NewTextEncoder(TextNoTime(), TextKeyValueSeparator(" = "), TextDedupeKeys(DedupeKeepLast), TextEmitSize())
+	defer enc.Free()
+	enc.AddString("a", "1")
+	enc.AddString("b", "2")
+	enc.AddString("a", "3")
+	sink := &testBuffer{}
+	require.NoError(t, enc.WriteEntry(sink, "", "hi", InfoLevel, epoch), "Unexpected failure writing entry.")
+	assert.Equal(t, "[I] hi b = 2 a = 3 _size = 30\n", sink.String(), "Expected deduplication to account for the separator.")
+
+	ansi := NewANSIEncoder(AnsiTextOption(TextNoTime()), AnsiTextOption(TextKeyValueSeparator(": ")), ANSIKeyColor("black+h"))
+	defer ansi.Free()
+	ansi.AddString("user", "jane")
+	sink.Reset()
+	require.NoError(t, ansi.WriteEntry(sink, "", "hi", InfoLevel, epoch), "Unexpected failure writing entry.")
+	info := ansi.(*ansiEncoder).levelColor(InfoLevel)
+	assert.Equal(t, info+"[I] hi \x1b[0;90muser"+resetColor+info+": jane"+resetColor+"\n", sink.String(), "Expected keys to be colored up to the separator.")
+}
+
+func TestTextEncoderAddUintptr(t *testing.T) {
+	assertTextOutput(t, "uintptr", "addr=0xDEADBEEF", func(e Encoder) { e.AddUintptr("addr", 0xdeadbeef) })
+	assertTextOutput(t, "zero uintptr", "addr=0x0", func(e Encoder) { e.AddUintptr("addr", 0) })
+	assertTextOutput(t, "nil pointer", "ptr=0x0", func(e Encoder) { e.AddPointer("ptr", nil) })
+
+	val := new(int)
+	expected := "ptr=0x" + strings.ToUpper(strings.TrimPrefix(fmt.Sprintf("%p", val), "0x"))
+	assertTextOutput(t, "pointer", expected, func(e Encoder) { e.AddPointer("ptr", unsafe.Pointer(val)) })
+
+	ansi := NewANSIEncoder().(*ansiEncoder)
+	defer ansi.Free()
+	ansi.AddPointer("ptr", unsafe.Pointer(val))
+	assert.Equal(t, expected, string(ansi.bytes), "Expected the ANSI encoder to render pointers like the text encoder.")
+}
+
+func TestAppendAddress(t *testing.T) {
+	tests := []struct {
+		addr     uintptr
+		expected string
+	}{
+		{0, "0x0"},
+		{0xf, "0xF"},
+		{0x10, "0x10"},
+		{0xc000012345, "0xC000012345"},
+		{^uintptr(0), "0x" + strings.Repeat("F", 2*int(unsafe.Sizeof(uintptr(0))))},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.expected, string(appendAddress(nil, tt.addr)), "Unexpected address for %d.", tt.addr)
+	}
+}
+
+func TestOtherEncodersAddUintptr(t *testing.T) {
+	json := NewJSONEncoder().(*jsonEncoder)
+	defer json.Free()
+	json.AddUintptr("a", 0xbeef)
+	json.AddPointer("p", nil)
+	assert.Equal(t, `"a":"0xBEEF","p":"0x0"`, string(json.bytes), "Unexpected JSON addresses.")
+
+	yaml := NewYAMLFlowEncoder().(*yamlFlowEncoder)
+	defer yaml.Free()
+	yaml.AddUintptr("a", 0xbeef)
+	yaml.AddPointer("p", nil)
+	assert.Equal(t, `a: 0xBEEF, p: 0x0`, string(yaml.bytes), "Unexpected YAML addresses.")
+
+	qs := NewQueryStringEncoder().(*queryStringEncoder)
+	defer qs.Free()
+	qs.AddUintptr("a", 0xbeef)
+	qs.AddPointer("p", nil)
+	assert.Equal(t, "a=0xBEEF&p=0x0", string(qs.bytes), "Unexpected query string addresses.")
+
+	NullEncoder().AddUintptr("a", 0xbeef)
+	NullEncoder().AddPointer("p", nil)
+}
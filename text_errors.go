@@ -0,0 +1,115 @@
+// Copyright (c) 2016 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zap
+
+import (
+	"strconv"
+	"strings"
+)
+
+// _maxErrorChain bounds how many causes errorChain follows, which protects
+// against errors whose causes form a cycle.
+const _maxErrorChain = 32
+
+// A causer is an error that wraps another, in the style of
+// github.com/pkg/errors.
+type causer interface {
+	Cause() error
+}
+
+// A wrapper is an error that wraps another, in the style of the standard
+// library.
+type wrapper interface {
+	Unwrap() error
+}
+
+// unwrapError returns the error that err wraps, if any.
+func unwrapError(err error) error {
+	switch e := err.(type) {
+	case causer:
+		return e.Cause()
+	case wrapper:
+		return e.Unwrap()
+	}
+	return nil
+}
+
+// errorChain returns the error's message followed by the messages of the
+// errors it wraps, joined by ": ". Since wrapping errors usually include the
+// wrapped message in their own, causes whose messages already end the chain
+// are skipped.
+func errorChain(err error) string {
+	msg := err.Error()
+	for i, cause := 0, unwrapError(err); cause != nil && i < _maxErrorChain; i, cause = i+1, unwrapError(cause) {
+		causeMsg := cause.Error()
+		if strings.HasSuffix(msg, causeMsg) {
+			continue
+		}
+		msg += ": " + causeMsg
+	}
+	return msg
+}
+
+// AddError adds an error's message, followed by the messages of any errors
+// it wraps with Cause() error or Unwrap() error methods (e.g.,
+// "err=\"query failed: connection refused\""). A nil error is written as
+// "<nil>".
+func (enc *textEncoder) AddError(key string, err error) {
+	enc.addKey(key)
+	if err == nil {
+		enc.bytes = append(enc.bytes, "<nil>"...)
+		return
+	}
+	if !enc.secretScan {
+		// Otherwise, Context would return the unmasked error.
+		enc.recordField(Field{key: key, fieldType: errorType, obj: err})
+	}
+	enc.bytes = enc.appendString(enc.bytes, enc.scanSecrets(errorChain(err)))
+}
+
+// A fieldsError is an error that carries structured context.
+type fieldsError interface {
+	error
+	Fields() []Field
+}
+
+// AddErrorGroup adds a group of errors, such as those collected from
+// concurrent operations, under indexed sub-keys. Each error's message is added
+// as "key.N.error", and errors with a Fields() []Field method also add their
+// fields under the same index (e.g., "errs.0.error=timeout errs.0.code=504
+// errs.1.error=EOF").
+func (enc *textEncoder) AddErrorGroup(key string, errs []error) {
+	for i, err := range errs {
+		if err == nil {
+			continue
+		}
+		prefix := key + "." + strconv.Itoa(i) + "."
+		enc.AddString(prefix+"error", err.Error())
+		fe, ok := err.(fieldsError)
+		if !ok {
+			continue
+		}
+		for _, f := range fe.Fields() {
+			f.key = prefix + f.key
+			f.AddTo(enc)
+		}
+	}
+}
@@ -0,0 +1,137 @@
+// Copyright (c) 2016 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zap
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// causedError wraps another error without including its message, in the
+// style of github.com/pkg/errors.
+type causedError struct {
+	msg   string
+	cause error
+}
+
+func (e causedError) Error() string { return e.msg }
+func (e causedError) Cause() error  { return e.cause }
+
+// selfCausedError is its own cause.
+type selfCausedError struct{}
+
+func (e selfCausedError) Error() string { return "loop" }
+func (e selfCausedError) Cause() error  { return e }
+
+type structuredError struct {
+	msg  string
+	code int
+}
+
+func (e structuredError) Error() string { return e.msg }
+
+func (e structuredError) Fields() []Field {
+	return []Field{Int("code", e.code), String("retry", "no")}
+}
+
+func TestTextAddErrorGroup(t *testing.T) {
+	tests := []struct {
+		desc     string
+		errs     []error
+		expected string
+	}{
+		{
+			"structured and plain errors",
+			[]error{structuredError{"timeout", 504}, errors.New("EOF"), structuredError{"denied", 403}},
+			"errs.0.error=timeout errs.0.code=504 errs.0.retry=no errs.1.error=EOF errs.2.error=denied errs.2.code=403 errs.2.retry=no",
+		},
+		{"nil errors", []error{nil, errors.New("EOF")}, "errs.1.error=EOF"},
+	}
+
+	for _, tt := range tests {
+		assertTextOutput(t, tt.desc, tt.expected, func(e Encoder) {
+			e.(*textEncoder).AddErrorGroup("errs", tt.errs)
+		})
+	}
+
+	withTextEncoder(func(enc *textEncoder) {
+		enc.AddErrorGroup("errs", nil)
+		assert.Empty(t, enc.bytes, "Expected an empty group to add nothing.")
+	})
+}
+
+func TestTextAddError(t *testing.T) {
+	root := errors.New("connection refused")
+	tests := []struct {
+		desc     string
+		err      error
+		expected string
+	}{
+		{"nil error", nil, "err=<nil>"},
+		{"plain error", errors.New("EOF"), "err=EOF"},
+		{"error with spaces", root, `err="connection refused"`},
+		{"Cause chain", causedError{"query", causedError{"dial", root}}, `err="query: dial: connection refused"`},
+		{"Unwrap chain", fmt.Errorf("query failed: %w", root), `err="query failed: connection refused"`},
+		{"mixed chain", causedError{"retry", fmt.Errorf("query failed: %w", root)}, `err="retry: query failed: connection refused"`},
+		{"self-caused error", selfCausedError{}, "err=loop"},
+	}
+
+	for _, tt := range tests {
+		assertTextOutput(t, tt.desc, tt.expected, func(e Encoder) {
+			e.AddError("err", tt.err)
+		})
+	}
+}
+
+func TestTextAddErrorContext(t *testing.T) {
+	err := causedError{"query", errors.New("EOF")}
+	withTextEncoder(func(enc *textEncoder) {
+		enc.AddError("err", err)
+		assert.Equal(t, []Field{{key: "err", fieldType: errorType, obj: err}}, enc.Context(), "Expected the error to be recorded as a typed field.")
+	})
+}
+
+func TestOtherEncodersAddError(t *testing.T) {
+	err := causedError{"query", errors.New("EOF")}
+
+	json := NewJSONEncoder().(*jsonEncoder)
+	defer json.Free()
+	json.AddError("err", err)
+	json.AddError("none", nil)
+	assert.Equal(t, `"err":"query: EOF","none":null`, string(json.bytes), "Unexpected JSON errors.")
+
+	qs := NewQueryStringEncoder().(*queryStringEncoder)
+	defer qs.Free()
+	qs.AddError("err", err)
+	qs.AddError("none", nil)
+	assert.Equal(t, "err=query%3A+EOF&none=", string(qs.bytes), "Unexpected query string errors.")
+
+	yaml := NewYAMLFlowEncoder().(*yamlFlowEncoder)
+	defer yaml.Free()
+	yaml.AddError("err", err)
+	yaml.AddError("none", nil)
+	assert.Equal(t, `err: "query: EOF", none: null`, string(yaml.bytes), "Unexpected YAML errors.")
+
+	NullEncoder().AddError("err", err)
+}
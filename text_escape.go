@@ -0,0 +1,57 @@
+// Copyright (c) 2016 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zap
+
+import "strings"
+
+// appendRaw appends s without quoting it. If the TextEscapeNewlines option
+// is enabled, newlines, carriage returns, and tabs are replaced with their
+// backslash escapes.
+func (enc *textEncoder) appendRaw(buf []byte, s string) []byte {
+	if !enc.escapeNewlines || !strings.ContainsAny(s, "\n\r\t") {
+		return append(buf, s...)
+	}
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; c {
+		case '\n':
+			buf = append(buf, '\\', 'n')
+		case '\r':
+			buf = append(buf, '\\', 'r')
+		case '\t':
+			buf = append(buf, '\\', 't')
+		default:
+			buf = append(buf, c)
+		}
+	}
+	return buf
+}
+
+// TextEscapeNewlines replaces newlines, carriage returns, and tabs in
+// messages, logger names, keys, and string values with their backslash
+// escapes (e.g., "\n"), so every entry is written as a single line even when
+// strings aren't quoted. Unlike the quoting done by TextQuoteStrings, it
+// doesn't add quotes or escape any other characters. Since messages are never
+// quoted, this is the only way to keep multi-line messages on one line.
+func TextEscapeNewlines() TextOption {
+	return textOptionFunc(func(enc *textEncoder) {
+		enc.escapeNewlines = true
+	})
+}
@@ -0,0 +1,122 @@
+// Copyright (c) 2016 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zap
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTextEscapeNewlines(t *testing.T) {
+	tests := []struct {
+		desc     string
+		opts     []TextOption
+		name     string
+		msg      string
+		f        func(Encoder)
+		expected string
+	}{
+		{
+			desc:     "multi-line message",
+			msg:      "first\nsecond\r\n\tthird",
+			expected: `[I] first\nsecond\r\n\tthird`,
+		},
+		{
+			desc:     "logger name",
+			name:     "a\nb",
+			msg:      "hi",
+			expected: `[I] a\nb hi`,
+		},
+		{
+			desc:     "quoted values",
+			msg:      "hi",
+			f:        func(e Encoder) { e.AddString("stack", "a\nb") },
+			expected: `[I] hi stack="a\nb"`,
+		},
+		{
+			desc: "unquoted keys and values",
+			opts: []TextOption{TextQuoteStrings(false)},
+			msg:  "hi",
+			f: func(e Encoder) {
+				e.AddString("k\ney", "a\nb\tc")
+				e.AddError("err", errors.New("x\ny"))
+			},
+			expected: `[I] hi k\ney=a\nb\tc err=x\ny`,
+		},
+		{
+			desc: "array elements",
+			opts: []TextOption{TextQuoteStrings(false)},
+			msg:  "hi",
+			f: func(e Encoder) {
+				e.(*textEncoder).AddArray("lines", ArrayMarshalerFunc(func(arr ArrayEncoder) error {
+					arr.AppendString("a\nb")
+					return nil
+				}))
+			},
+			expected: `[I] hi lines=[a\nb]`,
+		},
+		{
+			desc:     "split message",
+			opts:     []TextOption{TextSplitMessage(3)},
+			msg:      "ab\ncd\n",
+			expected: `[I] ab\n msg_1="cd\n"`,
+		},
+		{
+			desc:     "other control characters",
+			msg:      "a\x00b",
+			expected: "[I] a\x00b",
+		},
+	}
+
+	for _, tt := range tests {
+		opts := append([]TextOption{TextNoTime(), TextEscapeNewlines()}, tt.opts...)
+		enc := NewTextEncoder(opts...)
+		if tt.f != nil {
+			tt.f(enc)
+		}
+		buf := &testBuffer{}
+		require.NoError(t, enc.WriteEntry(buf, tt.name, tt.msg, InfoLevel, epoch), "Unexpected error writing entry (%s).", tt.desc)
+		assert.Equal(t, tt.expected+"\n", buf.String(), "Unexpected output (%s).", tt.desc)
+		assert.Equal(t, 1, strings.Count(buf.String(), "\n"), "Expected a single physical line (%s).", tt.desc)
+		enc.Free()
+	}
+}
+
+func TestTextEscapeNewlinesDisabled(t *testing.T) {
+	enc := NewTextEncoder(TextNoTime())
+	defer enc.Free()
+	buf := &testBuffer{}
+	require.NoError(t, enc.WriteEntry(buf, "", "first\nsecond", InfoLevel, epoch), "Unexpected error writing entry.")
+	assert.Equal(t, "[I] first\nsecond\n", buf.String(), "Expected messages to be written verbatim by default.")
+}
+
+func TestANSIEscapeNewlines(t *testing.T) {
+	enc := NewANSIEncoder(AnsiTextOption(TextNoTime()), AnsiTextOption(TextEscapeNewlines()), ANSIHyperlinkCaller("https://example.com/"))
+	defer enc.Free()
+	buf := &testBuffer{}
+	require.NoError(t, enc.WriteEntry(buf, "", "foo.go:42: first\nsecond", InfoLevel, epoch), "Unexpected error writing entry.")
+	assert.Equal(t, `[I] foo.go:42: first\nsecond`, stripEscapes(buf.Stripped()), "Unexpected ANSI output.")
+	assert.Equal(t, 1, strings.Count(buf.String(), "\n"), "Expected a single physical line.")
+}
@@ -0,0 +1,135 @@
+// Copyright (c) 2016 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zap
+
+import (
+	"strconv"
+	"time"
+	"unicode/utf8"
+)
+
+// _maxExemplarRunes is the OpenMetrics limit on the combined length of an
+// exemplar's label names and values.
+const _maxExemplarRunes = 128
+
+// traceContext returns the values of the last top-level trace_id and span_id
+// fields in the accumulated fields and final. It must be called before
+// final's fields are rewritten by options like TextDeltaFields.
+func (enc *textEncoder) traceContext(final *textEncoder) (traceID, spanID string) {
+	kept := len(enc.keptFields())
+	for _, cf := range enc.context {
+		end := cf.end
+		if end < 0 {
+			end = kept
+		}
+		switch cf.field.key {
+		case "trace_id":
+			traceID = unquoteValue(enc.bytes[cf.start:end])
+		case "span_id":
+			spanID = unquoteValue(enc.bytes[cf.start:end])
+		}
+	}
+	for _, cf := range final.context {
+		end := cf.end
+		if end < 0 {
+			end = len(final.bytes)
+		}
+		switch cf.field.key {
+		case "trace_id":
+			traceID = unquoteValue(final.bytes[cf.start:end])
+		case "span_id":
+			spanID = unquoteValue(final.bytes[cf.start:end])
+		}
+	}
+	return traceID, spanID
+}
+
+// unquoteValue returns a rendered value as a string, unquoting it if it was
+// quoted. Rendered values are used so that trace IDs are found however
+// they were added (e.g., with AddString or AddObject).
+func unquoteValue(rendered []byte) string {
+	if s, err := strconv.Unquote(string(rendered)); err == nil {
+		return s
+	}
+	return string(rendered)
+}
+
+// addExemplar ends the entry with an exemplar, if the option is enabled. It's
+// added after the fields, including the size, so that it ends the line.
+func (enc *textEncoder) addExemplar(final *textEncoder, t time.Time) {
+	if enc.exemplars {
+		final.bytes = appendExemplar(final.bytes, final.traceID, final.spanID, t)
+	}
+}
+
+// appendExemplar appends an OpenMetrics exemplar for an entry with the given
+// trace context, or nothing if there's no trace ID.
+func appendExemplar(buf []byte, traceID, spanID string, t time.Time) []byte {
+	if traceID == "" {
+		return buf
+	}
+	runes := len("trace_id") + utf8.RuneCountInString(traceID)
+	if runes > _maxExemplarRunes {
+		return buf
+	}
+	buf = append(buf, ` # {trace_id="`...)
+	buf = appendExemplarValue(buf, traceID)
+	buf = append(buf, '"')
+	if spanID != "" && runes+len("span_id")+utf8.RuneCountInString(spanID) <= _maxExemplarRunes {
+		buf = append(buf, `,span_id="`...)
+		buf = appendExemplarValue(buf, spanID)
+		buf = append(buf, '"')
+	}
+	buf = append(buf, "} 1 "...)
+	return appendEpoch(buf, t, 3)
+}
+
+// appendExemplarValue escapes a label value as OpenMetrics requires.
+func appendExemplarValue(buf []byte, s string) []byte {
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; c {
+		case '\\':
+			buf = append(buf, `\\`...)
+		case '"':
+			buf = append(buf, `\"`...)
+		case '\n':
+			buf = append(buf, `\n`...)
+		default:
+			buf = append(buf, c)
+		}
+	}
+	return buf
+}
+
+// TextOpenMetricsExemplars links entries to metrics: entries with a trace_id
+// field end with the trace context in the OpenMetrics exemplar format (e.g.,
+// `# {trace_id="4bf92f35",span_id="00f067aa"} 1 1467331200.000`), which
+// lets tooling that understands exemplars associate the entry with a metric
+// sample. The exemplar's value is always 1, for a single entry, and its
+// timestamp is the entry's. A span_id field is included if one's present and
+// it fits within the 128-character limit on exemplar labels; entries whose
+// trace ID alone exceeds the limit get no exemplar. The exemplar follows every
+// field, including TextEmitSize's "_size".
+func TextOpenMetricsExemplars() TextOption {
+	return textOptionFunc(func(enc *textEncoder) {
+		enc.exemplars = true
+	})
+}
@@ -0,0 +1,132 @@
+// Copyright (c) 2016 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zap
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTextOpenMetricsExemplars(t *testing.T) {
+	ts := time.Unix(1467331200, 123456789)
+	tests := []struct {
+		desc     string
+		f        func(Encoder)
+		expected string
+	}{
+		{
+			desc:     "no trace context",
+			f:        func(e Encoder) { e.AddString("user", "jane") },
+			expected: "[I] msg user=jane",
+		},
+		{
+			desc: "trace and span",
+			f: func(e Encoder) {
+				e.AddString("trace_id", "4bf92f3577b34da6a3ce929d0e0e4736")
+				e.AddString("span_id", "00f067aa0ba902b7")
+				e.AddString("user", "jane")
+			},
+			expected: `[I] msg trace_id=4bf92f3577b34da6a3ce929d0e0e4736 span_id=00f067aa0ba902b7 user=jane # {trace_id="4bf92f3577b34da6a3ce929d0e0e4736",span_id="00f067aa0ba902b7"} 1 1467331200.123`,
+		},
+		{
+			desc:     "trace only",
+			f:        func(e Encoder) { e.AddInt("trace_id", 42) },
+			expected: `[I] msg trace_id=42 # {trace_id="42"} 1 1467331200.123`,
+		},
+		{
+			desc:     "span only",
+			f:        func(e Encoder) { e.AddString("span_id", "00f067aa0ba902b7") },
+			expected: "[I] msg span_id=00f067aa0ba902b7",
+		},
+		{
+			desc:     "escaped values",
+			f:        func(e Encoder) { e.AddString("trace_id", "a \"b\"\\\nc") },
+			expected: `[I] msg trace_id="a \"b\"\\\nc" # {trace_id="a \"b\"\\\nc"} 1 1467331200.123`,
+		},
+		{
+			desc: "last trace ID wins",
+			f: func(e Encoder) {
+				e.AddString("trace_id", "old")
+				e.AddString("trace_id", "new")
+			},
+			expected: `[I] msg trace_id=old trace_id=new # {trace_id="new"} 1 1467331200.123`,
+		},
+		{
+			desc: "nested trace ID ignored",
+			f: func(e Encoder) {
+				e.AddMarshaler("obj", LogMarshalerFunc(func(kv KeyValue) error {
+					kv.AddString("trace_id", "nested")
+					return nil
+				}))
+			},
+			expected: "[I] msg obj={trace_id=nested}",
+		},
+		{
+			desc: "span ID over limit",
+			f: func(e Encoder) {
+				e.AddString("trace_id", strings.Repeat("a", 100))
+				e.AddString("span_id", strings.Repeat("b", 20))
+			},
+			expected: `[I] msg trace_id=` + strings.Repeat("a", 100) + ` span_id=` + strings.Repeat("b", 20) + ` # {trace_id="` + strings.Repeat("a", 100) + `"} 1 1467331200.123`,
+		},
+		{
+			desc:     "trace ID over limit",
+			f:        func(e Encoder) { e.AddString("trace_id", strings.Repeat("a", 121)) },
+			expected: `[I] msg trace_id=` + strings.Repeat("a", 121),
+		},
+	}
+
+	for _, tt := range tests {
+		enc := NewTextEncoder(TextNoTime(), TextOpenMetricsExemplars())
+		tt.f(enc)
+		sink := &testBuffer{}
+		require.NoError(t, enc.WriteEntry(sink, "", "msg", InfoLevel, ts), "Unexpected failure writing entry.")
+		assert.Equal(t, tt.expected, sink.Stripped(), "Unexpected output for %s.", tt.desc)
+		enc.Free()
+	}
+}
+
+func TestTextOpenMetricsExemplarsWithOtherOptions(t *testing.T) {
+	ts := time.Unix(1467331200, 0)
+
+	enc := NewTextEncoder(TextNoTime(), TextOpenMetricsExemplars(), TextDeltaFields(), TextEmitSize())
+	defer enc.Free()
+	enc.AddString("trace_id", "abc")
+	sink := &testBuffer{}
+	for i := 0; i < 2; i++ {
+		clone := enc.Clone()
+		clone.(*textEncoder).AddLazy("span_id", func() interface{} { return "def" })
+		require.NoError(t, clone.WriteEntry(sink, "", "msg", InfoLevel, ts), "Unexpected failure writing entry.")
+		clone.Free()
+	}
+	exemplar := ` # {trace_id="abc",span_id="def"} 1 1467331200.000`
+	first := "[I] msg trace_id=abc span_id=def _size=92" + exemplar
+	second := "[I] msg trace_id=^ span_id=^ _size=88" + exemplar
+	assert.Equal(t, []string{first, second}, sink.Lines(), "Expected exemplars to use values from before fields are compacted.")
+	for _, line := range sink.Lines() {
+		assert.True(t, strings.Contains(line, "_size="+strconv.Itoa(len(line)+1)), "Expected the size to include the exemplar.")
+	}
+}
@@ -0,0 +1,48 @@
+// Copyright (c) 2016 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zap
+
+// omitEmptyFields reports whether an entry written with the TextFieldsOnly
+// option should be left out because it has no fields. If so, the line is
+// removed from final, leaving anything that precedes it (e.g., a header).
+func (enc *textEncoder) omitEmptyFields(final *textEncoder, lineStart, fieldsStart int) bool {
+	if !enc.fieldsOnly || !enc.omitEmptyLines || len(final.bytes) > fieldsStart {
+		return false
+	}
+	final.bytes = final.bytes[:lineStart]
+	return true
+}
+
+// TextFieldsOnly writes only the fields of each entry, omitting the level,
+// timestamp, logger name, labels, and message. This is useful when embedding
+// the key=value portion of an entry in another record format. The fields
+// aren't preceded by a separator, so a line might look like
+//
+//	user=jane attempt=2
+//
+// Entries without any fields are written as an empty line, unless omitEmpty
+// is set, in which case nothing is written for them.
+func TextFieldsOnly(omitEmpty bool) TextOption {
+	return textOptionFunc(func(enc *textEncoder) {
+		enc.fieldsOnly = true
+		enc.omitEmptyLines = omitEmpty
+	})
+}
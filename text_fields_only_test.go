@@ -0,0 +1,111 @@
+// Copyright (c) 2016 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zap
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTextFieldsOnly(t *testing.T) {
+	tests := []struct {
+		desc     string
+		opts     []TextOption
+		f        func(Encoder)
+		expected string
+	}{
+		{
+			desc:     "accumulated fields",
+			f:        func(e Encoder) { e.AddString("user", "jane"); e.AddInt("attempt", 2) },
+			expected: "user=jane attempt=2\n",
+		},
+		{
+			desc:     "generated fields only",
+			opts:     []TextOption{TextComponent("api")},
+			expected: "component=api\n",
+		},
+		{
+			desc:     "accumulated and generated fields",
+			opts:     []TextOption{TextComponent("api")},
+			f:        func(e Encoder) { e.AddString("user", "jane") },
+			expected: "user=jane component=api\n",
+		},
+		{
+			desc:     "header separator ignored",
+			opts:     []TextOption{TextHeaderFieldSeparator(" | ")},
+			f:        func(e Encoder) { e.AddString("user", "jane") },
+			expected: "user=jane\n",
+		},
+		{
+			desc:     "no fields",
+			expected: "\n",
+		},
+		{
+			desc:     "no fields omitted",
+			opts:     []TextOption{TextFieldsOnly(true)},
+			expected: "",
+		},
+		{
+			desc:     "fields not omitted",
+			opts:     []TextOption{TextFieldsOnly(true)},
+			f:        func(e Encoder) { e.AddBool("ok", true) },
+			expected: "ok=true\n",
+		},
+	}
+
+	for _, tt := range tests {
+		enc := NewTextEncoder(append([]TextOption{TextFieldsOnly(false)}, tt.opts...)...)
+		if tt.f != nil {
+			tt.f(enc)
+		}
+		buf := &testBuffer{}
+		require.NoError(t, enc.WriteEntry(buf, "svc", "hello", InfoLevel, epoch), "Unexpected error writing entry (%s).", tt.desc)
+		assert.Equal(t, tt.expected, buf.String(), "Unexpected output (%s).", tt.desc)
+		assert.NotContains(t, buf.String(), "hello", "Expected the message to be omitted (%s).", tt.desc)
+		enc.Free()
+	}
+}
+
+func TestTextFieldsOnlyKeepsPreamble(t *testing.T) {
+	enc := NewTextEncoder(TextFieldsOnly(true), TextWriteBOM())
+	defer enc.Free()
+	buf := &testBuffer{}
+	require.NoError(t, enc.WriteEntry(buf, "", "hello", InfoLevel, epoch), "Unexpected error writing entry.")
+	assert.Equal(t, _utf8BOM, buf.String(), "Expected the BOM without an entry.")
+}
+
+func TestANSIFieldsOnly(t *testing.T) {
+	enc := NewANSIEncoder(ANSIFieldsOnly(false), ANSIKeyColor("blue"))
+	defer enc.Free()
+	enc.AddString("user", "jane")
+	buf := &testBuffer{}
+	require.NoError(t, enc.WriteEntry(buf, "svc", "hello", InfoLevel, epoch), "Unexpected error writing entry.")
+	assert.Equal(t, "user=jane", stripEscapes(buf.Stripped()), "Unexpected output.")
+	assert.NotEqual(t, "user=jane", buf.Stripped(), "Expected fields to be colored.")
+
+	empty := NewANSIEncoder(ANSIFieldsOnly(true))
+	defer empty.Free()
+	buf.Reset()
+	require.NoError(t, empty.WriteEntry(buf, "svc", "hello", InfoLevel, epoch), "Unexpected error writing entry.")
+	assert.Equal(t, "", buf.String(), "Expected nothing to be written without fields.")
+}
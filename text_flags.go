@@ -0,0 +1,65 @@
+// Copyright (c) 2016 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zap
+
+import (
+	"sort"
+	"strings"
+)
+
+// AddFlags adds the names of the true flags as a sorted, comma-separated list
+// (e.g., "flags=debug,trace,verbose"), which is much more compact than a
+// key=true pair per flag. False flags are omitted, so a map with no true flags
+// renders as an empty value. The list is quoted under the same rules as
+// AddString, so an empty list is written as `flags=""` by default.
+func (enc *textEncoder) AddFlags(key string, flags map[string]bool) {
+	names := make([]string, 0, len(flags))
+	for name, set := range flags {
+		if set {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	enc.addKey(key)
+	enc.bytes = enc.appendString(enc.bytes, strings.Join(names, ","))
+}
+
+// A flagEval records how a feature flag was evaluated.
+type flagEval struct {
+	flag, variant, reason string
+}
+
+func (f flagEval) MarshalLog(kv KeyValue) error {
+	kv.AddString("flag", f.flag)
+	kv.AddString("variant", f.variant)
+	kv.AddString("reason", f.reason)
+	return nil
+}
+
+// AddFlagEval adds the result of evaluating a feature flag as a nested block
+// with the flag's name, the variant it resolved to, and the reason it
+// resolved that way (e.g., `exp={flag=checkout variant=B reason="rule 3"}`).
+// All three keys are always present, so entries from experiments can be
+// parsed consistently.
+func (enc *textEncoder) AddFlagEval(key, flag, variant, reason string) {
+	enc.AddMarshaler(key, flagEval{flag: flag, variant: variant, reason: reason})
+}
@@ -0,0 +1,101 @@
+// Copyright (c) 2016 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zap
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTextAddFlags(t *testing.T) {
+	tests := []struct {
+		desc     string
+		flags    map[string]bool
+		expected string
+	}{
+		{"mixed flags", map[string]bool{"verbose": true, "debug": true, "color": false, "trace": true}, "flags=debug,trace,verbose"},
+		{"all-false flags", map[string]bool{"verbose": false, "debug": false}, `flags=""`},
+		{"nil flags", nil, `flags=""`},
+		{"names with spaces", map[string]bool{"dark mode": true, "beta": true}, `flags="beta,dark mode"`},
+	}
+
+	for _, tt := range tests {
+		assertTextOutput(t, tt.desc, tt.expected, func(e Encoder) {
+			e.(*textEncoder).AddFlags("flags", tt.flags)
+		})
+	}
+
+	withTextEncoder(func(enc *textEncoder) {
+		TextQuoteStrings(false).apply(enc)
+		enc.AddFlags("flags", map[string]bool{"debug": false})
+		assert.Equal(t, "flags=", string(enc.bytes), "Expected an empty value with quoting disabled.")
+	})
+}
+
+func TestTextAddFlagEval(t *testing.T) {
+	tests := []struct {
+		desc                  string
+		flag, variant, reason string
+		expected              string
+	}{
+		{"simple", "checkout", "B", "rule_match", "exp={flag=checkout variant=B reason=rule_match}"},
+		{"quoted reason", "new-ui", "on", "targeted user", `exp={flag=new-ui variant=on reason="targeted user"}`},
+		{"empty reason", "new-ui", "off", "", `exp={flag=new-ui variant=off reason=""}`},
+	}
+
+	for _, tt := range tests {
+		assertTextOutput(t, tt.desc, tt.expected, func(e Encoder) {
+			e.(*textEncoder).AddFlagEval("exp", tt.flag, tt.variant, tt.reason)
+		})
+	}
+}
+
+func TestTextAddFlagEvalEntry(t *testing.T) {
+	enc := NewTextEncoder(TextNoTime()).(*textEncoder)
+	defer enc.Free()
+	enc.AddFlagEval("exp", "checkout", "B", "default")
+	enc.AddString("user", "jane")
+
+	buf := &testBuffer{}
+	require.NoError(t, enc.WriteEntry(buf, "", "served", InfoLevel, epoch), "Unexpected failure writing entry.")
+	assert.Equal(t, "[I] served exp={flag=checkout variant=B reason=default} user=jane", buf.Stripped(), "Unexpected entry.")
+	assert.Equal(t, []Field{
+		Marshaler("exp", flagEval{flag: "checkout", variant: "B", reason: "default"}),
+		String("user", "jane"),
+	}, enc.Context(), "Expected the evaluation to be a single field in the context.")
+}
+
+func TestTextEncoderAddFlags(t *testing.T) {
+	assertTextEncoderOutput(t, "flags", "[I] hello flags=debug,verbose", func(enc TextEncoder) {
+		enc.AddFlags("flags", map[string]bool{"verbose": true, "debug": true, "trace": false})
+	})
+	assertTextEncoderOutput(t, "all-false flags", `[I] hello flags=""`, func(enc TextEncoder) {
+		enc.AddFlags("flags", map[string]bool{"trace": false})
+	})
+}
+
+func TestTextEncoderAddFlagEval(t *testing.T) {
+	assertTextEncoderOutput(t, "flag evaluation", `[I] hello exp={flag=checkout variant=B reason="rule 3"}`, func(enc TextEncoder) {
+		enc.AddFlagEval("exp", "checkout", "B", "rule 3")
+	})
+}
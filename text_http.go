@@ -0,0 +1,65 @@
+// Copyright (c) 2016 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zap
+
+import (
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// _redactedHeaders are the headers whose values AddHeaders omits, keyed by
+// canonical name.
+var _redactedHeaders = map[string]struct{}{
+	"Authorization":       {},
+	"Cookie":              {},
+	"Proxy-Authorization": {},
+	"Set-Cookie":          {},
+	"X-Api-Key":           {},
+}
+
+// AddHeaders adds HTTP headers as a nested object with sorted keys, joining
+// multiple values for the same header with commas (e.g.,
+// "req={Accept=text/html,application/json Authorization=REDACTED}"). The
+// values of headers that typically carry credentials, like Authorization and
+// Cookie, are redacted. The joined values are quoted under the same rules as
+// AddString.
+func (enc *textEncoder) AddHeaders(key string, h http.Header) {
+	names := make([]string, 0, len(h))
+	for name := range h {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	enc.addKey(key)
+	enc.bytes = append(enc.bytes, '{')
+	enc.depth++
+	for _, name := range names {
+		enc.addKey(name)
+		if _, ok := _redactedHeaders[http.CanonicalHeaderKey(name)]; ok {
+			enc.bytes = append(enc.bytes, "REDACTED"...)
+			continue
+		}
+		enc.bytes = enc.appendString(enc.bytes, enc.scanSecrets(strings.Join(h[name], ",")))
+	}
+	enc.depth--
+	enc.bytes = append(enc.bytes, '}')
+}
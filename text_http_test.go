@@ -0,0 +1,80 @@
+// Copyright (c) 2016 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zap
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTextAddHeaders(t *testing.T) {
+	h := http.Header{}
+	h.Add("X-Request-Id", "abc")
+	h.Add("Accept", "text/html")
+	h.Add("Accept", "application/json")
+	h.Set("Authorization", "Bearer secret")
+	h.Add("Set-Cookie", "a=1")
+	h.Add("Set-Cookie", "b=2")
+	h["x-api-key"] = []string{"non-canonical secret"}
+
+	assertTextOutput(t, "headers", "req={Accept=text/html,application/json Authorization=REDACTED Set-Cookie=REDACTED X-Request-Id=abc x-api-key=REDACTED}", func(e Encoder) {
+		e.(*textEncoder).AddHeaders("req", h)
+	})
+	assertTextOutput(t, "empty headers", "req={}", func(e Encoder) {
+		e.(*textEncoder).AddHeaders("req", nil)
+	})
+}
+
+func TestTextEncoderAddHeaders(t *testing.T) {
+	for _, e := range []Encoder{NewTextEncoder(TextNoTime()), NewANSIEncoder(AnsiTextOption(TextNoTime()))} {
+		enc := e.(TextEncoder)
+		enc.AddHeaders("req", http.Header{"Accept": {"text/html"}})
+		clone := enc.Clone().(TextEncoder)
+		clone.AddHeaders("resp", http.Header{"Server": {"zap"}})
+
+		sink := &testBuffer{}
+		require.NoError(t, clone.WriteEntry(sink, "", "hello", InfoLevel, epoch), "Unexpected failure writing entry.")
+		assert.Equal(t, "[I] hello req={Accept=text/html} resp={Server=zap}", stripEscapes(sink.Stripped()), "Unexpected output adding headers through the TextEncoder interface.")
+		clone.Free()
+		enc.Free()
+	}
+}
+
+func TestTextAddHeadersQuoting(t *testing.T) {
+	h := http.Header{}
+	h.Set("User-Agent", "Mozilla/5.0 (X11; Linux)")
+	h.Add("X-Tags", "a b")
+	h.Add("X-Tags", "c")
+	h.Set("X-Empty", "")
+	assertTextOutput(t, "header values with spaces", `req={User-Agent="Mozilla/5.0 (X11; Linux)" X-Empty="" X-Tags="a b,c"}`, func(e Encoder) {
+		e.(TextEncoder).AddHeaders("req", h)
+	})
+
+	enc := NewTextEncoder(TextNoTime(), TextEscapeNewlines(), TextQuoteStrings(false)).(TextEncoder)
+	defer enc.Free()
+	enc.AddHeaders("req", http.Header{"X-Multi": {"a\nb"}})
+	sink := &testBuffer{}
+	require.NoError(t, enc.WriteEntry(sink, "", "hello", InfoLevel, epoch), "Unexpected failure writing entry.")
+	assert.Equal(t, []string{`[I] hello req={X-Multi=a\nb}`}, sink.Lines(), "Expected newlines in header values to be escaped.")
+}
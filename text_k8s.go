@@ -0,0 +1,63 @@
+// Copyright (c) 2016 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zap
+
+import "os"
+
+// k8sMetadata describes the Kubernetes pod running the process, as exposed by
+// the downward API.
+type k8sMetadata struct {
+	pod, namespace, node string
+}
+
+func (m *k8sMetadata) MarshalLog(kv KeyValue) error {
+	if m.pod != "" {
+		kv.AddString("pod", m.pod)
+	}
+	if m.namespace != "" {
+		kv.AddString("ns", m.namespace)
+	}
+	if m.node != "" {
+		kv.AddString("node", m.node)
+	}
+	return nil
+}
+
+// TextK8sMetadata adds the Kubernetes pod name, namespace, and node name to
+// each entry as a nested block (e.g., "k8s={pod=api-1 ns=prod node=node-a}").
+// The values are read once, when the option is applied, from the POD_NAME,
+// POD_NAMESPACE, and NODE_NAME environment variables that are conventionally
+// populated using the downward API. Missing variables are omitted, and if
+// none are set, the block is omitted entirely.
+func TextK8sMetadata() TextOption {
+	return textOptionFunc(func(enc *textEncoder) {
+		m := &k8sMetadata{
+			pod:       os.Getenv("POD_NAME"),
+			namespace: os.Getenv("POD_NAMESPACE"),
+			node:      os.Getenv("NODE_NAME"),
+		}
+		if m.pod == "" && m.namespace == "" && m.node == "" {
+			enc.k8s = nil
+			return
+		}
+		enc.k8s = m
+	})
+}
@@ -0,0 +1,85 @@
+// Copyright (c) 2016 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zap
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func withEnv(env map[string]string, f func()) {
+	saved := make(map[string]string, len(env))
+	for k, v := range env {
+		saved[k] = os.Getenv(k)
+		if v == "" {
+			os.Unsetenv(k)
+		} else {
+			os.Setenv(k, v)
+		}
+	}
+	defer func() {
+		for k, v := range saved {
+			os.Setenv(k, v)
+			if v == "" {
+				os.Unsetenv(k)
+			}
+		}
+	}()
+	f()
+}
+
+func TestTextK8sMetadata(t *testing.T) {
+	tests := []struct {
+		desc     string
+		env      map[string]string
+		expected string
+	}{
+		{
+			"all set",
+			map[string]string{"POD_NAME": "api-1", "POD_NAMESPACE": "prod", "NODE_NAME": "node-a"},
+			"[I] hello foo=bar k8s={pod=api-1 ns=prod node=node-a}",
+		},
+		{
+			"some set",
+			map[string]string{"POD_NAME": "api-1", "POD_NAMESPACE": "", "NODE_NAME": "node-a"},
+			"[I] hello foo=bar k8s={pod=api-1 node=node-a}",
+		},
+		{
+			"none set",
+			map[string]string{"POD_NAME": "", "POD_NAMESPACE": "", "NODE_NAME": ""},
+			"[I] hello foo=bar",
+		},
+	}
+
+	for _, tt := range tests {
+		withEnv(tt.env, func() {
+			enc := NewTextEncoder(TextNoTime(), TextK8sMetadata())
+			defer enc.Free()
+			enc.AddString("foo", "bar")
+
+			sink := &testBuffer{}
+			assert.NoError(t, enc.WriteEntry(sink, "", "hello", InfoLevel, epoch), "Unexpected failure writing entry.")
+			assert.Equal(t, tt.expected, sink.Stripped(), "Unexpected output with %s.", tt.desc)
+		})
+	}
+}
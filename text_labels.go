@@ -0,0 +1,43 @@
+// Copyright (c) 2016 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zap
+
+// AddLabel adds a low-cardinality label, like a service name or region.
+// Labels are kept apart from regular fields and rendered in their own block
+// before the message (e.g., "[I] {service=api region=us} hello user=jane"),
+// which maps naturally onto label-based stores like Loki.
+func (enc *textEncoder) AddLabel(key, val string) {
+	if len(enc.labels) > 0 {
+		enc.labels = append(enc.labels, ' ')
+	}
+	enc.labels = enc.appendString(enc.labels, key)
+	enc.labels = append(enc.labels, enc.keyValueSep()...)
+	enc.labels = enc.appendString(enc.labels, enc.scanSecrets(val))
+}
+
+func (enc *textEncoder) addLabels(final *textEncoder) {
+	if len(enc.labels) == 0 {
+		return
+	}
+	final.bytes = append(final.bytes, " {"...)
+	final.bytes = append(final.bytes, enc.labels...)
+	final.bytes = append(final.bytes, '}')
+}
@@ -0,0 +1,78 @@
+// Copyright (c) 2016 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zap
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTextAddLabel(t *testing.T) {
+	enc := NewTextEncoder(TextNoTime())
+	defer enc.Free()
+	enc.(*textEncoder).AddLabel("service", "api")
+	enc.AddString("user", "jane")
+	enc.(*textEncoder).AddLabel("region", "us east")
+
+	sink := &testBuffer{}
+	require.NoError(t, enc.WriteEntry(sink, "", "hello", InfoLevel, epoch), "Unexpected error writing entry.")
+	assert.Equal(t, `[I] {service=api region="us east"} hello user=jane`+"\n", sink.String(), "Expected labels in their own block.")
+}
+
+func TestTextAddLabelClones(t *testing.T) {
+	parent := NewTextEncoder(TextNoTime())
+	defer parent.Free()
+	parent.(*textEncoder).AddLabel("service", "api")
+
+	child := parent.Clone()
+	defer child.Free()
+	child.(*textEncoder).AddLabel("region", "us")
+
+	sink := &testBuffer{}
+	require.NoError(t, parent.WriteEntry(sink, "", "parent", InfoLevel, epoch), "Unexpected error writing entry.")
+	require.NoError(t, child.WriteEntry(sink, "", "child", InfoLevel, epoch), "Unexpected error writing entry.")
+	assert.Equal(t, []string{
+		"[I] {service=api} parent",
+		"[I] {service=api region=us} child",
+	}, sink.Lines(), "Expected labels added to a clone not to affect the original.")
+}
+
+func TestTextAddLabelOnly(t *testing.T) {
+	enc := NewTextEncoder(TextNoTime(), TextSkipEmptyEntries())
+	defer enc.Free()
+	enc.(*textEncoder).AddLabel("service", "api")
+
+	sink := &testBuffer{}
+	require.NoError(t, enc.WriteEntry(sink, "", "", InfoLevel, epoch), "Unexpected error writing entry.")
+	assert.Equal(t, "[I] {service=api} \n", sink.String(), "Expected labels to count as content.")
+}
+
+func TestANSIAddLabel(t *testing.T) {
+	enc := NewANSIEncoder(AnsiTextOption(TextNoTime()))
+	defer enc.Free()
+	enc.(*ansiEncoder).AddLabel("service", "api")
+
+	sink := &testBuffer{}
+	require.NoError(t, enc.WriteEntry(sink, "", "hello", InfoLevel, epoch), "Unexpected error writing entry.")
+	assert.Equal(t, defaultInfoColor+"[I] {service=api} hello"+resetColor+"\n", sink.String(), "Expected labels in their own block.")
+}
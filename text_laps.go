@@ -0,0 +1,60 @@
+// Copyright (c) 2016 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zap
+
+import "time"
+
+// MarkLap records the current time as a lap of the named operation. Use
+// AddLaps to add the time between laps as a field.
+func (enc *textEncoder) MarkLap(name string) {
+	if enc.laps == nil {
+		enc.laps = make(map[string][]time.Time)
+	}
+	enc.laps[name] = append(enc.laps[name], _timeNow())
+}
+
+// AddLaps adds the durations between consecutive laps recorded under the key
+// as an array (e.g., "stages=[+1ms +3ms +2ms]"). Laps are cleared when the
+// encoder is truncated.
+func (enc *textEncoder) AddLaps(key string) {
+	enc.addKey(key)
+	enc.bytes = append(enc.bytes, '[')
+	laps := enc.laps[key]
+	for i := 1; i < len(laps); i++ {
+		if i > 1 {
+			enc.bytes = append(enc.bytes, ' ')
+		}
+		enc.bytes = append(enc.bytes, '+')
+		enc.bytes = append(enc.bytes, laps[i].Sub(laps[i-1]).String()...)
+	}
+	enc.bytes = append(enc.bytes, ']')
+}
+
+func copyLaps(laps map[string][]time.Time) map[string][]time.Time {
+	if laps == nil {
+		return nil
+	}
+	copied := make(map[string][]time.Time, len(laps))
+	for name, ts := range laps {
+		copied[name] = append([]time.Time(nil), ts...)
+	}
+	return copied
+}
@@ -0,0 +1,88 @@
+// Copyright (c) 2016 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zap
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func withFakeClock(f func(advance func(time.Duration))) {
+	defer func() { _timeNow = time.Now }()
+	now := epoch
+	_timeNow = func() time.Time { return now }
+	f(func(d time.Duration) { now = now.Add(d) })
+}
+
+func TestTextAddLaps(t *testing.T) {
+	withFakeClock(func(advance func(time.Duration)) {
+		withTextEncoder(func(enc *textEncoder) {
+			enc.MarkLap("stages")
+			for _, d := range []time.Duration{time.Millisecond, 3 * time.Millisecond, 2 * time.Millisecond} {
+				advance(d)
+				enc.MarkLap("stages")
+			}
+			enc.MarkLap("other")
+
+			enc.AddLaps("stages")
+			enc.AddLaps("other")
+			enc.AddLaps("missing")
+			assert.Equal(t, "stages=[+1ms +3ms +2ms] other=[] missing=[]", string(enc.bytes), "Unexpected lap output.")
+		})
+	})
+}
+
+func TestTextLapsCloneAndTruncate(t *testing.T) {
+	withFakeClock(func(advance func(time.Duration)) {
+		withTextEncoder(func(enc *textEncoder) {
+			enc.MarkLap("op")
+			clone := enc.Clone().(*textEncoder)
+			defer clone.Free()
+
+			advance(time.Second)
+			enc.MarkLap("op")
+			advance(time.Second)
+			clone.MarkLap("op")
+
+			clone.AddLaps("op")
+			assert.Equal(t, "op=[+2s]", string(clone.bytes), "Expected clones to have independent laps.")
+
+			enc.truncate()
+			enc.AddLaps("op")
+			assert.Equal(t, "op=[]", string(enc.bytes), "Expected truncate to clear laps.")
+		})
+	})
+}
+
+func TestTextEncoderAddLaps(t *testing.T) {
+	withFakeClock(func(advance func(time.Duration)) {
+		assertTextEncoderOutput(t, "laps", "[I] hello op=[+1ms +2ms]", func(enc TextEncoder) {
+			enc.MarkLap("op")
+			advance(time.Millisecond)
+			enc.MarkLap("op")
+			advance(2 * time.Millisecond)
+			enc.MarkLap("op")
+			enc.AddLaps("op")
+		})
+	})
+}
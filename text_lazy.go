@@ -0,0 +1,153 @@
+// Copyright (c) 2016 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zap
+
+import (
+	"fmt"
+	"time"
+)
+
+type lazyField struct {
+	key string
+	fn  func() interface{}
+}
+
+// AddLazy adds a field whose value is computed only when an entry is written.
+// If the entry is dropped (e.g., by TextMinLevel), fn is never called, which
+// saves the cost of computing expensive values that would be discarded. Lazy
+// fields are written after all other accumulated fields.
+func (enc *textEncoder) AddLazy(key string, fn func() interface{}) {
+	enc.lazy = append(enc.lazy, lazyField{key, fn})
+}
+
+// addValue adds an arbitrary value using the most specific KeyValue method
+// for its type, falling back to AddObject. Sized integers use the Encoder
+// method for their width when kv is an Encoder.
+func addValue(kv KeyValue, key string, val interface{}) {
+	var err error
+	switch v := val.(type) {
+	case string:
+		kv.AddString(key, v)
+	case bool:
+		kv.AddBool(key, v)
+	case int:
+		kv.AddInt(key, v)
+	case int8:
+		if enc, ok := kv.(Encoder); ok {
+			enc.AddInt8(key, v)
+		} else {
+			kv.AddInt64(key, int64(v))
+		}
+	case int16:
+		if enc, ok := kv.(Encoder); ok {
+			enc.AddInt16(key, v)
+		} else {
+			kv.AddInt64(key, int64(v))
+		}
+	case int32:
+		if enc, ok := kv.(Encoder); ok {
+			enc.AddInt32(key, v)
+		} else {
+			kv.AddInt64(key, int64(v))
+		}
+	case int64:
+		kv.AddInt64(key, v)
+	case uint:
+		kv.AddUint(key, v)
+	case uint8:
+		if enc, ok := kv.(Encoder); ok {
+			enc.AddUint8(key, v)
+		} else {
+			kv.AddUint64(key, uint64(v))
+		}
+	case uint16:
+		if enc, ok := kv.(Encoder); ok {
+			enc.AddUint16(key, v)
+		} else {
+			kv.AddUint64(key, uint64(v))
+		}
+	case uint32:
+		if enc, ok := kv.(Encoder); ok {
+			enc.AddUint32(key, v)
+		} else {
+			kv.AddUint64(key, uint64(v))
+		}
+	case uint64:
+		kv.AddUint64(key, v)
+	case float32:
+		kv.AddFloat32(key, v)
+	case float64:
+		kv.AddFloat64(key, v)
+	case complex64:
+		if enc, ok := kv.(Encoder); ok {
+			enc.AddComplex64(key, v)
+		} else {
+			kv.AddString(key, string(appendComplex(nil, complex128(v), 32)))
+		}
+	case complex128:
+		if enc, ok := kv.(Encoder); ok {
+			enc.AddComplex128(key, v)
+		} else {
+			kv.AddString(key, string(appendComplex(nil, v, 64)))
+		}
+	case []byte:
+		kv.AddBytes(key, v)
+	case []string:
+		if enc, ok := kv.(Encoder); ok {
+			enc.AddStrings(key, v)
+		} else {
+			err = kv.AddObject(key, v)
+		}
+	case []int:
+		if enc, ok := kv.(Encoder); ok {
+			enc.AddInts(key, v)
+		} else {
+			err = kv.AddObject(key, v)
+		}
+	case time.Duration:
+		if enc, ok := kv.(Encoder); ok {
+			enc.AddDuration(key, v)
+		} else {
+			kv.AddString(key, v.String())
+		}
+	case time.Time:
+		if enc, ok := kv.(Encoder); ok {
+			enc.AddTime(key, v)
+		} else {
+			kv.AddString(key, v.Format(time.RFC3339Nano))
+		}
+	case LogMarshaler:
+		err = kv.AddMarshaler(key, v)
+	case error:
+		if enc, ok := kv.(Encoder); ok {
+			enc.AddError(key, v)
+		} else {
+			kv.AddString(key, v.Error())
+		}
+	case fmt.Stringer:
+		kv.AddString(key, v.String())
+	default:
+		err = kv.AddObject(key, v)
+	}
+	if err != nil {
+		kv.AddString(key+"Error", err.Error())
+	}
+}
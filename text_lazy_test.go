@@ -0,0 +1,113 @@
+// Copyright (c) 2016 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zap
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTextAddLazy(t *testing.T) {
+	calls := 0
+	expensive := func() interface{} {
+		calls++
+		return calls
+	}
+
+	enc := NewTextEncoder(TextNoTime(), TextMinLevel(WarnLevel))
+	defer enc.Free()
+	enc.AddString("foo", "bar")
+	enc.(*textEncoder).AddLazy("n", expensive)
+	enc.AddString("baz", "qux")
+
+	sink := &testBuffer{}
+	assert.NoError(t, enc.WriteEntry(sink, "", "filtered", InfoLevel, epoch), "Unexpected failure writing entry.")
+	assert.Equal(t, 0, calls, "Expected lazy fields not to be evaluated for filtered entries.")
+	assert.Equal(t, 0, sink.Len(), "Expected filtered entries not to be written.")
+
+	assert.NoError(t, enc.WriteEntry(sink, "", "written", WarnLevel, epoch), "Unexpected failure writing entry.")
+	assert.NoError(t, enc.WriteEntry(sink, "", "written", ErrorLevel, epoch), "Unexpected failure writing entry.")
+	assert.Equal(t, 2, calls, "Expected lazy fields to be evaluated once per written entry.")
+	assert.Equal(t, []string{
+		"[W] written foo=bar baz=qux n=1",
+		"[E] written foo=bar baz=qux n=2",
+	}, sink.Lines(), "Unexpected output with lazy fields.")
+}
+
+func TestTextAddLazyTypes(t *testing.T) {
+	tests := []struct {
+		val      interface{}
+		expected string
+	}{
+		{"s", "k=s"},
+		{true, "k=true"},
+		{int8(-8), "k=-8"},
+		{uint16(16), "k=16"},
+		{1.5, "k=1.5"},
+		{[]byte{0x2a}, "k=0x2A"},
+		{time.Second, "k=1s"},
+		{errors.New("fail"), "k=fail"},
+		{loggable{true}, "k={loggable=yes}"},
+	}
+
+	for _, tt := range tests {
+		enc := NewTextEncoder(TextNoTime())
+		val := tt.val
+		enc.(*textEncoder).AddLazy("k", func() interface{} { return val })
+		sink := &testBuffer{}
+		assert.NoError(t, enc.WriteEntry(sink, "", "", InfoLevel, epoch), "Unexpected failure writing entry.")
+		assert.Equal(t, "[I]  "+tt.expected, sink.Stripped(), "Unexpected output for lazy %T.", tt.val)
+		enc.Free()
+	}
+}
+
+func TestTextLazyCloneAndTruncate(t *testing.T) {
+	withTextEncoder(func(enc *textEncoder) {
+		enc.AddLazy("a", func() interface{} { return 1 })
+		clone := enc.Clone().(*textEncoder)
+		defer clone.Free()
+		clone.AddLazy("b", func() interface{} { return 2 })
+		assert.Len(t, enc.lazy, 1, "Adding lazy fields to a clone shouldn't affect the original.")
+		assert.Len(t, clone.lazy, 2, "Expected clones to inherit lazy fields.")
+
+		enc.truncate()
+		assert.Empty(t, enc.lazy, "Expected truncate to clear lazy fields.")
+	})
+}
+
+func TestTextAddValueSizedInts(t *testing.T) {
+	withTextEncoder(func(enc *textEncoder) {
+		addValue(enc, "i8", int8(-8))
+		addValue(enc, "i16", int16(-16))
+		addValue(enc, "i32", int32(-32))
+		addValue(enc, "u8", uint8(8))
+		addValue(enc, "u16", uint16(16))
+		addValue(enc, "u32", uint32(32))
+		assert.Equal(t, "i8=-8 i16=-16 i32=-32 u8=8 u16=16 u32=32", string(enc.bytes), "Unexpected sized integers.")
+		assert.Equal(t, []Field{
+			Int8("i8", -8), Int16("i16", -16), Int32("i32", -32),
+			Uint8("u8", 8), Uint16("u16", 16), Uint32("u32", 32),
+		}, enc.Context(), "Expected sized integers to use the methods for their widths.")
+	})
+}
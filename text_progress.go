@@ -0,0 +1,49 @@
+// Copyright (c) 2016 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zap
+
+import "strconv"
+
+// AddProgress adds the progress of a long-running job as a fraction followed
+// by the whole percentage completed, rounded down (e.g., "rows=42/100(42%)").
+// Since the percentage of a zero total is undefined, it's omitted (e.g.,
+// "rows=42/0").
+func (enc *textEncoder) AddProgress(key string, current, total int64) {
+	enc.addKey(key)
+	enc.bytes = strconv.AppendInt(enc.bytes, current, 10)
+	enc.bytes = append(enc.bytes, '/')
+	enc.bytes = strconv.AppendInt(enc.bytes, total, 10)
+	if total == 0 {
+		return
+	}
+	enc.bytes = append(enc.bytes, '(')
+	enc.bytes = strconv.AppendInt(enc.bytes, percent(current, total), 10)
+	enc.bytes = append(enc.bytes, "%)"...)
+}
+
+// percent returns current as a whole percentage of total, rounded toward
+// zero. Products that would overflow are computed in floating point.
+func percent(current, total int64) int64 {
+	if p := current * 100; p/100 == current {
+		return p / total
+	}
+	return int64(float64(current) / float64(total) * 100)
+}
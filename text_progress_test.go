@@ -0,0 +1,56 @@
+// Copyright (c) 2016 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zap
+
+import (
+	"math"
+	"testing"
+)
+
+func TestTextEncoderAddProgress(t *testing.T) {
+	tests := []struct {
+		current, total int64
+		expected       string
+	}{
+		{42, 100, "p=42/100(42%)"},
+		{0, 100, "p=0/100(0%)"},
+		{100, 100, "p=100/100(100%)"},
+		{1, 3, "p=1/3(33%)"},
+		{2, 3, "p=2/3(66%)"},
+		{150, 100, "p=150/100(150%)"},
+		{42, 0, "p=42/0"},
+		{0, 0, "p=0/0"},
+		{-5, 10, "p=-5/10(-50%)"},
+		{math.MaxInt64 / 2, math.MaxInt64, "p=4611686018427387903/9223372036854775807(50%)"},
+	}
+
+	for _, tt := range tests {
+		assertTextOutput(t, "progress", tt.expected, func(e Encoder) {
+			e.(*textEncoder).AddProgress("p", tt.current, tt.total)
+		})
+	}
+}
+
+func TestTextEncoderAddProgressInterface(t *testing.T) {
+	assertTextEncoderOutput(t, "progress", "[I] hello rows=42/100(42%)", func(enc TextEncoder) {
+		enc.AddProgress("rows", 42, 100)
+	})
+}
@@ -0,0 +1,86 @@
+// Copyright (c) 2016 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zap
+
+import (
+	"strconv"
+	"time"
+)
+
+// _ratePrefixes are the SI prefixes used to scale rates.
+var _ratePrefixes = []string{"", "k", "M", "G", "T", "P", "E"}
+
+// AddRate adds a throughput computed from a count over a duration, choosing
+// the time unit and SI prefix so that the value is easy to read (e.g.,
+// "1.2k/s" or "30/min"). Rates of at least one per second are reported per
+// second, however large, so 3.4M events per minute is "56.7k/s"; slower rates
+// are reported per minute or, if necessary, per hour.
+// Since a rate over a non-positive duration is undefined, it's rendered as
+// "n/a".
+func (enc *textEncoder) AddRate(key string, count int64, per time.Duration) {
+	enc.addKey(key)
+	if per <= 0 {
+		enc.bytes = append(enc.bytes, "n/a"...)
+		return
+	}
+
+	rate := float64(count) / per.Seconds()
+	unit := "/s"
+	switch abs := absFloat(rate); {
+	case abs == 0 || abs >= 1:
+	case abs*60 >= 1:
+		rate, unit = rate*60, "/min"
+	default:
+		rate, unit = rate*3600, "/h"
+	}
+
+	prefix := 0
+	for absFloat(rate) >= 999.95 && prefix < len(_ratePrefixes)-1 {
+		rate /= 1000
+		prefix++
+	}
+	enc.bytes = appendTrimmedFloat(enc.bytes, rate, 1)
+	enc.bytes = append(enc.bytes, _ratePrefixes[prefix]...)
+	enc.bytes = append(enc.bytes, unit...)
+}
+
+func absFloat(f float64) float64 {
+	if f < 0 {
+		return -f
+	}
+	return f
+}
+
+// appendTrimmedFloat appends f with the given precision, dropping a
+// fractional part that's entirely zeros.
+func appendTrimmedFloat(buf []byte, f float64, prec int) []byte {
+	buf = strconv.AppendFloat(buf, f, 'f', prec, 64)
+	if prec <= 0 {
+		return buf
+	}
+	frac := buf[len(buf)-prec:]
+	for _, c := range frac {
+		if c != '0' {
+			return buf
+		}
+	}
+	return buf[:len(buf)-prec-1]
+}
@@ -0,0 +1,106 @@
+// Copyright (c) 2016 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zap
+
+import (
+	"strconv"
+	"sync"
+	"time"
+)
+
+// A rateLimiter is a token bucket shared by an encoder and its clones.
+type rateLimiter struct {
+	sync.Mutex
+	perSecond float64
+	burst     float64
+	tokens    float64
+	last      time.Time
+	dropped   int64
+}
+
+func newRateLimiter(perSecond, burst int) *rateLimiter {
+	if burst < 1 {
+		burst = 1
+	}
+	return &rateLimiter{
+		perSecond: float64(perSecond),
+		burst:     float64(burst),
+		tokens:    float64(burst),
+	}
+}
+
+// allow takes a token from the bucket, reporting whether one was available.
+// When an entry is allowed, it also returns the number of entries dropped
+// since the last allowed entry.
+func (r *rateLimiter) allow() (dropped int64, ok bool) {
+	now := _timeNow()
+	r.Lock()
+	defer r.Unlock()
+	if !r.last.IsZero() {
+		r.tokens += now.Sub(r.last).Seconds() * r.perSecond
+		if r.tokens > r.burst {
+			r.tokens = r.burst
+		}
+	}
+	r.last = now
+	if r.tokens < 1 {
+		r.dropped++
+		return 0, false
+	}
+	r.tokens--
+	dropped, r.dropped = r.dropped, 0
+	return dropped, true
+}
+
+// admit applies the TextRateLimit option, if any.
+func (enc *textEncoder) admit() (dropped int64, ok bool) {
+	if enc.limiter == nil {
+		return 0, true
+	}
+	return enc.limiter.allow()
+}
+
+// addDropSummary adds a warning-level line reporting how many entries the
+// rate limit dropped, without a trailing newline.
+func (enc *textEncoder) addDropSummary(final *textEncoder, dropped int64, t time.Time) {
+	enc.addLevel(final, WarnLevel)
+	enc.addTime(final, t)
+	final.bytes = append(final.bytes, " dropped "...)
+	final.bytes = strconv.AppendInt(final.bytes, dropped, 10)
+	final.bytes = append(final.bytes, " entries"...)
+}
+
+// TextRateLimit caps the rate at which the encoder and its clones write
+// entries, using a token bucket that refills at perSecond tokens per second
+// and holds at most burst tokens. Entries over the limit are dropped, and the
+// next entry that's written is preceded by a warning-level summary line
+// (e.g., "dropped 37 entries"). Unlike sampling, this is a blanket ceiling
+// that protects sinks and downstream systems from floods. A non-positive
+// perSecond disables the limit.
+func TextRateLimit(perSecond int, burst int) TextOption {
+	return textOptionFunc(func(enc *textEncoder) {
+		if perSecond <= 0 {
+			enc.limiter = nil
+			return
+		}
+		enc.limiter = newRateLimiter(perSecond, burst)
+	})
+}
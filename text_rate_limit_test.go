@@ -0,0 +1,117 @@
+// Copyright (c) 2016 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zap
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTextRateLimit(t *testing.T) {
+	withFakeClock(func(advance func(time.Duration)) {
+		enc := NewTextEncoder(TextNoTime(), TextRateLimit(2, 3))
+		defer enc.Free()
+		clone := enc.Clone()
+		defer clone.Free()
+		sink := &testBuffer{}
+
+		for i := 0; i < 10; i++ {
+			e := enc
+			if i%2 == 1 {
+				e = clone
+			}
+			assert.NoError(t, e.WriteEntry(sink, "", "flood", InfoLevel, epoch), "Unexpected failure writing entry.")
+		}
+		assert.Equal(t, []string{"[I] flood", "[I] flood", "[I] flood"}, sink.Lines(), "Expected entries beyond the burst to be dropped.")
+
+		sink.Reset()
+		advance(time.Second)
+		for i := 0; i < 3; i++ {
+			assert.NoError(t, enc.WriteEntry(sink, "", "later", InfoLevel, epoch), "Unexpected failure writing entry.")
+		}
+		assert.Equal(t, []string{
+			"[W] dropped 7 entries",
+			"[I] later",
+			"[I] later",
+		}, sink.Lines(), "Expected a summary of dropped entries once the bucket refilled.")
+
+		sink.Reset()
+		advance(time.Hour)
+		for i := 0; i < 3; i++ {
+			assert.NoError(t, enc.WriteEntry(sink, "", "refilled", InfoLevel, epoch), "Unexpected failure writing entry.")
+		}
+		assert.Equal(t, []string{
+			"[W] dropped 1 entries",
+			"[I] refilled",
+			"[I] refilled",
+			"[I] refilled",
+		}, sink.Lines(), "Expected the bucket to refill only up to the burst size.")
+	})
+}
+
+func TestTextRateLimitANSI(t *testing.T) {
+	withFakeClock(func(advance func(time.Duration)) {
+		enc := NewANSIEncoder(AnsiTextOption(TextNoTime()), AnsiTextOption(TextRateLimit(1, 1)))
+		defer enc.Free()
+		sink := &testBuffer{}
+		for i := 0; i < 3; i++ {
+			assert.NoError(t, enc.WriteEntry(sink, "", "flood", InfoLevel, epoch), "Unexpected failure writing entry.")
+		}
+		advance(time.Second)
+		sink.Reset()
+		assert.NoError(t, enc.WriteEntry(sink, "", "later", InfoLevel, epoch), "Unexpected failure writing entry.")
+		assert.Equal(t, []string{
+			defaultWarnColor + "[W] dropped 2 entries" + resetColor,
+			defaultInfoColor + "[I] later" + resetColor,
+		}, sink.Lines(), "Unexpected summary from ANSI encoder.")
+	})
+}
+
+func TestTextRateLimitConcurrent(t *testing.T) {
+	withFakeClock(func(func(time.Duration)) {
+		enc := NewTextEncoder(TextNoTime(), TextRateLimit(10, 5))
+		defer enc.Free()
+
+		var (
+			mu      sync.Mutex
+			written int
+			wg      sync.WaitGroup
+		)
+		for i := 0; i < 20; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				sink := &testBuffer{}
+				for j := 0; j < 10; j++ {
+					enc.WriteEntry(sink, "", "flood", InfoLevel, epoch)
+				}
+				mu.Lock()
+				written += len(sink.Lines())
+				mu.Unlock()
+			}()
+		}
+		wg.Wait()
+		assert.Equal(t, 5, written, "Expected exactly the burst to be written without advancing the clock.")
+	})
+}
@@ -0,0 +1,56 @@
+// Copyright (c) 2016 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zap
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTextEncoderAddRate(t *testing.T) {
+	tests := []struct {
+		count    int64
+		per      time.Duration
+		expected string
+	}{
+		{0, time.Second, "rate=0/s"},
+		{5, time.Second, "rate=5/s"},
+		{1200, time.Second, "rate=1.2k/s"},
+		{1234567, 500 * time.Millisecond, "rate=2.5M/s"},
+		{999999, time.Second, "rate=1M/s"},
+		{3, time.Minute, "rate=3/min"},
+		{3400000, time.Hour, "rate=944.4/s"},
+		{57, time.Hour, "rate=57/h"},
+		{3400, 60 * time.Hour, "rate=56.7/h"},
+		{90, 2 * time.Minute, "rate=45/min"},
+		{30, time.Minute, "rate=30/min"},
+		{3400000, time.Minute, "rate=56.7k/s"},
+		{-2500, time.Second, "rate=-2.5k/s"},
+		{10, 0, "rate=n/a"},
+		{10, -time.Second, "rate=n/a"},
+	}
+
+	for _, tt := range tests {
+		assertTextOutput(t, "rate", tt.expected, func(e Encoder) {
+			e.(*textEncoder).AddRate("rate", tt.count, tt.per)
+		})
+	}
+}
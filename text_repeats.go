@@ -0,0 +1,124 @@
+// Copyright (c) 2016 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zap
+
+import "strconv"
+
+// keyRepeats counts how often each top-level key has been added to a text
+// encoder, so that fields beyond the limit can be collapsed into a count.
+type keyRepeats struct {
+	max    int
+	counts map[string]int
+	// Keys that exceeded the limit, in the order they first did so.
+	overflow []string
+	// Offset of a field that's being dropped, or -1. The field is trimmed from
+	// the buffer when the next key is added.
+	dropFrom int
+}
+
+func (r *keyRepeats) copy() *keyRepeats {
+	if r == nil {
+		return nil
+	}
+	copied := &keyRepeats{
+		max:      r.max,
+		counts:   make(map[string]int, len(r.counts)),
+		overflow: append([]string(nil), r.overflow...),
+		dropFrom: -1,
+	}
+	for k, n := range r.counts {
+		copied.counts[k] = n
+	}
+	return copied
+}
+
+// count records an occurrence of key and reports whether it's over the limit.
+func (r *keyRepeats) count(key string) bool {
+	n := r.counts[key] + 1
+	r.counts[key] = n
+	if n == r.max+1 {
+		r.overflow = append(r.overflow, key)
+	}
+	return n > r.max
+}
+
+// trackRepeat trims any field that's being dropped and, if key is over the
+// limit, starts dropping the field that's about to be added. It reports
+// whether the new field is dropped.
+func (enc *textEncoder) trackRepeat(key string) bool {
+	r := enc.repeats
+	if r == nil || enc.depth > 0 {
+		return false
+	}
+	if r.dropFrom >= 0 {
+		enc.bytes = enc.bytes[:r.dropFrom]
+		r.dropFrom = -1
+	}
+	if r.count(key) {
+		r.dropFrom = len(enc.bytes)
+		return true
+	}
+	return false
+}
+
+// keptFields returns the accumulated fields, excluding any field that's
+// being dropped.
+func (enc *textEncoder) keptFields() []byte {
+	if enc.repeats != nil && enc.repeats.dropFrom >= 0 {
+		return enc.bytes[:enc.repeats.dropFrom]
+	}
+	return enc.bytes
+}
+
+// addOmittedCounts summarizes the fields dropped from final.
+func (final *textEncoder) addOmittedCounts() {
+	r := final.repeats
+	if r == nil {
+		return
+	}
+	if r.dropFrom >= 0 {
+		final.bytes = final.bytes[:r.dropFrom]
+		r.dropFrom = -1
+	}
+	for _, key := range r.overflow {
+		final.addKey(key + ".omitted")
+		final.bytes = strconv.AppendInt(final.bytes, int64(r.counts[key]-r.max), 10)
+	}
+}
+
+// TextCollapseRepeatedKeys limits how many times the same top-level key is
+// written in an entry. After max occurrences, further fields with that key are
+// dropped and replaced by a count of the omitted fields (e.g.,
+// "item.omitted=37"), which keeps lines bounded when code accidentally adds
+// the same key in a loop. A non-positive max disables the limit.
+func TextCollapseRepeatedKeys(max int) TextOption {
+	return textOptionFunc(func(enc *textEncoder) {
+		if max <= 0 {
+			enc.repeats = nil
+			return
+		}
+		enc.repeats = &keyRepeats{
+			max:      max,
+			counts:   make(map[string]int),
+			dropFrom: -1,
+		}
+	})
+}
@@ -0,0 +1,84 @@
+// Copyright (c) 2016 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zap
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTextCollapseRepeatedKeys(t *testing.T) {
+	tests := []struct {
+		desc     string
+		adds     int
+		expected string
+	}{
+		{"below max", 2, "[I] hello item=0 item=1 other=x"},
+		{"at max", 3, "[I] hello item=0 item=1 item=2 other=x"},
+		{"above max", 40, "[I] hello item=0 item=1 item=2 other=x item.omitted=37"},
+	}
+
+	for _, tt := range tests {
+		enc := NewTextEncoder(TextNoTime(), TextCollapseRepeatedKeys(3))
+		for i := 0; i < tt.adds; i++ {
+			enc.AddInt("item", i)
+		}
+		enc.AddString("other", "x")
+		sink := &testBuffer{}
+		assert.NoError(t, enc.WriteEntry(sink, "", "hello", InfoLevel, epoch), "Unexpected error writing entry %s.", tt.desc)
+		assert.Equal(t, tt.expected, sink.Stripped(), "Unexpected output %s.", tt.desc)
+		enc.Free()
+	}
+}
+
+func TestTextCollapseRepeatedKeysTrailingField(t *testing.T) {
+	enc := NewTextEncoder(TextNoTime(), TextCollapseRepeatedKeys(1))
+	defer enc.Free()
+	enc.AddString("item", "a")
+	enc.AddMarshaler("item", loggable{true})
+	enc.AddMarshaler("obj", loggable{true})
+	enc.AddString("item", "c")
+
+	sink := &testBuffer{}
+	assert.NoError(t, enc.WriteEntry(sink, "", "hello", InfoLevel, epoch), "Unexpected error writing entry.")
+	assert.Equal(t, "[I] hello item=a obj={loggable=yes} item.omitted=2", sink.Stripped(), "Expected the last field to be dropped too.")
+
+	// WriteEntry shouldn't consume the counts, and clones should inherit them.
+	clone := enc.Clone()
+	defer clone.Free()
+	clone.AddString("item", "d")
+	sink.Reset()
+	assert.NoError(t, clone.WriteEntry(sink, "", "hello", InfoLevel, epoch), "Unexpected error writing entry.")
+	assert.Equal(t, "[I] hello item=a obj={loggable=yes} item.omitted=3", sink.Stripped(), "Unexpected output from clone.")
+}
+
+func TestTextCollapseRepeatedKeysNested(t *testing.T) {
+	enc := NewTextEncoder(TextNoTime(), TextCollapseRepeatedKeys(1))
+	defer enc.Free()
+	enc.AddString("loggable", "outer")
+	enc.AddMarshaler("obj", loggable{true})
+	enc.AddMarshaler("obj2", loggable{true})
+
+	sink := &testBuffer{}
+	assert.NoError(t, enc.WriteEntry(sink, "", "hello", InfoLevel, epoch), "Unexpected error writing entry.")
+	assert.Equal(t, "[I] hello loggable=outer obj={loggable=yes} obj2={loggable=yes}", sink.Stripped(), "Nested keys shouldn't be counted.")
+}
@@ -0,0 +1,70 @@
+// Copyright (c) 2016 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zap
+
+import (
+	"runtime"
+	"strconv"
+	"time"
+)
+
+// AddChanStats adds a channel's length and capacity as "len/cap" (e.g.,
+// "queue=3/100"), which is useful for spotting backpressure. Since a
+// channel's contents can't be inspected without receiving from it, callers
+// supply len(ch) and cap(ch) directly.
+func (enc *textEncoder) AddChanStats(key string, length, capacity int) {
+	enc.addKey(key)
+	enc.bytes = strconv.AppendInt(enc.bytes, int64(length), 10)
+	enc.bytes = append(enc.bytes, '/')
+	enc.bytes = strconv.AppendInt(enc.bytes, int64(capacity), 10)
+}
+
+// AddGoroutineCount adds the number of running goroutines under the
+// "goroutines" key, which is useful for spotting goroutine leaks.
+func (enc *textEncoder) AddGoroutineCount() {
+	enc.AddInt("goroutines", runtime.NumGoroutine())
+}
+
+// AddMemStats reads the runtime's memory statistics and adds a summary of
+// them; see AddMemStatsSnapshot for the fields. Reading the statistics stops
+// the world, so this is best reserved for infrequent diagnostic entries.
+// Callers that already have a snapshot, or that log the same statistics to
+// several places, should use AddMemStatsSnapshot instead.
+func (enc *textEncoder) AddMemStats(key string) {
+	var ms runtime.MemStats
+	runtime.ReadMemStats(&ms)
+	enc.AddMemStatsSnapshot(key, &ms)
+}
+
+// AddMemStatsSnapshot adds a summary of previously-read memory statistics:
+// the bytes of allocated heap objects, the bytes in in-use heap spans, the
+// number of completed GC cycles, and the total time spent in GC pauses (e.g.,
+// "mem.alloc=1024 mem.heap_inuse=8192 mem.num_gc=3 mem.pause_total=1.5ms").
+func (enc *textEncoder) AddMemStatsSnapshot(key string, ms *runtime.MemStats) {
+	enc.addSubKey(key, "alloc")
+	enc.bytes = strconv.AppendUint(enc.bytes, ms.Alloc, 10)
+	enc.addSubKey(key, "heap_inuse")
+	enc.bytes = strconv.AppendUint(enc.bytes, ms.HeapInuse, 10)
+	enc.addSubKey(key, "num_gc")
+	enc.bytes = strconv.AppendUint(enc.bytes, uint64(ms.NumGC), 10)
+	enc.addSubKey(key, "pause_total")
+	enc.bytes = append(enc.bytes, time.Duration(ms.PauseTotalNs).String()...)
+}
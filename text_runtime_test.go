@@ -0,0 +1,98 @@
+// Copyright (c) 2016 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zap
+
+import (
+	"regexp"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTextAddChanStats(t *testing.T) {
+	ch := make(chan int, 100)
+	for i := 0; i < 3; i++ {
+		ch <- i
+	}
+	unbuffered := make(chan int)
+
+	withTextEncoder(func(enc *textEncoder) {
+		enc.AddChanStats("queue", len(ch), cap(ch))
+		enc.AddChanStats("sync", len(unbuffered), cap(unbuffered))
+		assert.Equal(t, "queue=3/100 sync=0/0", string(enc.bytes), "Unexpected channel stats output.")
+	})
+}
+
+func TestTextAddGoroutineCount(t *testing.T) {
+	withTextEncoder(func(enc *textEncoder) {
+		enc.AddGoroutineCount()
+		assert.Regexp(t, regexp.MustCompile(`^goroutines=[1-9][0-9]*$`), string(enc.bytes), "Unexpected goroutine count output.")
+	})
+}
+
+func TestTextAddMemStatsSnapshot(t *testing.T) {
+	ms := &runtime.MemStats{
+		Alloc:        1024,
+		HeapInuse:    8192,
+		NumGC:        3,
+		PauseTotalNs: 1500000,
+		Mallocs:      42,
+	}
+	withTextEncoder(func(enc *textEncoder) {
+		enc.AddMemStatsSnapshot("mem", ms)
+		assert.Equal(t, "mem.alloc=1024 mem.heap_inuse=8192 mem.num_gc=3 mem.pause_total=1.5ms", string(enc.bytes), "Unexpected memory stats output.")
+	})
+}
+
+func TestTextAddMemStats(t *testing.T) {
+	withTextEncoder(func(enc *textEncoder) {
+		enc.AddMemStats("mem")
+		assert.Regexp(t, regexp.MustCompile(`^mem\.alloc=[1-9][0-9]* mem\.heap_inuse=[1-9][0-9]* mem\.num_gc=[0-9]+ mem\.pause_total=\S+$`), string(enc.bytes), "Unexpected memory stats output.")
+	})
+}
+
+func TestTextEncoderAddChanStats(t *testing.T) {
+	assertTextEncoderOutput(t, "channel stats", "[I] hello queue=3/100", func(enc TextEncoder) {
+		enc.AddChanStats("queue", 3, 100)
+	})
+
+	enc := NewTextEncoder().(TextEncoder)
+	defer enc.Free()
+	enc.AddGoroutineCount()
+	sink := &testBuffer{}
+	assert.NoError(t, enc.WriteEntry(sink, "", "hello", InfoLevel, epoch), "Unexpected failure writing entry.")
+	assert.Regexp(t, regexp.MustCompile(` goroutines=[1-9][0-9]*$`), sink.Stripped(), "Unexpected goroutine count output.")
+}
+
+func TestTextEncoderAddMemStats(t *testing.T) {
+	ms := &runtime.MemStats{Alloc: 1024, HeapInuse: 8192, NumGC: 3, PauseTotalNs: 1500000}
+	assertTextEncoderOutput(t, "memory stats", "[I] hello mem.alloc=1024 mem.heap_inuse=8192 mem.num_gc=3 mem.pause_total=1.5ms", func(enc TextEncoder) {
+		enc.AddMemStatsSnapshot("mem", ms)
+	})
+
+	enc := NewTextEncoder().(TextEncoder)
+	defer enc.Free()
+	enc.AddMemStats("mem")
+	sink := &testBuffer{}
+	assert.NoError(t, enc.WriteEntry(sink, "", "hello", InfoLevel, epoch), "Unexpected failure writing entry.")
+	assert.Regexp(t, regexp.MustCompile(` mem\.alloc=[1-9][0-9]* `), sink.Stripped(), "Unexpected memory stats output.")
+}
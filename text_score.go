@@ -0,0 +1,67 @@
+// Copyright (c) 2016 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zap
+
+// DefaultSeverityScore is the scoring function used by TextSeverityScore when
+// none is supplied. It scores entries by level alone, from 10 for DebugLevel
+// to 100 for FatalLevel, leaving room for custom functions to rank entries
+// between levels.
+func DefaultSeverityScore(lvl Level, _ string, _ []Field) int {
+	switch lvl {
+	case DebugLevel:
+		return 10
+	case InfoLevel:
+		return 20
+	case WarnLevel:
+		return 40
+	case ErrorLevel:
+		return 60
+	case PanicLevel:
+		return 80
+	}
+	if lvl < DebugLevel {
+		return 0
+	}
+	return 100
+}
+
+// scoreEntry applies the TextSeverityScore option, if any, recording the
+// entry's score in the final encoder.
+func (enc *textEncoder) scoreEntry(final *textEncoder, lvl Level, msg string) {
+	if enc.scoreFn == nil {
+		return
+	}
+	final.score = enc.scoreFn(lvl, msg, enc.Context())
+}
+
+// TextSeverityScore adds a "_score" field to each entry, computed from its
+// level, message, and fields (as returned by Context) by fn. Downstream
+// systems can use the score to rank entries for triage. If fn is nil,
+// DefaultSeverityScore is used. Since fields are collected for the scoring
+// function, this option allocates for every entry.
+func TextSeverityScore(fn func(lvl Level, msg string, fields []Field) int) TextOption {
+	return textOptionFunc(func(enc *textEncoder) {
+		if fn == nil {
+			fn = DefaultSeverityScore
+		}
+		enc.scoreFn = fn
+	})
+}
@@ -0,0 +1,108 @@
+// Copyright (c) 2016 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zap
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDefaultSeverityScore(t *testing.T) {
+	tests := []struct {
+		lvl   Level
+		score int
+	}{
+		{Level(-5), 0},
+		{DebugLevel, 10},
+		{InfoLevel, 20},
+		{WarnLevel, 40},
+		{ErrorLevel, 60},
+		{PanicLevel, 80},
+		{FatalLevel, 100},
+		{Level(42), 100},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.score, DefaultSeverityScore(tt.lvl, "msg", nil), "Unexpected score for level %v.", tt.lvl)
+	}
+}
+
+func TestTextSeverityScore(t *testing.T) {
+	custom := func(lvl Level, msg string, fields []Field) int {
+		score := DefaultSeverityScore(lvl, msg, fields)
+		if strings.Contains(msg, "payment") {
+			score += 5
+		}
+		for _, f := range fields {
+			if f.key == "customer" {
+				score += 3
+			}
+		}
+		return score
+	}
+
+	tests := []struct {
+		desc     string
+		fn       func(Level, string, []Field) int
+		lvl      Level
+		msg      string
+		expected string
+	}{
+		{"default info", nil, InfoLevel, "hi", "[I] hi user=jane _score=20"},
+		{"default error", nil, ErrorLevel, "hi", "[E] hi user=jane _score=60"},
+		{"custom", custom, ErrorLevel, "payment failed", "[E] payment failed user=jane _score=65"},
+	}
+
+	for _, tt := range tests {
+		enc := NewTextEncoder(TextNoTime(), TextSeverityScore(tt.fn))
+		enc.AddString("user", "jane")
+		buf := &testBuffer{}
+		require.NoError(t, enc.WriteEntry(buf, "", tt.msg, tt.lvl, epoch), "Unexpected error writing entry (%s).", tt.desc)
+		assert.Equal(t, tt.expected, buf.Stripped(), "Unexpected output (%s).", tt.desc)
+		enc.Free()
+	}
+}
+
+func TestTextSeverityScoreFields(t *testing.T) {
+	var seen []Field
+	enc := NewTextEncoder(TextNoTime(), TextSeverityScore(func(lvl Level, msg string, fields []Field) int {
+		seen = fields
+		return len(fields)
+	}))
+	defer enc.Free()
+	enc.AddString("customer", "acme")
+	clone := enc.Clone()
+	defer clone.Free()
+	clone.AddInt("attempt", 2)
+
+	buf := &testBuffer{}
+	require.NoError(t, clone.WriteEntry(buf, "", "hi", WarnLevel, epoch), "Unexpected error writing entry.")
+	assert.Equal(t, "[W] hi customer=acme attempt=2 _score=2", buf.Stripped(), "Unexpected output.")
+	assert.Equal(t, []Field{String("customer", "acme"), Int("attempt", 2)}, seen, "Expected the scoring function to see the entry's fields.")
+
+	ansi := NewANSIEncoder(AnsiTextOption(TextNoTime()), AnsiTextOption(TextSeverityScore(nil)))
+	defer ansi.Free()
+	buf.Reset()
+	require.NoError(t, ansi.WriteEntry(buf, "", "hi", ErrorLevel, epoch), "Unexpected error writing entry.")
+	assert.Equal(t, "[E] hi _score=60", stripEscapes(buf.Stripped()), "Unexpected ANSI output.")
+}
@@ -0,0 +1,113 @@
+// Copyright (c) 2016 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zap
+
+import (
+	"regexp"
+	"strings"
+)
+
+// _secretMask replaces secrets found by TextSecretScan.
+const _secretMask = "[REDACTED]"
+
+// A secretPattern matches one kind of secret. Since regular expressions are
+// comparatively slow, a pattern is only applied to strings that contain one
+// of its hints.
+type secretPattern struct {
+	hints []string
+	re    *regexp.Regexp
+	// The replacement for matches, which may refer to submatches.
+	repl string
+}
+
+var _secretPatterns = []secretPattern{
+	{
+		// AWS access key IDs.
+		hints: []string{"AKIA", "ASIA"},
+		re:    regexp.MustCompile(`\b(?:AKIA|ASIA)[0-9A-Z]{16}\b`),
+		repl:  _secretMask,
+	},
+	{
+		// PEM-encoded private keys, including the body if it's present.
+		hints: []string{"-----BEGIN "},
+		re:    regexp.MustCompile(`-----BEGIN [A-Z ]*PRIVATE KEY-----(?s:.*?-----END [A-Z ]*PRIVATE KEY-----)?`),
+		repl:  _secretMask,
+	},
+	{
+		// Bearer tokens, keeping the scheme.
+		hints: []string{"earer", "EARER"},
+		re:    regexp.MustCompile(`(?i)(\bbearer\s+)[A-Za-z0-9\-._~+/]+=*`),
+		repl:  "${1}" + _secretMask,
+	},
+}
+
+// maskSecrets replaces any secrets in s, reporting whether it found any.
+func maskSecrets(s string) (string, bool) {
+	found := false
+	for _, p := range _secretPatterns {
+		if !containsAny(s, p.hints) || !p.re.MatchString(s) {
+			continue
+		}
+		s = p.re.ReplaceAllString(s, p.repl)
+		found = true
+	}
+	return s, found
+}
+
+func containsAny(s string, substrs []string) bool {
+	for _, sub := range substrs {
+		if strings.Contains(s, sub) {
+			return true
+		}
+	}
+	return false
+}
+
+// scanSecrets masks any secrets in a string value if the TextSecretScan
+// option is enabled, remembering that the encoder's fields contain a secret.
+func (enc *textEncoder) scanSecrets(s string) string {
+	if !enc.secretScan {
+		return s
+	}
+	masked, found := maskSecrets(s)
+	if found {
+		enc.secretFound = true
+	}
+	return masked
+}
+
+// TextSecretScan masks secrets that are accidentally logged, regardless of
+// the key they're logged under. String values, including errors, labels, and
+// objects rendered as strings, are checked for AWS access key IDs, PEM
+// private keys, and bearer tokens, and each secret is replaced with
+// "[REDACTED]". Entries with a masked value also get a
+// "_secret_detected=true" field, so leaks can be found and fixed at the
+// source.
+//
+// Scanning is a defense in depth, not a guarantee: it only recognizes these
+// formats. Each string is checked for a few fixed substrings before any
+// regular expression is applied, so scanning clean values is fairly cheap,
+// but it's still a cost on every string field.
+func TextSecretScan() TextOption {
+	return textOptionFunc(func(enc *textEncoder) {
+		enc.secretScan = true
+	})
+}
@@ -0,0 +1,71 @@
+// Copyright (c) 2016 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zap
+
+import "strconv"
+
+// addSize fills in the value of the "_size" field, which addFields left at
+// offset at, with the length of the entry starting at lineStart. The length
+// includes the size field itself and the trailing newline, so it's exactly
+// the number of bytes the entry occupies in the sink.
+func (enc *textEncoder) addSize(final *textEncoder, lineStart, at int) {
+	if !enc.emitSize {
+		return
+	}
+	n := len(final.bytes) - lineStart
+	size := n + decimalDigits(n)
+	if digits := decimalDigits(size); digits > size-n {
+		// Adding the digits carried the size into another digit.
+		size = n + digits
+	}
+
+	var scratch [20]byte
+	digits := strconv.AppendInt(scratch[:0], int64(size), 10)
+	tail := len(final.bytes) - at
+	final.bytes = append(final.bytes, digits...)
+	copy(final.bytes[at+len(digits):], final.bytes[at:at+tail])
+	copy(final.bytes[at:], digits)
+}
+
+// decimalDigits returns the number of digits in the decimal form of a
+// non-negative integer.
+func decimalDigits(n int) int {
+	digits := 1
+	for ; n >= 10; n /= 10 {
+		digits++
+	}
+	return digits
+}
+
+// TextEmitSize ends each entry with a "_size" field holding the entry's length
+// in bytes, including the field itself and the trailing newline. This makes
+// it easy to find the entries that dominate log volume without measuring them
+// externally. Lines written before an entry, like the format header and
+// rate-limiting summaries, aren't counted.
+//
+// With TextOpenMetricsExemplars, an entry's exemplar still ends the line,
+// since the OpenMetrics format requires it there: "_size" is the last field,
+// and it's followed only by the exemplar, which the size includes.
+func TextEmitSize() TextOption {
+	return textOptionFunc(func(enc *textEncoder) {
+		enc.emitSize = true
+	})
+}
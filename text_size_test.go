@@ -0,0 +1,105 @@
+// Copyright (c) 2016 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zap
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTextEmitSize(t *testing.T) {
+	tests := []struct {
+		desc string
+		msg  string
+		opts []TextOption
+	}{
+		{"short entry", "hi", nil},
+		{"entry near a digit boundary", strings.Repeat("x", 70), nil},
+		{"entry near another digit boundary", strings.Repeat("x", 973), nil},
+		{"no fields and a custom separator", "hi", []TextOption{TextHeaderFieldSeparator(" | ")}},
+		{"generated fields", "hi", []TextOption{TextRunID(), TextEntryUUID()}},
+	}
+
+	for _, tt := range tests {
+		// Try a range of message lengths around each case to cross digit
+		// boundaries in the size.
+		for pad := 0; pad < 12; pad++ {
+			enc := NewTextEncoder(append(tt.opts, TextEmitSize())...)
+			enc.AddString("user", "jane")
+			sink := &testBuffer{}
+			require.NoError(t, enc.WriteEntry(sink, "", tt.msg+strings.Repeat("y", pad), InfoLevel, epoch), "Unexpected error writing entry.")
+			enc.Free()
+
+			line := sink.String()
+			i := strings.LastIndex(line, "_size=")
+			require.True(t, i >= 0, "Expected a size field in %q for %s.", line, tt.desc)
+			size, err := strconv.Atoi(strings.TrimSuffix(line[i+len("_size="):], "\n"))
+			require.NoError(t, err, "Expected the size field to end the entry for %s.", tt.desc)
+			assert.Equal(t, len(line), size, "Unexpected size in %q for %s.", line, tt.desc)
+		}
+	}
+}
+
+func TestTextEmitSizeSeparator(t *testing.T) {
+	enc := NewTextEncoder(TextNoTime(), TextEmitSize(), TextHeaderFieldSeparator(" | "))
+	defer enc.Free()
+	sink := &testBuffer{}
+	require.NoError(t, enc.WriteEntry(sink, "", "hi", InfoLevel, epoch), "Unexpected error writing entry.")
+	assert.Equal(t, "[I] hi | _size=18\n", sink.String(), "Expected the size field to follow the header separator.")
+}
+
+func TestTextEmitSizeWithExemplar(t *testing.T) {
+	enc := NewTextEncoder(TextNoTime(), TextEmitSize(), TextOpenMetricsExemplars())
+	defer enc.Free()
+	enc.AddString("trace_id", "abc")
+	sink := &testBuffer{}
+	require.NoError(t, enc.WriteEntry(sink, "", "hi", InfoLevel, time.Unix(1467374400, 0)), "Unexpected error writing entry.")
+
+	line := sink.String()
+	assert.Equal(t, "[I] hi trace_id=abc _size=65 # {trace_id=\"abc\"} 1 1467374400.000\n", line, "Expected the exemplar to follow the size field.")
+	assert.Equal(t, 65, len(line), "Expected the size to include the exemplar.")
+}
+
+func TestANSIEmitSize(t *testing.T) {
+	enc := NewANSIEncoder(AnsiTextOption(TextNoTime()), AnsiTextOption(TextEmitSize()))
+	defer enc.Free()
+	enc.AddString("user", "jane")
+	sink := &testBuffer{}
+	require.NoError(t, enc.WriteEntry(sink, "", "hi", WarnLevel, epoch), "Unexpected error writing entry.")
+
+	line := sink.String()
+	assert.True(t, strings.HasSuffix(line, resetColor+"\n"), "Expected the color to be reset after the size field.")
+	i := strings.LastIndex(line, "_size=")
+	size, err := strconv.Atoi(strings.TrimSuffix(line[i+len("_size="):], resetColor+"\n"))
+	require.NoError(t, err, "Expected the size field to be the last field.")
+	assert.Equal(t, len(line), size, "Expected the size to include color codes.")
+}
+
+func TestDecimalDigits(t *testing.T) {
+	for _, n := range []int{0, 1, 9, 10, 99, 100, 12345, 1<<31 - 1} {
+		assert.Equal(t, len(strconv.Itoa(n)), decimalDigits(n), "Unexpected digit count for %d.", n)
+	}
+}
@@ -0,0 +1,67 @@
+// Copyright (c) 2016 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zap
+
+import "strconv"
+
+// splitMessage returns the first chunk of the message and the remainder,
+// which is written as continuation fields. Messages aren't split unless the
+// TextSplitMessage option is set.
+func (enc *textEncoder) splitMessage(msg string) (first, rest string) {
+	if enc.splitWidth <= 0 {
+		return msg, ""
+	}
+	n := chunkEnd(msg, enc.splitWidth)
+	return msg[:n], msg[n:]
+}
+
+// addContinuations adds the remainder of a split message as "msg_1",
+// "msg_2", and so on. Unlike the message, the continuations are fields, so
+// they're quoted like other strings.
+func (enc *textEncoder) addContinuations(final *textEncoder, rest string) {
+	for i := 1; rest != ""; i++ {
+		n := chunkEnd(rest, enc.splitWidth)
+		final.addKey("msg_" + strconv.Itoa(i))
+		final.bytes = enc.appendString(final.bytes, rest[:n])
+		rest = rest[n:]
+	}
+}
+
+// chunkEnd returns the byte offset just past the first width runes of s.
+func chunkEnd(s string, width int) int {
+	for i := range s {
+		if width == 0 {
+			return i
+		}
+		width--
+	}
+	return len(s)
+}
+
+// TextSplitMessage splits messages longer than width runes into chunks,
+// writing the first chunk as the message and the others as "msg_1", "msg_2",
+// and so on. This keeps entries intact in ingestion systems that truncate
+// long fields. A non-positive width disables splitting.
+func TextSplitMessage(width int) TextOption {
+	return textOptionFunc(func(enc *textEncoder) {
+		enc.splitWidth = width
+	})
+}
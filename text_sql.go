@@ -0,0 +1,160 @@
+// Copyright (c) 2016 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zap
+
+import (
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"unicode"
+)
+
+// A SQLArgsFormat controls how AddSQL renders query arguments.
+type SQLArgsFormat int
+
+const (
+	// SQLArgsCount renders only the number of arguments. It's the default.
+	SQLArgsCount SQLArgsFormat = iota
+	// SQLArgsRedacted also renders a placeholder for each argument, which shows
+	// which arguments were nil.
+	SQLArgsRedacted
+	// SQLArgsHashed also renders a short hash of each argument, which lets
+	// entries with equal arguments be correlated without logging the values.
+	// Since hashes of low-entropy values (like small integers) are easy to
+	// reverse, this isn't a substitute for redaction.
+	SQLArgsHashed
+)
+
+// _defaultNullToken is written in place of invalid nullable SQL values.
+const _defaultNullToken = "null"
+
+// AddNullString adds the string if it's valid, and the encoder's null token
+// otherwise.
+func (enc *textEncoder) AddNullString(key string, val sql.NullString) {
+	if !val.Valid {
+		enc.addNull(key)
+		return
+	}
+	enc.AddString(key, val.String)
+}
+
+// AddNullInt64 adds the integer if it's valid, and the encoder's null token
+// otherwise.
+func (enc *textEncoder) AddNullInt64(key string, val sql.NullInt64) {
+	if !val.Valid {
+		enc.addNull(key)
+		return
+	}
+	enc.AddInt64(key, val.Int64)
+}
+
+// AddNullFloat64 adds the float if it's valid, and the encoder's null token
+// otherwise.
+func (enc *textEncoder) AddNullFloat64(key string, val sql.NullFloat64) {
+	if !val.Valid {
+		enc.addNull(key)
+		return
+	}
+	enc.AddFloat64(key, val.Float64)
+}
+
+// AddNullBool adds the boolean if it's valid, and the encoder's null token
+// otherwise.
+func (enc *textEncoder) AddNullBool(key string, val sql.NullBool) {
+	if !val.Valid {
+		enc.addNull(key)
+		return
+	}
+	enc.AddBool(key, val.Bool)
+}
+
+// AddSQL adds a query with its placeholders (e.g., "?" or "$1") intact, with
+// runs of whitespace collapsed so that multi-line queries fit on one line. The
+// number of arguments is added under "key.nargs", but by default their values
+// are omitted, since they often contain personal data. The TextSQLArgs option
+// adds redacted or hashed arguments under "key.args".
+func (enc *textEncoder) AddSQL(key, query string, args ...interface{}) {
+	enc.AddString(key, collapseSpace(query))
+	enc.AddInt(key+".nargs", len(args))
+	if enc.sqlArgs == SQLArgsCount {
+		return
+	}
+	enc.addKey(key + ".args")
+	enc.bytes = append(enc.bytes, '[')
+	for i, arg := range args {
+		if i > 0 {
+			enc.bytes = append(enc.bytes, ' ')
+		}
+		switch {
+		case arg == nil:
+			enc.bytes = append(enc.bytes, "<nil>"...)
+		case enc.sqlArgs == SQLArgsHashed:
+			sum := sha256.Sum256([]byte(fmt.Sprint(arg)))
+			var digits [16]byte
+			hex.Encode(digits[:], sum[:8])
+			enc.bytes = append(enc.bytes, digits[:]...)
+		default:
+			enc.bytes = append(enc.bytes, "REDACTED"...)
+		}
+	}
+	enc.bytes = append(enc.bytes, ']')
+}
+
+// collapseSpace replaces each run of whitespace in s with a single space and
+// trims leading and trailing whitespace.
+func collapseSpace(s string) string {
+	buf := make([]byte, 0, len(s))
+	space := false
+	for _, r := range s {
+		if unicode.IsSpace(r) {
+			space = len(buf) > 0
+			continue
+		}
+		if space {
+			buf = append(buf, ' ')
+			space = false
+		}
+		buf = append(buf, string(r)...)
+	}
+	return string(buf)
+}
+
+func (enc *textEncoder) addNull(key string) {
+	enc.addKey(key)
+	enc.bytes = append(enc.bytes, enc.nullToken...)
+}
+
+// TextNullToken sets the token written for invalid nullable SQL values. By
+// default, the encoder writes "null".
+func TextNullToken(token string) TextOption {
+	return textOptionFunc(func(enc *textEncoder) {
+		enc.nullToken = token
+	})
+}
+
+// TextSQLArgs sets how AddSQL renders query arguments. By default, only the
+// number of arguments is added.
+func TextSQLArgs(f SQLArgsFormat) TextOption {
+	return textOptionFunc(func(enc *textEncoder) {
+		enc.sqlArgs = f
+	})
+}
@@ -0,0 +1,117 @@
+// Copyright (c) 2016 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zap
+
+import (
+	"database/sql"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTextNullableSQLTypes(t *testing.T) {
+	tests := []struct {
+		desc     string
+		expected string
+		f        func(*textEncoder)
+	}{
+		{"valid string", "k=foo", func(e *textEncoder) { e.AddNullString("k", sql.NullString{String: "foo", Valid: true}) }},
+		{"null string", "k=null", func(e *textEncoder) { e.AddNullString("k", sql.NullString{String: "foo"}) }},
+		{"valid int64", "k=42", func(e *textEncoder) { e.AddNullInt64("k", sql.NullInt64{Int64: 42, Valid: true}) }},
+		{"null int64", "k=null", func(e *textEncoder) { e.AddNullInt64("k", sql.NullInt64{}) }},
+		{"valid float64", "k=1.5", func(e *textEncoder) { e.AddNullFloat64("k", sql.NullFloat64{Float64: 1.5, Valid: true}) }},
+		{"null float64", "k=null", func(e *textEncoder) { e.AddNullFloat64("k", sql.NullFloat64{}) }},
+		{"valid bool", "k=false", func(e *textEncoder) { e.AddNullBool("k", sql.NullBool{Bool: false, Valid: true}) }},
+		{"null bool", "k=null", func(e *textEncoder) { e.AddNullBool("k", sql.NullBool{}) }},
+	}
+
+	for _, tt := range tests {
+		withTextEncoder(func(enc *textEncoder) {
+			tt.f(enc)
+			assert.Equal(t, tt.expected, string(enc.bytes), "Unexpected output adding a %s.", tt.desc)
+		})
+	}
+}
+
+func TestTextNullToken(t *testing.T) {
+	enc := NewTextEncoder(TextNullToken("<nil>")).(*textEncoder)
+	defer enc.Free()
+	enc.AddNullString("s", sql.NullString{})
+	enc.AddNullInt64("i", sql.NullInt64{Int64: 1, Valid: true})
+	assert.Equal(t, "s=<nil> i=1", string(enc.bytes), "Expected the custom null token.")
+}
+
+func TestTextAddSQL(t *testing.T) {
+	const query = `
+		SELECT name, email
+		FROM users
+		WHERE id = $1 AND  status = $2`
+	const clean = `q="SELECT name, email FROM users WHERE id = $1 AND status = $2"`
+
+	tests := []struct {
+		desc     string
+		format   SQLArgsFormat
+		args     []interface{}
+		expected string
+	}{
+		{"no args", SQLArgsCount, nil, `q="SELECT 1" q.nargs=0`},
+		{"count only", SQLArgsCount, []interface{}{42, "jane@example.com"}, clean + " q.nargs=2"},
+		{"redacted", SQLArgsRedacted, []interface{}{42, nil}, clean + " q.nargs=2 q.args=[REDACTED <nil>]"},
+	}
+
+	for _, tt := range tests {
+		withTextEncoder(func(enc *textEncoder) {
+			TextSQLArgs(tt.format).apply(enc)
+			if tt.args == nil {
+				enc.AddSQL("q", "SELECT 1")
+			} else {
+				enc.AddSQL("q", query, tt.args...)
+			}
+			out := string(enc.bytes)
+			assert.Equal(t, tt.expected, out, "Unexpected output adding a query with %s.", tt.desc)
+			assert.NotContains(t, out, "jane@example.com", "Expected argument values to be omitted.")
+		})
+	}
+}
+
+func TestTextAddSQLHashedArgs(t *testing.T) {
+	hashed := func(args ...interface{}) string {
+		enc := NewTextEncoder(TextSQLArgs(SQLArgsHashed)).(*textEncoder)
+		defer enc.Free()
+		enc.AddSQL("q", "SELECT * FROM users WHERE email = ? AND id = ?", args...)
+		return string(enc.bytes)
+	}
+
+	out := hashed("jane@example.com", nil)
+	assert.Regexp(t, `q.nargs=2 q.args=\[[0-9a-f]{16} <nil>\]$`, out, "Expected a short hash of each argument.")
+	assert.NotContains(t, out, "jane@example.com", "Expected argument values to be omitted.")
+	assert.Equal(t, out, hashed("jane@example.com", nil), "Expected equal arguments to have equal hashes.")
+	assert.NotEqual(t, out, hashed("john@example.com", nil), "Expected different arguments to have different hashes.")
+}
+
+func TestTextEncoderAddNullTypes(t *testing.T) {
+	assertTextEncoderOutput(t, "nullable SQL types", "[I] hello s=foo i=null f=1.5 b=null", func(enc TextEncoder) {
+		enc.AddNullString("s", sql.NullString{String: "foo", Valid: true})
+		enc.AddNullInt64("i", sql.NullInt64{})
+		enc.AddNullFloat64("f", sql.NullFloat64{Float64: 1.5, Valid: true})
+		enc.AddNullBool("b", sql.NullBool{})
+	})
+}
@@ -0,0 +1,144 @@
+// Copyright (c) 2016 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zap
+
+import (
+	"reflect"
+	"sync"
+	"time"
+)
+
+// _durationType is special-cased by the reflection-based helpers, which would
+// otherwise render durations as integer numbers of nanoseconds.
+var _durationType = reflect.TypeOf(time.Duration(0))
+
+// _structLayouts caches the tagged fields of each struct type passed to
+// AddStruct, so that we only need to inspect struct tags once per type.
+var _structLayouts = struct {
+	sync.RWMutex
+	m map[reflect.Type][]structField
+}{m: make(map[reflect.Type][]structField)}
+
+type structField struct {
+	index int
+	name  string
+}
+
+// AddStruct adds the exported fields of a struct (or pointer to a struct) that
+// have a `log:"name"` tag as fields of a nested object. Untagged fields and
+// fields tagged `log:"-"` are skipped, and nested structs are handled
+// recursively, and time.Duration fields are rendered in Go's human-readable
+// form (e.g., "1.5s"). Values that aren't structs are added with AddObject.
+//
+// Like encoding/json, AddStruct uses reflection, but it caches each type's
+// field layout to avoid re-parsing struct tags on every call.
+func (enc *textEncoder) AddStruct(key string, v interface{}) {
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Ptr && !rv.IsNil() {
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct {
+		enc.AddObject(key, v)
+		return
+	}
+	if err := enc.AddMarshaler(key, structMarshaler{rv}); err != nil {
+		enc.AddString(key+"Error", err.Error())
+	}
+}
+
+// structMarshaler adapts a reflected struct to the LogMarshaler interface.
+type structMarshaler struct {
+	v reflect.Value
+}
+
+func (sm structMarshaler) MarshalLog(kv KeyValue) error {
+	for _, f := range structLayout(sm.v.Type()) {
+		if err := addReflected(kv, f.name, sm.v.Field(f.index)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func addReflected(kv KeyValue, key string, v reflect.Value) error {
+	if v.Type() == _durationType {
+		kv.AddString(key, time.Duration(v.Int()).String())
+		return nil
+	}
+	switch v.Kind() {
+	case reflect.Bool:
+		kv.AddBool(key, v.Bool())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		kv.AddInt64(key, v.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		kv.AddUint64(key, v.Uint())
+	case reflect.Float32:
+		kv.AddFloat32(key, float32(v.Float()))
+	case reflect.Float64:
+		kv.AddFloat64(key, v.Float())
+	case reflect.String:
+		kv.AddString(key, v.String())
+	case reflect.Struct:
+		return kv.AddMarshaler(key, structMarshaler{v})
+	case reflect.Ptr:
+		if !v.IsNil() && v.Elem().Kind() == reflect.Struct {
+			return kv.AddMarshaler(key, structMarshaler{v.Elem()})
+		}
+		if !v.IsNil() && v.Elem().Type() == _durationType {
+			return addReflected(kv, key, v.Elem())
+		}
+		return kv.AddObject(key, v.Interface())
+	default:
+		if b, ok := v.Interface().([]byte); ok {
+			kv.AddBytes(key, b)
+			return nil
+		}
+		return kv.AddObject(key, v.Interface())
+	}
+	return nil
+}
+
+func structLayout(t reflect.Type) []structField {
+	_structLayouts.RLock()
+	fields, ok := _structLayouts.m[t]
+	_structLayouts.RUnlock()
+	if ok {
+		return fields
+	}
+
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.PkgPath != "" {
+			// Unexported.
+			continue
+		}
+		name := f.Tag.Get("log")
+		if name == "" || name == "-" {
+			continue
+		}
+		fields = append(fields, structField{index: i, name: name})
+	}
+
+	_structLayouts.Lock()
+	_structLayouts.m[t] = fields
+	_structLayouts.Unlock()
+	return fields
+}
@@ -0,0 +1,108 @@
+// Copyright (c) 2016 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zap
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type taggedAddress struct {
+	City    string `log:"city"`
+	Private string
+}
+
+type taggedUser struct {
+	Name     string         `log:"name"`
+	Age      int            `log:"age"`
+	Admin    bool           `log:"is_admin"`
+	Password string         `log:"-"`
+	Untagged string         ``
+	hidden   string         `log:"hidden"`
+	Home     taggedAddress  `log:"home"`
+	Work     *taggedAddress `log:"work"`
+	Tags     []string       `log:"tags"`
+}
+
+func TestTextAddStruct(t *testing.T) {
+	user := taggedUser{
+		Name:     "jane",
+		Age:      30,
+		Admin:    true,
+		Password: "hunter2",
+		Untagged: "skipped",
+		hidden:   "skipped",
+		Home:     taggedAddress{City: "sf", Private: "skipped"},
+		Tags:     []string{"a", "b"},
+	}
+	expected := `u={name=jane age=30 is_admin=true home={city=sf} work=<nil> tags="[a b]"}`
+
+	withTextEncoder(func(enc *textEncoder) {
+		enc.AddStruct("u", user)
+		assert.Equal(t, expected, string(enc.bytes), "Unexpected output adding a tagged struct.")
+	})
+
+	user.Work = &taggedAddress{City: "nyc"}
+	withTextEncoder(func(enc *textEncoder) {
+		enc.AddStruct("u", &user)
+		assert.Equal(t, `u={name=jane age=30 is_admin=true home={city=sf} work={city=nyc} tags="[a b]"}`, string(enc.bytes), "Unexpected output adding a pointer to a tagged struct.")
+	})
+
+	withTextEncoder(func(enc *textEncoder) {
+		enc.AddStruct("n", 42)
+		assert.Equal(t, "n=42", string(enc.bytes), "Expected non-structs to fall back to AddObject.")
+	})
+
+	_structLayouts.RLock()
+	layout := _structLayouts.m[reflect.TypeOf(user)]
+	_structLayouts.RUnlock()
+	assert.Len(t, layout, 6, "Expected the struct layout to be cached.")
+}
+
+type taggedRequest struct {
+	Path    string         `log:"path"`
+	Latency time.Duration  `log:"latency"`
+	Timeout *time.Duration `log:"timeout"`
+}
+
+func TestTextAddStructDurations(t *testing.T) {
+	timeout := 2 * time.Second
+	req := taggedRequest{Path: "/", Latency: 1500 * time.Millisecond, Timeout: &timeout}
+
+	withTextEncoder(func(enc *textEncoder) {
+		enc.AddStruct("req", req)
+		assert.Equal(t, "req={path=/ latency=1.5s timeout=2s}", string(enc.bytes), "Expected durations to be human-readable.")
+	})
+
+	json := newJSONEncoder()
+	defer json.Free()
+	assert.NoError(t, json.AddMarshaler("req", structMarshaler{reflect.ValueOf(req)}), "Unexpected error adding a reflected struct.")
+	assert.Contains(t, string(json.bytes), `"latency":"1.5s"`, "Expected durations to be human-readable in JSON.")
+}
+
+func TestTextEncoderAddStruct(t *testing.T) {
+	assertTextEncoderOutput(t, "tagged struct", "[I] hello home={city=sf}", func(enc TextEncoder) {
+		enc.AddStruct("home", taggedAddress{City: "sf", Private: "skipped"})
+	})
+}
@@ -0,0 +1,78 @@
+// Copyright (c) 2016 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zap
+
+import (
+	"strconv"
+	"time"
+)
+
+// AddTime adds a timestamp formatted with the same layout as the entry's
+// timestamp, so that the two can be compared at a glance. If the encoder was
+// configured with TextNoTime, field timestamps fall back to RFC3339, and if it
+// was configured with TextEpochTime, they're written as seconds since epoch.
+// The zero time is written as an empty value.
+func (enc *textEncoder) AddTime(key string, val time.Time) {
+	enc.addKey(key)
+	if val.IsZero() {
+		return
+	}
+	if enc.epochTime {
+		enc.bytes = appendEpoch(enc.bytes, val, enc.epochPrecision)
+		return
+	}
+	layout := enc.timeFmt
+	if layout == "" {
+		layout = time.RFC3339
+	}
+	start := len(enc.bytes)
+	enc.bytes = val.AppendFormat(enc.bytes, layout)
+	if enc.shouldQuote(string(enc.bytes[start:])) {
+		formatted := string(enc.bytes[start:])
+		enc.bytes = enc.appendString(enc.bytes[:start], formatted)
+	}
+}
+
+// appendEpoch appends the number of seconds between the Unix epoch and t,
+// truncated to precision fractional digits.
+func appendEpoch(buf []byte, t time.Time, precision int) []byte {
+	sec, nsec := t.Unix(), int64(t.Nanosecond())
+	if sec < 0 && nsec > 0 {
+		// Unix rounds toward negative infinity, but we write the fraction with
+		// the same sign as the whole seconds.
+		sec++
+		nsec = int64(time.Second) - nsec
+		if sec == 0 {
+			buf = append(buf, '-')
+		}
+	}
+	buf = strconv.AppendInt(buf, sec, 10)
+	if precision == 0 {
+		return buf
+	}
+	buf = append(buf, '.')
+	var digits [9]byte
+	for i := len(digits) - 1; i >= 0; i-- {
+		digits[i] = byte('0' + nsec%10)
+		nsec /= 10
+	}
+	return append(buf, digits[:precision]...)
+}
@@ -0,0 +1,121 @@
+// Copyright (c) 2016 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zap
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTextAddTime(t *testing.T) {
+	ts := time.Date(2016, 7, 1, 12, 30, 0, 0, time.UTC)
+	tests := []struct {
+		desc     string
+		opts     []TextOption
+		val      time.Time
+		expected string
+	}{
+		{"default layout", nil, ts, "at=2016-07-01T12:30:00Z"},
+		{"custom layout", []TextOption{TextTimeFormat(time.Kitchen)}, ts, "at=12:30PM"},
+		{"layout with spaces", []TextOption{TextTimeFormat(time.ANSIC)}, ts, `at="Fri Jul  1 12:30:00 2016"`},
+		{"no entry time", []TextOption{TextNoTime()}, ts, "at=2016-07-01T12:30:00Z"},
+		{"zero time", nil, time.Time{}, "at="},
+	}
+
+	for _, tt := range tests {
+		assertTextOutput(t, tt.desc, tt.expected, func(e Encoder) {
+			for _, opt := range tt.opts {
+				opt.apply(e.(*textEncoder))
+			}
+			e.AddTime("at", tt.val)
+		})
+	}
+}
+
+func TestTextAddTimeMatchesEntryTime(t *testing.T) {
+	ts := time.Date(2016, 7, 1, 12, 30, 0, 123456789, time.FixedZone("PDT", -7*60*60))
+	for _, layout := range []string{time.RFC3339, time.RFC3339Nano, time.StampMilli, "2006-01-02T15:04:05.000Z07:00"} {
+		enc := NewTextEncoder(TextTimeFormat(layout), TextQuoteStrings(false))
+		enc.AddTime("at", ts)
+		sink := &testBuffer{}
+		require.NoError(t, enc.WriteEntry(sink, "", "hello", InfoLevel, ts), "Unexpected error writing entry.")
+		enc.Free()
+
+		formatted := ts.Format(layout)
+		assert.Equal(t, "[I] "+formatted+" hello at="+formatted+"\n", sink.String(), "Expected field and entry times to share the %q layout.", layout)
+	}
+}
+
+func TestOtherEncodersAddTime(t *testing.T) {
+	ts := time.Date(2016, 7, 1, 12, 30, 0, 0, time.FixedZone("PDT", -7*60*60))
+
+	json := NewJSONEncoder().(*jsonEncoder)
+	defer json.Free()
+	json.AddTime("at", ts)
+	assert.Equal(t, `"at":1467401400`, string(json.bytes), "Expected JSON times in seconds since epoch.")
+
+	qs := NewQueryStringEncoder().(*queryStringEncoder)
+	defer qs.Free()
+	qs.AddTime("at", ts)
+	assert.Equal(t, "at=2016-07-01T12%3A30%3A00-07%3A00", string(qs.bytes), "Unexpected query string time.")
+
+	yaml := NewYAMLFlowEncoder().(*yamlFlowEncoder)
+	defer yaml.Free()
+	yaml.AddTime("at", ts)
+	assert.Equal(t, `at: "2016-07-01T12:30:00-07:00"`, string(yaml.bytes), "Unexpected YAML time.")
+
+	NullEncoder().AddTime("at", ts)
+}
+
+func TestTextEpochTime(t *testing.T) {
+	ts := time.Date(2016, 7, 1, 12, 0, 0, 123456789, time.UTC)
+	tests := []struct {
+		desc      string
+		opts      []TextOption
+		t         time.Time
+		expected  string
+		fieldTime string
+	}{
+		{"whole seconds", []TextOption{TextEpochTime(0)}, ts, "[I] 1467374400 hello", "1467374400"},
+		{"milliseconds", []TextOption{TextEpochTime(3)}, ts, "[I] 1467374400.123 hello", "1467374400.123"},
+		{"nanoseconds", []TextOption{TextEpochTime(9)}, ts, "[I] 1467374400.123456789 hello", "1467374400.123456789"},
+		{"clamped precision", []TextOption{TextEpochTime(12)}, ts, "[I] 1467374400.123456789 hello", "1467374400.123456789"},
+		{"negative precision", []TextOption{TextEpochTime(-1)}, ts, "[I] 1467374400 hello", "1467374400"},
+		{"epoch", []TextOption{TextEpochTime(3)}, epoch, "[I] 0.000 hello", "0.000"},
+		{"before epoch", []TextOption{TextEpochTime(3)}, epoch.Add(-1500 * time.Millisecond), "[I] -1.500 hello", "-1.500"},
+		{"just before epoch", []TextOption{TextEpochTime(1)}, epoch.Add(-250 * time.Millisecond), "[I] -0.2 hello", "-0.2"},
+		{"epoch wins over TextNoTime", []TextOption{TextEpochTime(0), TextNoTime()}, ts, "[I] 1467374400 hello", "1467374400"},
+		{"TextNoTime before epoch", []TextOption{TextNoTime(), TextEpochTime(0)}, ts, "[I] 1467374400 hello", "1467374400"},
+	}
+
+	for _, tt := range tests {
+		enc := NewTextEncoder(tt.opts...)
+		sink := &testBuffer{}
+		require.NoError(t, enc.WriteEntry(sink, "", "hello", InfoLevel, tt.t), "Unexpected error writing entry.")
+		assert.Equal(t, tt.expected, sink.Stripped(), "Unexpected entry timestamp with %s.", tt.desc)
+		enc.AddTime("at", tt.t)
+		assert.Equal(t, "at="+tt.fieldTime, string(enc.(*textEncoder).bytes), "Expected field times to match entry times with %s.", tt.desc)
+		enc.Free()
+	}
+}
@@ -0,0 +1,85 @@
+// Copyright (c) 2016 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zap
+
+import (
+	"sync"
+	"time"
+)
+
+// A timestampCache holds the most recently formatted entry timestamp, shared
+// by an encoder and its clones. Since the cached string is reused for every
+// entry in the same second, it's only used for layouts without fractional
+// seconds.
+type timestampCache struct {
+	sync.Mutex
+	layout string
+	// Whether layout omits fractional seconds.
+	coarse    bool
+	sec       int64
+	loc       *time.Location
+	formatted []byte
+	// The number of times a timestamp was formatted, for tests.
+	formats int
+}
+
+// append appends t formatted with layout, reformatting only if t is in a
+// different second (or location) than the previous timestamp.
+func (c *timestampCache) append(buf []byte, t time.Time, layout string) []byte {
+	c.Lock()
+	defer c.Unlock()
+	if layout != c.layout {
+		c.layout = layout
+		c.coarse = isCoarseLayout(layout)
+		c.formatted = c.formatted[:0]
+	}
+	if !c.coarse {
+		c.formats++
+		return t.AppendFormat(buf, layout)
+	}
+	if sec := t.Unix(); len(c.formatted) == 0 || sec != c.sec || t.Location() != c.loc {
+		c.formats++
+		c.sec, c.loc = sec, t.Location()
+		c.formatted = t.AppendFormat(c.formatted[:0], layout)
+	}
+	return append(buf, c.formatted...)
+}
+
+// isCoarseLayout reports whether a time layout formats every instant in a
+// second identically.
+func isCoarseLayout(layout string) bool {
+	start := time.Date(2006, time.January, 2, 15, 4, 5, 0, time.UTC)
+	end := start.Add(time.Second - 1)
+	return start.Format(layout) == end.Format(layout)
+}
+
+// TextCachedTimestamps caches each entry's formatted timestamp, so entries
+// written in the same second reuse it rather than formatting the time again.
+// Formatting is a significant part of the cost of writing an entry, so this
+// helps high-volume loggers. The cache is shared by the encoder and its
+// clones. Layouts with fractional seconds can't be cached, so they're
+// formatted for every entry as usual; timestamps in fields added with AddTime
+// are never cached.
+func TextCachedTimestamps() TextOption {
+	return textOptionFunc(func(enc *textEncoder) {
+		enc.timestamps = &timestampCache{}
+	})
+}
@@ -0,0 +1,92 @@
+// Copyright (c) 2016 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zap
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTextCachedTimestamps(t *testing.T) {
+	enc := NewTextEncoder(TextCachedTimestamps()).(*textEncoder)
+	defer enc.Free()
+	clone := enc.Clone()
+	defer clone.Free()
+
+	base := time.Date(2016, time.July, 1, 12, 0, 59, 0, time.UTC)
+	buf := &testBuffer{}
+	for _, ts := range []time.Time{
+		base,
+		base.Add(time.Millisecond),
+		base.Add(999 * time.Millisecond),
+		// Crossing a second boundary.
+		base.Add(time.Second),
+		base.Add(time.Second + time.Millisecond),
+		// Same second in a different location.
+		base.Add(time.Second).In(time.FixedZone("UTC-8", -8*60*60)),
+	} {
+		require.NoError(t, clone.WriteEntry(buf, "", "hi", InfoLevel, ts), "Unexpected error writing entry.")
+	}
+	assert.Equal(t, []string{
+		"[I] 2016-07-01T12:00:59Z hi",
+		"[I] 2016-07-01T12:00:59Z hi",
+		"[I] 2016-07-01T12:00:59Z hi",
+		"[I] 2016-07-01T12:01:00Z hi",
+		"[I] 2016-07-01T12:01:00Z hi",
+		"[I] 2016-07-01T04:01:00-08:00 hi",
+	}, buf.Lines(), "Unexpected timestamps.")
+	assert.Equal(t, 3, enc.timestamps.formats, "Expected timestamps to be formatted once per second and location.")
+}
+
+func TestTextCachedTimestampsFractionalLayout(t *testing.T) {
+	enc := NewTextEncoder(TextCachedTimestamps(), TextTimeFormat(time.RFC3339Nano)).(*textEncoder)
+	defer enc.Free()
+
+	base := time.Date(2016, time.July, 1, 12, 0, 59, 0, time.UTC)
+	buf := &testBuffer{}
+	for _, ts := range []time.Time{base.Add(time.Millisecond), base.Add(2 * time.Millisecond)} {
+		require.NoError(t, enc.WriteEntry(buf, "", "hi", InfoLevel, ts), "Unexpected error writing entry.")
+	}
+	assert.Equal(t, []string{
+		"[I] 2016-07-01T12:00:59.001Z hi",
+		"[I] 2016-07-01T12:00:59.002Z hi",
+	}, buf.Lines(), "Expected fractional seconds to be formatted for every entry.")
+}
+
+func TestIsCoarseLayout(t *testing.T) {
+	tests := []struct {
+		layout string
+		coarse bool
+	}{
+		{time.RFC3339, true},
+		{time.Kitchen, true},
+		{"2006-01-02 15:04", true},
+		{time.RFC3339Nano, false},
+		{time.StampMilli, false},
+		{"15:04:05.000", false},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.coarse, isCoarseLayout(tt.layout), "Unexpected result for layout %q.", tt.layout)
+	}
+}
@@ -0,0 +1,42 @@
+// Copyright (c) 2016 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zap
+
+import "time"
+
+// AddLocation adds the name of a time zone (e.g., "America/New_York"), which
+// records the zone a computation used without logging a whole time. A nil
+// location is treated as UTC, as it is by the time package.
+func (enc *textEncoder) AddLocation(key string, loc *time.Location) {
+	enc.addKey(key)
+	if loc == nil {
+		enc.bytes = append(enc.bytes, "UTC"...)
+		return
+	}
+	enc.bytes = append(enc.bytes, loc.String()...)
+}
+
+// AddZoneOffset adds the offset from UTC of the time's zone, at that time, in
+// ISO 8601 form (e.g., "-05:00").
+func (enc *textEncoder) AddZoneOffset(key string, t time.Time) {
+	enc.addKey(key)
+	enc.bytes = t.AppendFormat(enc.bytes, "-07:00")
+}
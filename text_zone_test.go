@@ -0,0 +1,78 @@
+// Copyright (c) 2016 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zap
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTextAddLocation(t *testing.T) {
+	ny, err := time.LoadLocation("America/New_York")
+	require.NoError(t, err, "Failed to load time zone.")
+
+	tests := []struct {
+		loc      *time.Location
+		expected string
+	}{
+		{ny, "tz=America/New_York"},
+		{time.UTC, "tz=UTC"},
+		{time.FixedZone("IST", 5*60*60+30*60), "tz=IST"},
+		{nil, "tz=UTC"},
+	}
+
+	for _, tt := range tests {
+		assertTextOutput(t, "location", tt.expected, func(e Encoder) {
+			e.(*textEncoder).AddLocation("tz", tt.loc)
+		})
+	}
+}
+
+func TestTextAddZoneOffset(t *testing.T) {
+	ny, err := time.LoadLocation("America/New_York")
+	require.NoError(t, err, "Failed to load time zone.")
+
+	tests := []struct {
+		t        time.Time
+		expected string
+	}{
+		{time.Date(2016, 1, 1, 0, 0, 0, 0, ny), "off=-05:00"},
+		{time.Date(2016, 7, 1, 0, 0, 0, 0, ny), "off=-04:00"},
+		{time.Date(2016, 1, 1, 0, 0, 0, 0, time.FixedZone("IST", 5*60*60+30*60)), "off=+05:30"},
+		{epoch, "off=+00:00"},
+	}
+
+	for _, tt := range tests {
+		assertTextOutput(t, "zone offset", tt.expected, func(e Encoder) {
+			e.(*textEncoder).AddZoneOffset("off", tt.t)
+		})
+	}
+}
+
+func TestTextEncoderAddZone(t *testing.T) {
+	ist := time.FixedZone("IST", 5*60*60+30*60)
+	assertTextEncoderOutput(t, "zone", "[I] hello tz=IST off=+05:30", func(enc TextEncoder) {
+		enc.AddLocation("tz", ist)
+		enc.AddZoneOffset("off", time.Date(2016, 1, 1, 0, 0, 0, 0, ist))
+	})
+}
@@ -0,0 +1,77 @@
+// Copyright (c) 2016 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zap
+
+import (
+	"io"
+	"sort"
+)
+
+// _trailerMessage is the message of entries written by WriteTrailer.
+const _trailerMessage = "summary"
+
+// A TrailerWriter is an Encoder that can write an entry summarizing a session.
+// The text, ANSI, and JSON encoders implement it.
+type TrailerWriter interface {
+	Encoder
+
+	// WriteTrailer writes an info-level entry with the message "summary" and
+	// the supplied fields, in sorted order.
+	WriteTrailer(sink io.Writer, summary map[string]interface{}) error
+}
+
+// writeTrailer writes an info-level entry with the message "summary" and the
+// supplied fields, in sorted order, using a clone of the encoder.
+func writeTrailer(enc Encoder, sink io.Writer, summary map[string]interface{}) error {
+	keys := make([]string, 0, len(summary))
+	for k := range summary {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	trailer := enc.Clone()
+	defer trailer.Free()
+	for _, k := range keys {
+		addValue(trailer, k, summary[k])
+	}
+	return trailer.WriteEntry(sink, "", _trailerMessage, InfoLevel, _timeNow())
+}
+
+// WriteTrailer writes a final entry summarizing a session (e.g., the number
+// of errors, the run's duration, and its exit status), which gives CLI tools a
+// consistent end-of-run line. The entry is written at InfoLevel with the
+// message "summary", includes the encoder's accumulated fields, and renders
+// the summary fields in sorted order.
+func (enc *textEncoder) WriteTrailer(sink io.Writer, summary map[string]interface{}) error {
+	return writeTrailer(enc, sink, summary)
+}
+
+// WriteTrailer writes a final entry summarizing a session; see the text
+// encoder's WriteTrailer for details.
+func (enc *ansiEncoder) WriteTrailer(sink io.Writer, summary map[string]interface{}) error {
+	return writeTrailer(enc, sink, summary)
+}
+
+// WriteTrailer writes a final entry summarizing a session; see the text
+// encoder's WriteTrailer for details.
+func (enc *jsonEncoder) WriteTrailer(sink io.Writer, summary map[string]interface{}) error {
+	return writeTrailer(enc, sink, summary)
+}
@@ -0,0 +1,76 @@
+// Copyright (c) 2016 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zap
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWriteTrailer(t *testing.T) {
+	defer func() { _timeNow = time.Now }()
+	_timeNow = func() time.Time { return epoch }
+
+	summary := map[string]interface{}{
+		"errors":   3,
+		"duration": 1500 * time.Millisecond,
+		"exit":     "ok",
+	}
+	tests := []struct {
+		enc      Encoder
+		expected string
+	}{
+		{
+			NewTextEncoder(),
+			"[I] 1970-01-01T00:00:00Z summary foo=bar duration=1.5s errors=3 exit=ok",
+		},
+		{
+			NewANSIEncoder(AnsiTextOption(TextNoTime())),
+			defaultInfoColor + "[I] summary foo=bar duration=1.5s errors=3 exit=ok" + resetColor,
+		},
+		{
+			NewJSONEncoder(),
+			`{"level":"info","ts":0,"msg":"summary","foo":"bar","duration":1500000000,"errors":3,"exit":"ok"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		tt.enc.AddString("foo", "bar")
+		sink := &testBuffer{}
+		assert.NoError(t, tt.enc.(TrailerWriter).WriteTrailer(sink, summary), "Unexpected failure writing trailer.")
+		assert.Equal(t, tt.expected, sink.Stripped(), "Unexpected trailer output.")
+
+		sink.Reset()
+		assert.NoError(t, tt.enc.WriteEntry(sink, "", "after", InfoLevel, epoch), "Unexpected failure writing entry.")
+		assert.NotContains(t, sink.String(), "errors", "Writing a trailer shouldn't modify the encoder.")
+		tt.enc.Free()
+	}
+}
+
+func TestTextEncoderWriteTrailer(t *testing.T) {
+	enc := NewTextEncoder(TextNoTime()).(TextEncoder)
+	defer enc.Free()
+	sink := &testBuffer{}
+	assert.NoError(t, enc.WriteTrailer(sink, map[string]interface{}{"errors": 0}), "Unexpected failure writing trailer.")
+	assert.Equal(t, "[I] summary errors=0", sink.Stripped(), "Unexpected trailer output.")
+}
@@ -0,0 +1,185 @@
+// Copyright (c) 2016 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zap
+
+import (
+	"errors"
+	"io"
+	"net"
+	"sync"
+	"time"
+)
+
+const (
+	// _socketSinkBufferSize bounds the bytes a socket sink holds while it's
+	// disconnected.
+	_socketSinkBufferSize = 64 * 1024
+	// Delays between failed connection attempts start at the minimum and
+	// double up to the maximum.
+	_socketSinkMinBackoff = 10 * time.Millisecond
+	_socketSinkMaxBackoff = 5 * time.Second
+)
+
+var (
+	errSinkClosed     = errors.New("can't write to a closed sink")
+	errSinkBufferFull = errors.New("sink is disconnected and its buffer is full, dropping entry")
+)
+
+// A socketSink writes to a socket, reconnecting as necessary.
+type socketSink struct {
+	sync.Mutex
+
+	network, addr string
+	conn          net.Conn
+	// Entries written while disconnected, oldest first.
+	pending  [][]byte
+	buffered int
+	// After a failed connection attempt, the sink waits until nextDial before
+	// trying again.
+	backoff  time.Duration
+	nextDial time.Time
+	// The most recent connection or write failure.
+	err    error
+	closed bool
+}
+
+// NewUnixSocketSink creates a sink that writes to the Unix domain socket at
+// path, which is useful for shipping logs to a local agent. It connects on the
+// first write rather than immediately, so the agent needn't be running yet.
+//
+// If the connection fails, the sink reconnects transparently. Entries written
+// while it's disconnected are buffered, up to 64KiB, and sent in order once it
+// reconnects; entries that don't fit are dropped and Write returns an error.
+// An entry interrupted by a failure is sent again in full, so the agent may
+// see the beginning of it twice, but never a fragment at the start of a
+// connection.
+// Reconnection is attempted immediately after a failed write and then with an
+// exponential backoff, between 10ms and 5s, so a missing agent doesn't slow
+// every write. Like encoders, the sink treats each call to Write as an entry.
+// It's safe to use concurrently. Closing the sink discards any buffered
+// entries.
+func NewUnixSocketSink(path string) io.WriteCloser {
+	return &socketSink{network: "unix", addr: path}
+}
+
+func (s *socketSink) Write(bs []byte) (int, error) {
+	s.Lock()
+	defer s.Unlock()
+	if s.closed {
+		return 0, errSinkClosed
+	}
+	// If the write fails, retry once on a fresh connection, since the failure
+	// may have been caused by the listener restarting. Receivers parse each
+	// connection separately, so an entry interrupted by a failure is always
+	// sent again in full rather than continued where it stopped.
+	for i := 0; i < 2 && s.connected(); i++ {
+		_, err := s.conn.Write(bs)
+		if err == nil {
+			return len(bs), nil
+		}
+		s.err = err
+		s.disconnect()
+	}
+	if s.buffered+len(bs) > _socketSinkBufferSize {
+		return 0, errSinkBufferFull
+	}
+	s.pending = append(s.pending, append([]byte(nil), bs...))
+	s.buffered += len(bs)
+	return len(bs), nil
+}
+
+// Sync sends any buffered entries, returning an error if the sink can't
+// reconnect.
+func (s *socketSink) Sync() error {
+	s.Lock()
+	defer s.Unlock()
+	if s.closed {
+		return errSinkClosed
+	}
+	if !s.connected() {
+		return s.err
+	}
+	return nil
+}
+
+func (s *socketSink) Close() error {
+	s.Lock()
+	defer s.Unlock()
+	if s.closed {
+		return nil
+	}
+	s.closed = true
+	s.pending = nil
+	s.buffered = 0
+	if s.conn == nil {
+		return nil
+	}
+	err := s.conn.Close()
+	s.conn = nil
+	return err
+}
+
+// connected reports whether the sink has a connection with no entries left
+// to send, dialing and sending buffered entries if necessary. The caller must
+// hold the lock.
+func (s *socketSink) connected() bool {
+	if s.conn == nil {
+		if _timeNow().Before(s.nextDial) {
+			return false
+		}
+		conn, err := net.Dial(s.network, s.addr)
+		if err != nil {
+			s.err = err
+			s.backoff *= 2
+			if s.backoff < _socketSinkMinBackoff {
+				s.backoff = _socketSinkMinBackoff
+			}
+			if s.backoff > _socketSinkMaxBackoff {
+				s.backoff = _socketSinkMaxBackoff
+			}
+			s.nextDial = _timeNow().Add(s.backoff)
+			return false
+		}
+		s.conn = conn
+		s.backoff = 0
+	}
+	for len(s.pending) > 0 {
+		entry := s.pending[0]
+		if _, err := s.conn.Write(entry); err != nil {
+			// The entry stays buffered, so it's sent in full after reconnecting.
+			s.err = err
+			s.disconnect()
+			return false
+		}
+		s.pending[0] = nil
+		s.pending = s.pending[1:]
+		s.buffered -= len(entry)
+	}
+	s.pending = nil
+	return true
+}
+
+// disconnect closes a failed connection. Since the backoff is reset by a
+// successful connection, the next attempt to reconnect is made immediately.
+func (s *socketSink) disconnect() {
+	s.conn.Close()
+	s.conn = nil
+}
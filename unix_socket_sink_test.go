@@ -0,0 +1,238 @@
+// Copyright (c) 2016 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zap
+
+import (
+	"bufio"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// flakyListener is a Unix socket server that can drop its connections.
+type flakyListener struct {
+	sync.Mutex
+	t     testing.TB
+	path  string
+	ln    net.Listener
+	conns []net.Conn
+	lines chan string
+}
+
+func newFlakyListener(t testing.TB, path string) *flakyListener {
+	l := &flakyListener{t: t, path: path, lines: make(chan string, 1024)}
+	l.listen()
+	return l
+}
+
+func (l *flakyListener) listen() {
+	ln, err := net.Listen("unix", l.path)
+	require.NoError(l.t, err, "Failed to listen on Unix socket.")
+	l.ln = ln
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			l.Lock()
+			l.conns = append(l.conns, conn)
+			l.Unlock()
+			go func() {
+				// Like an agent, discard an incomplete line at the end of a
+				// connection.
+				r := bufio.NewReader(conn)
+				for {
+					line, err := r.ReadString('\n')
+					if err != nil {
+						return
+					}
+					l.lines <- strings.TrimSuffix(line, "\n")
+				}
+			}()
+		}
+	}()
+}
+
+// drop closes all the accepted connections.
+func (l *flakyListener) drop() {
+	l.Lock()
+	defer l.Unlock()
+	for _, c := range l.conns {
+		c.Close()
+	}
+	l.conns = nil
+}
+
+func (l *flakyListener) close() {
+	l.ln.Close()
+	l.drop()
+}
+
+func (l *flakyListener) next() string {
+	select {
+	case line := <-l.lines:
+		return line
+	case <-time.After(5 * time.Second):
+		l.t.Fatal("Timed out waiting for a line from the sink.")
+		return ""
+	}
+}
+
+func withSocketPath(t testing.TB, f func(path string)) {
+	dir, err := ioutil.TempDir("", "zap-unix-sink")
+	require.NoError(t, err, "Failed to create temporary directory.")
+	defer os.RemoveAll(dir)
+	f(filepath.Join(dir, "log.sock"))
+}
+
+func TestUnixSocketSinkReconnects(t *testing.T) {
+	withSocketPath(t, func(path string) {
+		l := newFlakyListener(t, path)
+		defer l.close()
+		sink := NewUnixSocketSink(path)
+		defer sink.Close()
+		enc := NewTextEncoder(TextNoTime())
+		defer enc.Free()
+
+		require.NoError(t, enc.WriteEntry(sink, "", "before", InfoLevel, epoch), "Unexpected failure writing entry.")
+		assert.Equal(t, "[I] before", l.next(), "Unexpected first entry.")
+
+		for i := 0; i < 3; i++ {
+			l.drop()
+			msg := fmt.Sprint("after drop ", i)
+			require.NoError(t, enc.WriteEntry(sink, "", msg, InfoLevel, epoch), "Expected the sink to reconnect transparently.")
+			assert.Equal(t, "[I] "+msg, l.next(), "Expected entries to resume after reconnecting.")
+		}
+	})
+}
+
+func TestUnixSocketSinkBuffersWhileDisconnected(t *testing.T) {
+	withSocketPath(t, func(path string) {
+		withFakeClock(func(advance func(time.Duration)) {
+			l := newFlakyListener(t, path)
+			sink := NewUnixSocketSink(path)
+			defer sink.Close()
+			enc := NewTextEncoder(TextNoTime())
+			defer enc.Free()
+
+			require.NoError(t, enc.WriteEntry(sink, "", "first", InfoLevel, epoch), "Unexpected failure writing entry.")
+			assert.Equal(t, "[I] first", l.next(), "Unexpected first entry.")
+
+			l.close()
+			for _, msg := range []string{"buffered 1", "buffered 2"} {
+				require.NoError(t, enc.WriteEntry(sink, "", msg, InfoLevel, epoch), "Expected entries to be buffered while disconnected.")
+			}
+			assert.Error(t, sink.(*socketSink).Sync(), "Expected Sync to fail while disconnected.")
+
+			l = newFlakyListener(t, path)
+			defer l.close()
+			require.NoError(t, enc.WriteEntry(sink, "", "during backoff", InfoLevel, epoch), "Expected entries to be buffered during backoff.")
+			select {
+			case line := <-l.lines:
+				t.Fatalf("Expected no reconnection during backoff, got %q.", line)
+			case <-time.After(10 * time.Millisecond):
+			}
+
+			advance(time.Second)
+			require.NoError(t, enc.WriteEntry(sink, "", "reconnected", InfoLevel, epoch), "Unexpected failure writing entry.")
+			for _, msg := range []string{"buffered 1", "buffered 2", "during backoff", "reconnected"} {
+				assert.Equal(t, "[I] "+msg, l.next(), "Expected buffered entries to be sent in order.")
+			}
+			assert.NoError(t, sink.(*socketSink).Sync(), "Unexpected failure syncing a connected sink.")
+		})
+	})
+}
+
+func TestUnixSocketSinkBufferLimit(t *testing.T) {
+	withSocketPath(t, func(path string) {
+		sink := NewUnixSocketSink(path)
+		defer sink.Close()
+		entry := []byte(strings.Repeat("x", 1023) + "\n")
+		for i := 0; i < _socketSinkBufferSize/len(entry); i++ {
+			_, err := sink.Write(entry)
+			require.NoError(t, err, "Expected entries to be buffered with no listener.")
+		}
+		_, err := sink.Write(entry)
+		assert.Equal(t, errSinkBufferFull, err, "Expected an error once the buffer is full.")
+	})
+}
+
+func TestUnixSocketSinkClose(t *testing.T) {
+	withSocketPath(t, func(path string) {
+		l := newFlakyListener(t, path)
+		defer l.close()
+		sink := NewUnixSocketSink(path)
+		_, err := sink.Write([]byte("hello\n"))
+		require.NoError(t, err, "Unexpected failure writing to sink.")
+		assert.NoError(t, sink.Close(), "Unexpected failure closing sink.")
+		assert.NoError(t, sink.Close(), "Expected closing twice to succeed.")
+		_, err = sink.Write([]byte("goodbye\n"))
+		assert.Equal(t, errSinkClosed, err, "Expected writes to a closed sink to fail.")
+	})
+}
+
+func TestUnixSocketSinkConcurrency(t *testing.T) {
+	withSocketPath(t, func(path string) {
+		l := newFlakyListener(t, path)
+		defer l.close()
+		sink := NewUnixSocketSink(path)
+		defer sink.Close()
+
+		var wg sync.WaitGroup
+		for i := 0; i < 5; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				enc := NewTextEncoder(TextNoTime())
+				defer enc.Free()
+				for j := 0; j < 50; j++ {
+					enc.WriteEntry(sink, "", fmt.Sprintf("goroutine %d entry %d", i, j), InfoLevel, epoch)
+				}
+			}(i)
+		}
+		for i := 0; i < 5; i++ {
+			l.drop()
+			time.Sleep(time.Millisecond)
+		}
+		wg.Wait()
+
+		l.drop()
+		_, err := sink.Write([]byte("done\n"))
+		require.NoError(t, err, "Unexpected failure writing final entry.")
+		for {
+			line := l.next()
+			if line == "done" {
+				break
+			}
+			assert.Regexp(t, `^\[I\] goroutine \d entry \d+$`, line, "Expected entries to be written intact.")
+		}
+	})
+}
@@ -0,0 +1,68 @@
+// Copyright (c) 2016 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zap
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"time"
+
+	"github.com/uber-go/atomic"
+)
+
+// _uuidState is the state of a splitmix64 generator. Advancing it atomically
+// makes UUID generation lock-free.
+var _uuidState = atomic.NewUint64(uuidSeed())
+
+func uuidSeed() uint64 {
+	var seed [8]byte
+	if _, err := rand.Read(seed[:]); err != nil {
+		return uint64(time.Now().UnixNano())
+	}
+	return binary.LittleEndian.Uint64(seed[:])
+}
+
+// fastRandom returns a pseudo-random uint64. It's not cryptographically
+// secure, but it's fast, safe for concurrent use, and doesn't allocate.
+func fastRandom() uint64 {
+	z := _uuidState.Add(0x9e3779b97f4a7c15)
+	z = (z ^ (z >> 30)) * 0xbf58476d1ce4e5b9
+	z = (z ^ (z >> 27)) * 0x94d049bb133111eb
+	return z ^ (z >> 31)
+}
+
+// appendUUID appends a random, RFC 4122 version 4 UUID to the buffer.
+func appendUUID(buf []byte) []byte {
+	var u [16]byte
+	binary.BigEndian.PutUint64(u[:8], fastRandom())
+	binary.BigEndian.PutUint64(u[8:], fastRandom())
+	u[6] = (u[6] & 0x0f) | 0x40 // version 4
+	u[8] = (u[8] & 0x3f) | 0x80 // RFC 4122 variant
+
+	for i, b := range u {
+		switch i {
+		case 4, 6, 8, 10:
+			buf = append(buf, '-')
+		}
+		buf = append(buf, _hex[b>>4], _hex[b&0xF])
+	}
+	return buf
+}
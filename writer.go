@@ -21,6 +21,7 @@
 package zap
 
 import (
+	"fmt"
 	"io"
 	"io/ioutil"
 	"sync"
@@ -33,6 +34,18 @@ var (
 	DiscardOutput = Output(Discard)
 )
 
+// A ShortWriteError is returned by encoders' WriteEntry methods when a sink
+// accepts only part of an entry without reporting an error of its own. Callers
+// can use errors.As to detect the condition (e.g., to retry).
+type ShortWriteError struct {
+	// Bytes written and the size of the entry.
+	Wrote, Expected int
+}
+
+func (e *ShortWriteError) Error() string {
+	return fmt.Sprintf("incomplete write: only wrote %v of %v bytes", e.Wrote, e.Expected)
+}
+
 // A WriteFlusher is an io.Writer that can also flush any buffered data.
 type WriteFlusher interface {
 	io.Writer
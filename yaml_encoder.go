@@ -0,0 +1,394 @@
+// Copyright (c) 2016 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zap
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+	"unicode/utf8"
+	"unsafe"
+)
+
+var yamlFlowPool = sync.Pool{New: func() interface{} {
+	return &yamlFlowEncoder{
+		bytes: make([]byte, 0, _initialBufSize),
+	}
+}}
+
+type yamlFlowEncoder struct {
+	bytes []byte
+}
+
+// NewYAMLFlowEncoder creates an encoder that renders each entry as a
+// single-line YAML flow mapping (e.g., `{level: info, ts: "2016-07-01T12:00:00Z",
+// msg: hello, user: jane}`), which YAML tooling can parse line by line. The
+// level, timestamp, logger name, and message are written under the reserved
+// keys "level", "ts", "logger", and "msg". Keys and strings are written as
+// plain scalars when that's unambiguous and double-quoted otherwise.
+func NewYAMLFlowEncoder() Encoder {
+	enc := yamlFlowPool.Get().(*yamlFlowEncoder)
+	enc.truncate()
+	return enc
+}
+
+func (enc *yamlFlowEncoder) Free() {
+	yamlFlowPool.Put(enc)
+}
+
+func (enc *yamlFlowEncoder) AddString(key, val string) {
+	enc.addKey(key)
+	enc.bytes = appendYAMLString(enc.bytes, val)
+}
+
+func (enc *yamlFlowEncoder) AddBool(key string, val bool) {
+	enc.addKey(key)
+	enc.bytes = strconv.AppendBool(enc.bytes, val)
+}
+
+func (enc *yamlFlowEncoder) AddByte(key string, val byte) {
+	enc.addKey(key)
+	enc.bytes = strconv.AppendUint(enc.bytes, uint64(val), 10)
+}
+
+func (enc *yamlFlowEncoder) AddBytes(key string, val []byte) {
+	enc.addKey(key)
+	enc.bytes = append(enc.bytes, '"')
+	enc.bytes = hexEncode(enc.bytes, val)
+	enc.bytes = append(enc.bytes, '"')
+}
+
+func (enc *yamlFlowEncoder) AddInt(key string, val int) {
+	enc.AddInt64(key, int64(val))
+}
+
+func (enc *yamlFlowEncoder) AddInt64(key string, val int64) {
+	enc.addKey(key)
+	enc.bytes = strconv.AppendInt(enc.bytes, val, 10)
+}
+
+func (enc *yamlFlowEncoder) AddUint(key string, val uint) {
+	enc.AddUint64(key, uint64(val))
+}
+
+func (enc *yamlFlowEncoder) AddUint64(key string, val uint64) {
+	enc.addKey(key)
+	enc.bytes = strconv.AppendUint(enc.bytes, val, 10)
+}
+
+func (enc *yamlFlowEncoder) AddInt32(key string, val int32) {
+	enc.AddInt64(key, int64(val))
+}
+
+func (enc *yamlFlowEncoder) AddInt16(key string, val int16) {
+	enc.AddInt64(key, int64(val))
+}
+
+func (enc *yamlFlowEncoder) AddInt8(key string, val int8) {
+	enc.AddInt64(key, int64(val))
+}
+
+func (enc *yamlFlowEncoder) AddUint32(key string, val uint32) {
+	enc.AddUint64(key, uint64(val))
+}
+
+func (enc *yamlFlowEncoder) AddUint16(key string, val uint16) {
+	enc.AddUint64(key, uint64(val))
+}
+
+func (enc *yamlFlowEncoder) AddUint8(key string, val uint8) {
+	enc.AddUint64(key, uint64(val))
+}
+
+func (enc *yamlFlowEncoder) AddFloat32(key string, val float32) {
+	enc.addFloat(key, float64(val), 32)
+}
+
+func (enc *yamlFlowEncoder) AddFloat64(key string, val float64) {
+	enc.addFloat(key, val, 64)
+}
+
+func (enc *yamlFlowEncoder) addFloat(key string, val float64, bitSize int) {
+	enc.addKey(key)
+	switch {
+	case math.IsNaN(val):
+		enc.bytes = append(enc.bytes, ".nan"...)
+	case math.IsInf(val, 1):
+		enc.bytes = append(enc.bytes, ".inf"...)
+	case math.IsInf(val, -1):
+		enc.bytes = append(enc.bytes, "-.inf"...)
+	default:
+		enc.bytes = strconv.AppendFloat(enc.bytes, val, 'g', -1, bitSize)
+	}
+}
+
+func (enc *yamlFlowEncoder) AddDuration(key string, val time.Duration) {
+	enc.addKey(key)
+	enc.bytes = appendYAMLString(enc.bytes, val.String())
+}
+
+func (enc *yamlFlowEncoder) AddTime(key string, val time.Time) {
+	enc.addKey(key)
+	enc.bytes = appendYAMLString(enc.bytes, val.Format(time.RFC3339Nano))
+}
+
+func (enc *yamlFlowEncoder) AddError(key string, err error) {
+	if err == nil {
+		enc.addKey(key)
+		enc.bytes = append(enc.bytes, "null"...)
+		return
+	}
+	enc.AddString(key, errorChain(err))
+}
+
+func (enc *yamlFlowEncoder) AddComplex128(key string, val complex128) {
+	enc.addKey(key)
+	enc.bytes = appendYAMLString(enc.bytes, string(appendComplex(nil, val, 64)))
+}
+
+func (enc *yamlFlowEncoder) AddComplex64(key string, val complex64) {
+	enc.addKey(key)
+	enc.bytes = appendYAMLString(enc.bytes, string(appendComplex(nil, complex128(val), 32)))
+}
+
+// AddStrings adds the strings as a flow sequence (e.g., `tags: [a, "b c"]`).
+func (enc *yamlFlowEncoder) AddStrings(key string, vals []string) {
+	enc.addKey(key)
+	enc.bytes = append(enc.bytes, '[')
+	for i, v := range vals {
+		if i > 0 {
+			enc.bytes = append(enc.bytes, ", "...)
+		}
+		enc.bytes = appendYAMLString(enc.bytes, v)
+	}
+	enc.bytes = append(enc.bytes, ']')
+}
+
+// AddInts adds the integers as a flow sequence.
+func (enc *yamlFlowEncoder) AddInts(key string, vals []int) {
+	enc.addKey(key)
+	enc.bytes = append(enc.bytes, '[')
+	for i, v := range vals {
+		if i > 0 {
+			enc.bytes = append(enc.bytes, ", "...)
+		}
+		enc.bytes = strconv.AppendInt(enc.bytes, int64(v), 10)
+	}
+	enc.bytes = append(enc.bytes, ']')
+}
+
+func (enc *yamlFlowEncoder) AddRune(key string, val rune) {
+	enc.AddString(key, string(val))
+}
+
+func (enc *yamlFlowEncoder) AddBase64Bytes(key string, val []byte) {
+	enc.addKey(key)
+	enc.bytes = append(enc.bytes, '"')
+	enc.bytes = appendBase64(enc.bytes, val)
+	enc.bytes = append(enc.bytes, '"')
+}
+
+// AddStackFrames adds the stack as a flow sequence of flow mappings.
+func (enc *yamlFlowEncoder) AddStackFrames(key string) {
+	enc.addKey(key)
+	enc.bytes = append(enc.bytes, '[')
+	for i, f := range takeStackFrames() {
+		if i > 0 {
+			enc.bytes = append(enc.bytes, ", "...)
+		}
+		enc.bytes = append(enc.bytes, '{')
+		f.MarshalLog(enc)
+		enc.bytes = append(enc.bytes, '}')
+	}
+	enc.bytes = append(enc.bytes, ']')
+}
+
+func (enc *yamlFlowEncoder) AddUintptr(key string, val uintptr) {
+	enc.addKey(key)
+	enc.bytes = appendAddress(enc.bytes, val)
+}
+
+func (enc *yamlFlowEncoder) AddPointer(key string, val unsafe.Pointer) {
+	enc.AddUintptr(key, uintptr(val))
+}
+
+// AddMarshaler adds the object as a nested flow mapping.
+func (enc *yamlFlowEncoder) AddMarshaler(key string, obj LogMarshaler) error {
+	enc.addKey(key)
+	enc.bytes = append(enc.bytes, '{')
+	err := obj.MarshalLog(enc)
+	enc.bytes = append(enc.bytes, '}')
+	return err
+}
+
+func (enc *yamlFlowEncoder) AddObject(key string, obj interface{}) error {
+	if ok, err := addFormattedObject(enc, key, obj); ok {
+		return err
+	}
+	enc.AddString(key, fmt.Sprintf("%+v", obj))
+	return nil
+}
+
+func (enc *yamlFlowEncoder) Clone() Encoder {
+	clone := yamlFlowPool.Get().(*yamlFlowEncoder)
+	clone.truncate()
+	clone.bytes = append(clone.bytes, enc.bytes...)
+	return clone
+}
+
+func (enc *yamlFlowEncoder) WriteEntry(sink io.Writer, name string, msg string, lvl Level, t time.Time) error {
+	if sink == nil {
+		return errNilSink
+	}
+
+	final := yamlFlowPool.Get().(*yamlFlowEncoder)
+	final.truncate()
+	final.bytes = append(final.bytes, '{')
+	final.AddString("level", lvl.String())
+	final.AddString("ts", t.Format(time.RFC3339Nano))
+	if name != "" {
+		final.AddString("logger", name)
+	}
+	final.AddString("msg", msg)
+	if len(enc.bytes) > 0 {
+		final.bytes = append(final.bytes, ", "...)
+		final.bytes = append(final.bytes, enc.bytes...)
+	}
+	final.bytes = append(final.bytes, "}\n"...)
+
+	expectedBytes := len(final.bytes)
+	n, err := sink.Write(final.bytes)
+	final.Free()
+	if err != nil {
+		return err
+	}
+	if n != expectedBytes {
+		return &ShortWriteError{Wrote: n, Expected: expectedBytes}
+	}
+	return nil
+}
+
+func (enc *yamlFlowEncoder) truncate() {
+	enc.bytes = enc.bytes[:0]
+}
+
+func (enc *yamlFlowEncoder) addKey(key string) {
+	last := len(enc.bytes) - 1
+	if last >= 0 && enc.bytes[last] != '{' {
+		enc.bytes = append(enc.bytes, ", "...)
+	}
+	enc.bytes = appendYAMLString(enc.bytes, key)
+	enc.bytes = append(enc.bytes, ": "...)
+}
+
+// appendYAMLString appends s as a plain scalar if YAML parsers would read it
+// back as the same string, and as a double-quoted scalar otherwise.
+func appendYAMLString(buf []byte, s string) []byte {
+	if isPlainYAML(s) {
+		return append(buf, s...)
+	}
+	buf = append(buf, '"')
+	for _, r := range s {
+		switch {
+		case r == '"':
+			buf = append(buf, `\"`...)
+		case r == '\\':
+			buf = append(buf, `\\`...)
+		case r == '\n':
+			buf = append(buf, `\n`...)
+		case r == '\r':
+			buf = append(buf, `\r`...)
+		case r == '\t':
+			buf = append(buf, `\t`...)
+		case unicode.IsPrint(r):
+			var encoded [utf8.UTFMax]byte
+			n := utf8.EncodeRune(encoded[:], r)
+			buf = append(buf, encoded[:n]...)
+		case r > 0xFFFF:
+			buf = append(buf, `\U`...)
+			buf = appendHexRune(buf, r, 8)
+		default:
+			buf = append(buf, `\u`...)
+			buf = appendHexRune(buf, r, 4)
+		}
+	}
+	return append(buf, '"')
+}
+
+func appendHexRune(buf []byte, r rune, digits int) []byte {
+	for shift := uint(digits-1) * 4; ; shift -= 4 {
+		buf = append(buf, hextable[r>>shift&0xF])
+		if shift == 0 {
+			return buf
+		}
+	}
+}
+
+// isPlainYAML reports whether s can be written as a plain scalar in a flow
+// mapping without changing its meaning.
+func isPlainYAML(s string) bool {
+	if s == "" || s[0] == ' ' || s[len(s)-1] == ' ' {
+		return false
+	}
+	if strings.IndexByte("-?:,[]{}#&*!|>'\"%@`", s[0]) >= 0 {
+		return false
+	}
+	for _, r := range s {
+		switch r {
+		case ':', ',', '[', ']', '{', '}', '#':
+			return false
+		}
+		if !unicode.IsPrint(r) {
+			return false
+		}
+	}
+	return !isYAMLKeyword(s) && !looksNumeric(s)
+}
+
+// isYAMLKeyword reports whether s would be resolved as a boolean or null by
+// YAML 1.1 or 1.2 parsers.
+func isYAMLKeyword(s string) bool {
+	switch strings.ToLower(s) {
+	case "~", "null", "true", "false", "yes", "no", "on", "off", "y", "n":
+		return true
+	}
+	return false
+}
+
+// looksNumeric reports whether s would be resolved as a number, including
+// YAML's special floats and underscore-separated or base-prefixed integers.
+func looksNumeric(s string) bool {
+	switch strings.ToLower(strings.TrimLeft(s, "+-")) {
+	case ".inf", ".nan":
+		return true
+	}
+	digits := strings.Replace(s, "_", "", -1)
+	if _, err := strconv.ParseFloat(digits, 64); err == nil {
+		return true
+	}
+	_, err := strconv.ParseInt(digits, 0, 64)
+	return err == nil
+}
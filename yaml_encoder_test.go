@@ -0,0 +1,125 @@
+// Copyright (c) 2016 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zap
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/uber-go/zap/spywrite"
+	"gopkg.in/yaml.v2"
+)
+
+func parseYAMLEntry(t testing.TB, enc Encoder, name, msg string) (string, map[string]interface{}) {
+	sink := &testBuffer{}
+	require.NoError(t, enc.WriteEntry(sink, name, msg, InfoLevel, epoch), "Unexpected failure writing entry.")
+	line := sink.Stripped()
+	parsed := make(map[string]interface{})
+	require.NoError(t, yaml.Unmarshal([]byte(line), &parsed), "Expected output to be valid YAML: %s", line)
+	return line, parsed
+}
+
+func TestYAMLFlowEncoderOutput(t *testing.T) {
+	enc := NewYAMLFlowEncoder()
+	defer enc.Free()
+	enc.AddString("user", "jane")
+	enc.AddInt("count", 3)
+	enc.AddBool("ok", true)
+	assert.NoError(t, enc.AddMarshaler("obj", loggable{true}), "Unexpected error adding a marshaler.")
+
+	line, _ := parseYAMLEntry(t, enc, "", "hello")
+	assert.Equal(t, `{level: info, ts: "1970-01-01T00:00:00Z", msg: hello, user: jane, count: 3, ok: true, obj: {loggable: "yes"}}`, line, "Unexpected YAML output.")
+}
+
+func TestYAMLFlowEncoderRoundTrip(t *testing.T) {
+	strs := map[string]string{
+		"colon":        "a: b",
+		"trailing":     "ends with:",
+		"comment":      "value #not a comment",
+		"flow":         "[1, 2] {a}",
+		"quotes":       `say "hi" and 'bye'`,
+		"backslash":    `C:\path`,
+		"indicator":    "- item",
+		"alias":        "*ref",
+		"space":        " padded ",
+		"empty":        "",
+		"newline":      "line1\nline2\ttabbed",
+		"control":      "bell\a",
+		"unicode":      "héllo ✓ 日本",
+		"bool word":    "yes",
+		"null word":    "null",
+		"tilde":        "~",
+		"int string":   "42",
+		"float string": "1e3",
+		"hex string":   "0x1F",
+		"inf string":   ".inf",
+	}
+
+	enc := NewYAMLFlowEncoder()
+	defer enc.Free()
+	for k, v := range strs {
+		enc.AddString(k, v)
+	}
+	enc.AddString("key: with, specials", "v")
+	enc.AddFloat64("float", 1.5)
+	enc.AddFloat64("nan", math.NaN())
+	enc.AddFloat64("inf", math.Inf(-1))
+	enc.AddUint64("big", math.MaxUint64)
+	enc.AddBytes("bytes", []byte{0xde, 0xad})
+
+	_, parsed := parseYAMLEntry(t, enc, "my: logger", "what: [why]?")
+	for k, v := range strs {
+		assert.Equal(t, v, parsed[k], "Unexpected round-tripped value for %q.", k)
+	}
+	assert.Equal(t, "info", parsed["level"], "Unexpected level.")
+	assert.Equal(t, "my: logger", parsed["logger"], "Unexpected logger name.")
+	assert.Equal(t, "what: [why]?", parsed["msg"], "Unexpected message.")
+	assert.Equal(t, "v", parsed["key: with, specials"], "Expected keys to be quoted when necessary.")
+	assert.Equal(t, 1.5, parsed["float"], "Unexpected float.")
+	assert.True(t, math.IsNaN(parsed["nan"].(float64)), "Expected NaN to round-trip.")
+	assert.Equal(t, math.Inf(-1), parsed["inf"], "Expected -Inf to round-trip.")
+	assert.Equal(t, uint64(math.MaxUint64), parsed["big"], "Unexpected uint64.")
+	assert.Equal(t, "0xDEAD", parsed["bytes"], "Unexpected bytes.")
+}
+
+func TestYAMLFlowEncoderClone(t *testing.T) {
+	parent := NewYAMLFlowEncoder()
+	defer parent.Free()
+	parent.AddString("foo", "bar")
+	clone := parent.Clone()
+	defer clone.Free()
+	clone.AddString("baz", "bing")
+
+	line, _ := parseYAMLEntry(t, clone, "", "")
+	assert.Equal(t, `{level: info, ts: "1970-01-01T00:00:00Z", msg: "", foo: bar, baz: bing}`, line, "Expected clones to inherit fields.")
+	line, _ = parseYAMLEntry(t, parent, "", "")
+	assert.Equal(t, `{level: info, ts: "1970-01-01T00:00:00Z", msg: "", foo: bar}`, line, "Adding to a clone shouldn't affect the parent.")
+}
+
+func TestYAMLFlowEncoderWriteFailure(t *testing.T) {
+	enc := NewYAMLFlowEncoder()
+	defer enc.Free()
+	assert.Equal(t, errNilSink, enc.WriteEntry(nil, "", "hello", InfoLevel, epoch), "Expected an error writing to a nil sink.")
+	assert.Error(t, enc.WriteEntry(spywrite.FailWriter{}, "", "hello", InfoLevel, epoch), "Expected an error when the sink fails.")
+	assert.Error(t, enc.WriteEntry(spywrite.ShortWriter{}, "", "hello", InfoLevel, epoch), "Expected an error on partial writes.")
+}